@@ -7,31 +7,49 @@ import (
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/statement"
+	"log"
 	"net/http"
-	"strconv"
 )
 
 type AccountApi struct {
 	accountService service.AccountService
 	auth           *AuthenticatedApi
+	idempotency    *IdempotentApi
 }
 
-func NewAccountApi(accountService service.AccountService, auth *AuthenticatedApi) *AccountApi {
-	return &AccountApi{accountService: accountService, auth: auth}
+func NewAccountApi(accountService service.AccountService, auth *AuthenticatedApi, idempotency *IdempotentApi) *AccountApi {
+	return &AccountApi{accountService: accountService, auth: auth, idempotency: idempotency}
 }
 
 func (api *AccountApi) Router() *mux.Router {
-	router := mux.NewRouter()
+	return api.Register(mux.NewRouter())
+}
+
+func (api *AccountApi) Register(router *mux.Router) *mux.Router {
 	router.Handle("/accounts", api.auth.Authenticated(api.createAccount)).Methods("POST")
+	router.Handle("/accounts", api.auth.Authenticated(api.listAccounts)).Methods("GET")
 	router.Handle("/accounts/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getAccount)).Methods("GET")
-	router.Handle("/top-up", api.auth.Authenticated(api.topUp)).Methods("POST")
-	router.Handle("/transfer", api.auth.Authenticated(api.transfer)).Methods("POST")
+	router.Handle("/accounts/{id:[1-9][0-9]*}/transactions", api.auth.Authenticated(api.getTransactions)).Methods("GET")
+	router.Handle("/accounts/{id:[1-9][0-9]*}/statement", api.auth.Authenticated(api.getStatement)).Methods("GET")
+	router.Handle("/accounts/{id:[1-9][0-9]*}/freeze", api.auth.Authenticated(api.freezeAccount)).Methods("POST")
+	router.Handle("/accounts/{id:[1-9][0-9]*}/close", api.auth.Authenticated(api.closeAccount)).Methods("POST")
+	router.Handle("/top-up", api.auth.Authenticated(api.idempotency.Idempotent(api.topUp))).Methods("POST")
+	router.Handle("/transfer", api.auth.Authenticated(api.idempotency.Idempotent(api.transfer))).Methods("POST")
+	router.Handle("/transfers/batch", api.auth.Authenticated(api.idempotency.Idempotent(api.transferLegs))).Methods("POST")
+	router.Handle("/transfers/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getTransfer)).Methods("GET")
+	router.Handle("/top-ups/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getTopUp)).Methods("GET")
+	router.Handle("/scheduled-transfers", api.auth.Authenticated(api.listScheduledTransfers)).Methods("GET")
+	router.Handle("/scheduled-transfers/{id:[1-9][0-9]*}/cancel", api.auth.Authenticated(api.cancelScheduledTransfer)).Methods("POST")
 	return router
 }
 
 func (api *AccountApi) createAccount(userId model.UserId) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if account, err := api.accountService.Create(userId); err == nil {
+		var request dto.CreateAccountRequest
+		if err := readOptionalJson(r, &request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if account, err := api.accountService.Create(&request, userId); err == nil {
 			writeResponse(w, dto.AccountFromModel(account), http.StatusCreated)
 		} else {
 			handleServiceError(w, err)
@@ -39,13 +57,20 @@ func (api *AccountApi) createAccount(userId model.UserId) http.Handler {
 	})
 }
 
+func (api *AccountApi) listAccounts(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accounts, err := api.accountService.List(userId); err == nil {
+			writeResponse(w, dto.AccountsFromModel(accounts), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
 func (api *AccountApi) getAccount(userId model.UserId) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		idStr, idFound := mux.Vars(r)["id"]
-		if !idFound {
-			writeResponse(w, &dto.ErrorResponse{Message: "The request is missing the account id"}, http.StatusBadRequest)
-		} else if id, err := strconv.Atoi(idStr); err != nil {
-			writeResponse(w, &dto.ErrorResponse{Message: "The account id must be a number"}, http.StatusBadRequest)
+		if id, ok := readPathId(w, r, "account"); !ok {
+			return
 		} else if account, err := api.accountService.Get(model.AccountId(id), userId); err == nil {
 			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
 		} else {
@@ -54,13 +79,76 @@ func (api *AccountApi) getAccount(userId model.UserId) http.Handler {
 	})
 }
 
+func (api *AccountApi) getTransactions(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "account"); !ok {
+			return
+		} else if request, err := dto.NewTransactionsRequest(model.AccountId(id), r.URL.Query()); err != nil {
+			handleServiceError(w, err)
+		} else if page, err := api.accountService.Transactions(request, userId); err == nil {
+			writeResponse(w, dto.TransactionPageFromModel(page), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+// getStatement streams the statement, so an error that comes after its first part can only be logged
+func (api *AccountApi) getStatement(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "account"); !ok {
+			return
+		} else if request, err := dto.NewStatementRequest(model.AccountId(id), r.URL.Query()); err != nil {
+			handleServiceError(w, err)
+		} else {
+			response := &streamedResponse{ResponseWriter: w, contentType: statement.ContentType(request.Format)}
+			if err := api.accountService.Statement(request, userId, statement.NewWriter(request.Format, response)); err == nil {
+				return
+			} else if !response.started {
+				handleServiceError(w, err)
+			} else {
+				log.Printf("Writing the statement of the account %d failed: %v", id, err)
+			}
+		}
+	})
+}
+
+func (api *AccountApi) freezeAccount(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "account"); !ok {
+			return
+		} else if account, err := api.accountService.Freeze(model.AccountId(id), userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AccountApi) closeAccount(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "account")
+		if !ok {
+			return
+		}
+		request := dto.CloseAccountRequest{Id: model.AccountId(id)}
+		if err := readOptionalJson(r, &request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if account, err := api.accountService.Close(&request, userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
 func (api *AccountApi) topUp(userId model.UserId) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var request dto.TopUpRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
-		} else if err := api.accountService.TopUp(&request, userId); err == nil {
-			writeResponse(w, "{}", http.StatusOK)
+		} else if entry, err := api.accountService.TopUp(&request, userId); err == nil {
+			writeResponse(w, dto.TopUpReceiptFromModel(entry), http.StatusOK)
 		} else {
 			handleServiceError(w, err)
 		}
@@ -72,30 +160,158 @@ func (api *AccountApi) transfer(userId model.UserId) http.Handler {
 		var request dto.TransferRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
-		} else if err := api.accountService.Transfer(&request, userId); err == nil {
-			writeResponse(w, "{}", http.StatusOK)
+		} else if request.ExecuteAt != nil {
+			api.scheduleTransfer(w, &request, userId)
+		} else if transfer, err := api.accountService.Transfer(&request, userId); err == nil {
+			writeResponse(w, dto.TransferReceiptFromModel(transfer), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AccountApi) transferLegs(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request dto.TransferLegsRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if transfers, err := api.accountService.TransferLegs(&request, userId); err == nil {
+			writeResponse(w, dto.TransferReceiptsFromModel(transfers), http.StatusOK)
 		} else {
 			handleServiceError(w, err)
 		}
 	})
 }
 
+func (api *AccountApi) scheduleTransfer(w http.ResponseWriter, request *dto.TransferRequest, userId model.UserId) {
+	if scheduled, err := api.accountService.Schedule(request, userId); err == nil {
+		writeResponse(w, dto.ScheduledTransferFromModel(scheduled), http.StatusCreated)
+	} else {
+		handleServiceError(w, err)
+	}
+}
+
+func (api *AccountApi) listScheduledTransfers(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if transfers, err := api.accountService.ScheduledTransfers(userId); err == nil {
+			writeResponse(w, dto.ScheduledTransfersFromModel(transfers), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AccountApi) cancelScheduledTransfer(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "scheduled transfer"); !ok {
+			return
+		} else if transfer, err := api.accountService.CancelScheduled(model.ScheduledTransferId(id), userId); err == nil {
+			writeResponse(w, dto.ScheduledTransferFromModel(transfer), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AccountApi) getTransfer(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "transfer"); !ok {
+			return
+		} else if transfer, err := api.accountService.GetTransfer(model.TransferId(id), userId); err == nil {
+			writeResponse(w, dto.TransferReceiptFromModel(transfer), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AccountApi) getTopUp(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "top-up"); !ok {
+			return
+		} else if entry, err := api.accountService.GetTopUp(model.LedgerEntryId(id), userId); err == nil {
+			writeResponse(w, dto.TopUpReceiptFromModel(entry), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+// handleServiceError answers a failed leg with the status of its error, and tells which leg failed and why
+// handleServiceError always tells which leg failed, even when its error is unhandled
 func handleServiceError(w http.ResponseWriter, err error) {
-	errResponse := &dto.ErrorResponse{Message: err.Error()}
-	switch err.(type) {
+	if legErr, ok := err.(*errors.BatchTransferError); ok {
+		if status, ok := errorStatus(legErr.Err); ok {
+			writeResponse(w, dto.TransferLegErrorResponseFromError(legErr), status)
+		} else {
+			writeResponse(w, dto.TransferLegErrorResponseFromError(legErr), http.StatusInternalServerError)
+		}
+	} else if status, ok := errorStatus(err); !ok {
+		writeResponse(w, &dto.ErrorResponse{Message: "Unhandled error"}, http.StatusInternalServerError)
+	} else if limitErr, ok := err.(*errors.LimitExceededError); ok {
+		writeResponse(w, dto.LimitExceededResponseFromError(limitErr), status)
+	} else {
+		writeResponse(w, &dto.ErrorResponse{Message: err.Error()}, status)
+	}
+}
+
+func errorStatus(err error) (int, bool) {
+	switch e := err.(type) {
+	case *errors.ActiveHoldsError:
+		return http.StatusConflict, true
+	case *errors.AdminAccessRequiredError:
+		return http.StatusForbidden, true
 	case *errors.AccountDoesNotExistError:
-		writeResponse(w, errResponse, http.StatusNotFound)
+		return http.StatusNotFound, true
+	case *errors.AccountClosedError:
+		return http.StatusConflict, true
+	case *errors.AccountFrozenError:
+		return http.StatusConflict, true
 	case *errors.BalanceTooLowError:
-		writeResponse(w, errResponse, http.StatusBadRequest)
+		return http.StatusBadRequest, true
+	case *errors.BatchDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.BatchTransferError:
+		return errorStatus(e.Err)
+	case *errors.CurrencyMismatchError:
+		return http.StatusBadRequest, true
 	case *errors.DuplicateAccountError:
-		writeResponse(w, errResponse, http.StatusConflict)
+		return http.StatusConflict, true
+	case *errors.FxRateUnavailableError:
+		return http.StatusBadRequest, true
 	case *errors.ForbiddenAccountAccessError:
-		writeResponse(w, errResponse, http.StatusForbidden)
+		return http.StatusForbidden, true
+	case *errors.IdempotencyKeyInProgressError:
+		return http.StatusConflict, true
+	case *errors.ScheduledTransferDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.ScheduledTransferNotPendingError:
+		return http.StatusConflict, true
+	case *errors.HoldDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.HoldNotActiveError:
+		return http.StatusConflict, true
+	case *errors.StandingOrderDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.StandingOrderEndedError:
+		return http.StatusConflict, true
+	case *errors.TransferAlreadyReversedError:
+		return http.StatusConflict, true
+	case *errors.TransferDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.TopUpDoesNotExistError:
+		return http.StatusNotFound, true
+	case *errors.IdempotencyKeyReuseError:
+		return http.StatusUnprocessableEntity, true
+	case *errors.LimitExceededError:
+		return http.StatusUnprocessableEntity, true
 	case *errors.InternalServerError:
-		writeResponse(w, errResponse, http.StatusInternalServerError)
+		return http.StatusInternalServerError, true
+	case *errors.NonZeroBalanceError:
+		return http.StatusConflict, true
 	case *errors.ValidationError:
-		writeResponse(w, errResponse, http.StatusBadRequest)
+		return http.StatusBadRequest, true
 	default:
-		writeResponse(w, &dto.ErrorResponse{Message: "Unhandled error"}, http.StatusInternalServerError)
+		return 0, false
 	}
 }
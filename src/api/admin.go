@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"net/http"
+)
+
+type AdminApi struct {
+	adminService service.AdminService
+	auth         *AuthenticatedApi
+}
+
+func NewAdminApi(adminService service.AdminService, auth *AuthenticatedApi) *AdminApi {
+	return &AdminApi{adminService: adminService, auth: auth}
+}
+
+func (api *AdminApi) Router() *mux.Router {
+	return api.Register(mux.NewRouter())
+}
+
+func (api *AdminApi) Register(router *mux.Router) *mux.Router {
+	router.Handle("/admin/trial-balance", api.auth.Authenticated(api.getTrialBalance)).Methods("GET")
+	router.Handle("/transfers/{id:[1-9][0-9]*}/reverse", api.auth.Authenticated(api.reverseTransfer)).Methods("POST")
+	router.Handle("/admin/accounts/overdrawn", api.auth.Authenticated(api.listOverdrawnAccounts)).Methods("GET")
+	router.Handle("/admin/accounts/{id:[1-9][0-9]*}/overdraft-limit", api.auth.Authenticated(api.setOverdraftLimit)).Methods("PUT")
+	router.Handle("/admin/accounts/{id:[1-9][0-9]*}/limits", api.auth.Authenticated(api.setTransferLimits)).Methods("PUT")
+	router.Handle("/admin/accounts/{id:[1-9][0-9]*}/interest-rate", api.auth.Authenticated(api.setInterestRate)).Methods("PUT")
+	router.Handle("/admin/accounts/{id:[1-9][0-9]*}/unfreeze", api.auth.Authenticated(api.unfreezeAccount)).Methods("POST")
+	return router
+}
+
+func (api *AdminApi) getTrialBalance(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if trialBalance, err := api.adminService.TrialBalance(userId); err == nil {
+			writeResponse(w, dto.TrialBalanceFromModel(trialBalance), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AdminApi) reverseTransfer(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "transfer")
+		if !ok {
+			return
+		}
+		request := dto.ReverseTransferRequest{Id: model.TransferId(id)}
+		if err := readOptionalJson(r, &request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if reversal, err := api.adminService.Reverse(&request, userId); err == nil {
+			writeResponse(w, dto.TransferReceiptFromModel(reversal), http.StatusCreated)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AdminApi) listOverdrawnAccounts(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accounts, err := api.adminService.OverdrawnAccounts(userId); err == nil {
+			writeResponse(w, dto.AccountsFromModel(accounts), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AdminApi) setOverdraftLimit(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "account")
+		if !ok {
+			return
+		}
+		request := dto.OverdraftLimitRequest{AccountId: model.AccountId(id)}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if account, err := api.adminService.SetOverdraftLimit(&request, userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+// setTransferLimits replaces all the overrides of the account, so a limit missing in the request goes back to the default
+func (api *AdminApi) setTransferLimits(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "account")
+		if !ok {
+			return
+		}
+		request := dto.TransferLimitsRequest{AccountId: model.AccountId(id)}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if account, err := api.adminService.SetTransferLimits(&request, userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AdminApi) setInterestRate(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "account")
+		if !ok {
+			return
+		}
+		request := dto.InterestRateRequest{AccountId: model.AccountId(id)}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if account, err := api.adminService.SetInterestRate(&request, userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *AdminApi) unfreezeAccount(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "account"); !ok {
+			return
+		} else if account, err := api.adminService.Unfreeze(model.AccountId(id), userId); err == nil {
+			writeResponse(w, dto.AccountFromModel(account), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"net/http"
+)
+
+type BatchApi struct {
+	batchService service.BatchService
+	auth         *AuthenticatedApi
+	idempotency  *IdempotentApi
+}
+
+func NewBatchApi(batchService service.BatchService, auth *AuthenticatedApi, idempotency *IdempotentApi) *BatchApi {
+	return &BatchApi{batchService: batchService, auth: auth, idempotency: idempotency}
+}
+
+func (api *BatchApi) Router() *mux.Router {
+	return api.Register(mux.NewRouter())
+}
+
+func (api *BatchApi) Register(router *mux.Router) *mux.Router {
+	router.Handle("/batches", api.auth.Authenticated(api.idempotency.Idempotent(api.createBatch))).Methods("POST")
+	router.Handle("/batches/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getBatch)).Methods("GET")
+	return router
+}
+
+// createBatch reads the file as the body, as a pain.001 XML or a CSV file depending on the content type
+func (api *BatchApi) createBatch(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if request, err := dto.NewBatchRequest(r.URL.Query().Get("mode"), r.Header.Get("Content-Type"), r.Body); err != nil {
+			handleServiceError(w, err)
+		} else if batch, err := api.batchService.Create(request, userId); err == nil {
+			writeResponse(w, dto.BatchFromModel(batch), http.StatusCreated)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *BatchApi) getBatch(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "batch"); !ok {
+			return
+		} else if batch, err := api.batchService.Get(model.BatchId(id), userId); err == nil {
+			writeResponse(w, dto.BatchFromModel(batch), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"net/http"
+)
+
+type HoldApi struct {
+	holdService service.HoldService
+	auth        *AuthenticatedApi
+	idempotency *IdempotentApi
+}
+
+func NewHoldApi(holdService service.HoldService, auth *AuthenticatedApi, idempotency *IdempotentApi) *HoldApi {
+	return &HoldApi{holdService: holdService, auth: auth, idempotency: idempotency}
+}
+
+func (api *HoldApi) Router() *mux.Router {
+	return api.Register(mux.NewRouter())
+}
+
+// Register needs no idempotency for capturing and releasing, because a hold can only be settled once
+func (api *HoldApi) Register(router *mux.Router) *mux.Router {
+	router.Handle("/holds", api.auth.Authenticated(api.idempotency.Idempotent(api.placeHold))).Methods("POST")
+	router.Handle("/holds/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getHold)).Methods("GET")
+	router.Handle("/holds/{id:[1-9][0-9]*}/capture", api.auth.Authenticated(api.captureHold)).Methods("POST")
+	router.Handle("/holds/{id:[1-9][0-9]*}/release", api.auth.Authenticated(api.releaseHold)).Methods("POST")
+	return router
+}
+
+func (api *HoldApi) placeHold(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request dto.HoldRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if hold, err := api.holdService.Place(&request, userId); err == nil {
+			writeResponse(w, dto.HoldFromModel(hold), http.StatusCreated)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *HoldApi) getHold(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "hold"); !ok {
+			return
+		} else if hold, err := api.holdService.Get(model.HoldId(id), userId); err == nil {
+			writeResponse(w, dto.HoldFromModel(hold), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *HoldApi) captureHold(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "hold")
+		if !ok {
+			return
+		}
+		request := dto.CaptureHoldRequest{Id: model.HoldId(id)}
+		if err := readOptionalJson(r, &request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if transfer, err := api.holdService.Capture(&request, userId); err == nil {
+			writeResponse(w, dto.TransferReceiptFromModel(transfer), http.StatusCreated)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *HoldApi) releaseHold(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "hold"); !ok {
+			return
+		} else if hold, err := api.holdService.Release(model.HoldId(id), userId); err == nil {
+			writeResponse(w, dto.HoldFromModel(hold), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"io"
+	"log"
+	"net/http"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type IdempotentApi struct {
+	idempotencyService service.IdempotencyService
+}
+
+func NewIdempotentApi(idempotencyService service.IdempotencyService) *IdempotentApi {
+	return &IdempotentApi{idempotencyService: idempotencyService}
+}
+
+// Idempotent stores the response of the first request with the Idempotency-Key header and replays it for retries.
+// Server errors are not stored, so that the request can be retried with the same key.
+func (api *IdempotentApi) Idempotent(next AuthenticatedHandler) AuthenticatedHandler {
+	return func(userId model.UserId) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next(userId).ServeHTTP(w, r)
+			} else if body, err := io.ReadAll(r.Body); err != nil {
+				writeResponse(w, &dto.ErrorResponse{Message: "The request body cannot be read"}, http.StatusBadRequest)
+			} else if record, err := api.idempotencyService.Begin(userId, key, hashRequest(r, body)); err != nil {
+				handleServiceError(w, err)
+			} else if record != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(*record.StatusCode)
+				w.Write(record.Response)
+			} else {
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+				next(userId).ServeHTTP(recorder, r)
+				if recorder.statusCode >= http.StatusInternalServerError {
+					err = api.idempotencyService.Release(userId, key)
+				} else {
+					err = api.idempotencyService.Complete(userId, key, recorder.statusCode, recorder.body.Bytes())
+				}
+				if err != nil {
+					log.Printf("Cannot store the response for the idempotency key '%s': %v", key, err)
+				}
+			}
+		})
+	}
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"net/http"
+)
+
+type StandingOrderApi struct {
+	orderService service.StandingOrderService
+	auth         *AuthenticatedApi
+	idempotency  *IdempotentApi
+}
+
+func NewStandingOrderApi(orderService service.StandingOrderService, auth *AuthenticatedApi, idempotency *IdempotentApi) *StandingOrderApi {
+	return &StandingOrderApi{orderService: orderService, auth: auth, idempotency: idempotency}
+}
+
+func (api *StandingOrderApi) Router() *mux.Router {
+	return api.Register(mux.NewRouter())
+}
+
+func (api *StandingOrderApi) Register(router *mux.Router) *mux.Router {
+	router.Handle("/standing-orders", api.auth.Authenticated(api.idempotency.Idempotent(api.createStandingOrder))).Methods("POST")
+	router.Handle("/standing-orders", api.auth.Authenticated(api.listStandingOrders)).Methods("GET")
+	router.Handle("/standing-orders/{id:[1-9][0-9]*}", api.auth.Authenticated(api.getStandingOrder)).Methods("GET")
+	router.Handle("/standing-orders/{id:[1-9][0-9]*}", api.auth.Authenticated(api.updateStandingOrder)).Methods("PUT")
+	router.Handle("/standing-orders/{id:[1-9][0-9]*}", api.auth.Authenticated(api.cancelStandingOrder)).Methods("DELETE")
+	return router
+}
+
+func (api *StandingOrderApi) createStandingOrder(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request dto.StandingOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if order, err := api.orderService.Create(&request, userId); err == nil {
+			writeResponse(w, dto.StandingOrderFromModel(order), http.StatusCreated)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *StandingOrderApi) listStandingOrders(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if orders, err := api.orderService.List(userId); err == nil {
+			writeResponse(w, dto.StandingOrdersFromModel(orders), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *StandingOrderApi) getStandingOrder(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "standing order"); !ok {
+			return
+		} else if order, err := api.orderService.Get(model.StandingOrderId(id), userId); err == nil {
+			writeResponse(w, dto.StandingOrderFromModel(order), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *StandingOrderApi) updateStandingOrder(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readPathId(w, r, "standing order")
+		if !ok {
+			return
+		}
+		request := dto.StandingOrderRequest{Id: model.StandingOrderId(id)}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeResponse(w, &dto.ErrorResponse{Message: "The request is not a valid json"}, http.StatusBadRequest)
+		} else if order, err := api.orderService.Update(&request, userId); err == nil {
+			writeResponse(w, dto.StandingOrderFromModel(order), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
+
+func (api *StandingOrderApi) cancelStandingOrder(userId model.UserId) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := readPathId(w, r, "standing order"); !ok {
+			return
+		} else if order, err := api.orderService.Cancel(model.StandingOrderId(id), userId); err == nil {
+			writeResponse(w, dto.StandingOrderFromModel(order), http.StatusOK)
+		} else {
+			handleServiceError(w, err)
+		}
+	})
+}
@@ -2,7 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"github.com/gorilla/mux"
+	"golang_bank_demo/src/dto"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 func writeResponse(w http.ResponseWriter, response interface{}, code int) {
@@ -10,3 +14,44 @@ func writeResponse(w http.ResponseWriter, response interface{}, code int) {
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(response)
 }
+
+// streamedResponse sends the headers of a successful response along with the first part of the body,
+// so that an error that comes before it can still get an error response
+type streamedResponse struct {
+	http.ResponseWriter
+	contentType string
+	started     bool
+}
+
+func (response *streamedResponse) Write(data []byte) (int, error) {
+	if !response.started {
+		response.started = true
+		response.Header().Set("Content-Type", response.contentType)
+		response.WriteHeader(http.StatusOK)
+	}
+	return response.ResponseWriter.Write(data)
+}
+
+// An empty body leaves the request with its default values
+func readOptionalJson(r *http.Request, request interface{}) error {
+	if r.Body == nil {
+		return nil
+	} else if err := json.NewDecoder(r.Body).Decode(request); err != nil && err != io.EOF {
+		return err
+	} else {
+		return nil
+	}
+}
+
+func readPathId(w http.ResponseWriter, r *http.Request, entity string) (int64, bool) {
+	idStr, idFound := mux.Vars(r)["id"]
+	if !idFound {
+		writeResponse(w, &dto.ErrorResponse{Message: "The request is missing the " + entity + " id"}, http.StatusBadRequest)
+		return 0, false
+	} else if id, err := strconv.ParseInt(idStr, 10, 64); err != nil {
+		writeResponse(w, &dto.ErrorResponse{Message: "The " + entity + " id must be a number"}, http.StatusBadRequest)
+		return 0, false
+	} else {
+		return id, true
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/statement"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// runCommand runs a job once instead of starting the server, for cron jobs and for making up missed days
+func runCommand(appConfig *config.AppConfig, command string, args []string) error {
+	switch command {
+	case "accrue-interest":
+		return accrueInterest(appConfig, args)
+	case "export-statement":
+		return exportStatement(appConfig, args)
+	default:
+		return fmt.Errorf("Unknown command '%s'", command)
+	}
+}
+
+// accrueInterest accrues the days from the first one to the last one, which are both yesterday by default
+func accrueInterest(appConfig *config.AppConfig, args []string) error {
+	clock := service.NewSystemClock()
+	yesterday := model.Today(clock.Now()).AddDate(0, 0, -1).Format(dateLayout)
+	flags := flag.NewFlagSet("accrue-interest", flag.ContinueOnError)
+	from := flags.String("from", yesterday, "the first day to accrue, as YYYY-MM-DD")
+	to := flags.String("to", "", "the last day to accrue, as YYYY-MM-DD, the first day by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	} else if *to == "" {
+		*to = *from
+	}
+
+	first, err := time.Parse(dateLayout, *from)
+	if err != nil {
+		return fmt.Errorf("Invalid first day: %w", err)
+	}
+	last, err := time.Parse(dateLayout, *to)
+	if err != nil {
+		return fmt.Errorf("Invalid last day: %w", err)
+	}
+	storages, err := createStorages(appConfig)
+	if err != nil {
+		return err
+	}
+	accruer, err := service.NewInterestAccruer(storages.interest, clock, &appConfig.Interest)
+	if err != nil {
+		return err
+	}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if accrued, err := accruer.Accrue(day); err != nil {
+			return fmt.Errorf("Accruing the interest of %s failed: %w", day.Format(dateLayout), err)
+		} else {
+			log.Printf("Accrued the interest of %s on %d accounts", day.Format(dateLayout), accrued)
+		}
+	}
+	return nil
+}
+
+// exportStatement writes the statement of any account, without the access check of the api, for the bank's own exports.
+// The period and the format are read like the query of the statement endpoint, and the format is camt053 by default.
+func exportStatement(appConfig *config.AppConfig, args []string) error {
+	flags := flag.NewFlagSet("export-statement", flag.ContinueOnError)
+	accountId := flags.Int64("account", 0, "the id of the account")
+	from := flags.String("from", "", "the start of the period, as YYYY-MM-DD or RFC 3339")
+	to := flags.String("to", "", "the end of the period, as YYYY-MM-DD for the whole day or RFC 3339")
+	format := flags.String("format", string(model.Camt053Statement), "csv, json, txt or camt053")
+	output := flags.String("output", "", "the file to write, the standard output by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	request, err := dto.NewStatementRequest(model.AccountId(*accountId), url.Values{"from": {*from}, "to": {*to}, "format": {*format}})
+	if err != nil {
+		return err
+	} else if err := request.Validate(); err != nil {
+		return err
+	}
+	storages, err := createStorages(appConfig)
+	if err != nil {
+		return err
+	}
+	account, err := storages.account.Get(request.AccountId)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return storages.ledger.Statement(request.Statement(account, time.Now()), statement.NewWriter(request.Format, out))
+}
@@ -1,10 +1,109 @@
 package config
 
+import (
+	"time"
+)
+
 type Postgres struct {
 	Uri string `yaml:"uri" env:"POSTGRES_URI"`
 }
 
+type Storage struct {
+	Type string `yaml:"type" env:"STORAGE_TYPE" env-default:"postgres"`
+}
+
+type Idempotency struct {
+	Expiration time.Duration `yaml:"expiration" env:"IDEMPOTENCY_EXPIRATION" env-default:"24h"`
+	Interval   time.Duration `yaml:"interval" env:"IDEMPOTENCY_INTERVAL" env-default:"1h"`
+}
+
+type Jwt struct {
+	Secret        string `yaml:"secret" env:"JWT_SECRET"`
+	PublicKeyFile string `yaml:"public_key_file" env:"JWT_PUBLIC_KEY_FILE"`
+	JwksFile      string `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	Issuer        string `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience      string `yaml:"audience" env:"JWT_AUDIENCE"`
+	UserClaim     string `yaml:"user_claim" env:"JWT_USER_CLAIM" env-default:"sub"`
+}
+
+type Authentication struct {
+	Type string `yaml:"type" env:"AUTHENTICATION_TYPE" env-default:"stub"`
+	Jwt  Jwt    `yaml:"jwt"`
+}
+
+type Admin struct {
+	Users []int64 `yaml:"users" env:"ADMIN_USERS"`
+}
+
+type Fx struct {
+	RatesFile string  `yaml:"rates_file" env:"FX_RATES_FILE" env-default:"fx_rates.yaml"`
+	Spread    float64 `yaml:"spread" env:"FX_SPREAD"`
+}
+
+type Fees struct {
+	ScheduleFile string `yaml:"schedule_file" env:"FEES_SCHEDULE_FILE" env-default:"fees.yaml"`
+}
+
+type Scheduler struct {
+	Interval      time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL" env-default:"10s"`
+	MaxAttempts   int           `yaml:"max_attempts" env:"SCHEDULER_MAX_ATTEMPTS" env-default:"5"`
+	RetryInterval time.Duration `yaml:"retry_interval" env:"SCHEDULER_RETRY_INTERVAL" env-default:"1m"`
+}
+
+type StandingOrders struct {
+	Interval      time.Duration `yaml:"interval" env:"STANDING_ORDERS_INTERVAL" env-default:"1m"`
+	MaxRetries    int           `yaml:"max_retries" env:"STANDING_ORDERS_MAX_RETRIES" env-default:"3"`
+	RetryInterval time.Duration `yaml:"retry_interval" env:"STANDING_ORDERS_RETRY_INTERVAL" env-default:"1h"`
+}
+
+type Holds struct {
+	Interval   time.Duration `yaml:"interval" env:"HOLDS_INTERVAL" env-default:"1m"`
+	Expiration time.Duration `yaml:"expiration" env:"HOLDS_EXPIRATION" env-default:"168h"`
+}
+
+// Interest accrues every day by the day count convention, which is ACT/365, ACT/360 or 30/360.
+// The interval is how often the server checks whether the last day has been accrued.
+type Interest struct {
+	Interval time.Duration `yaml:"interval" env:"INTEREST_INTERVAL" env-default:"1h"`
+	DayCount string        `yaml:"day_count" env:"INTEREST_DAY_COUNT" env-default:"ACT/365"`
+}
+
+// Limits are the limits of every currency by its code. A currency that is not listed has no default limits.
+type Limits map[string]CurrencyLimits
+
+// CurrencyLimits are decimal strings in the currency, and a missing or zero limit does not apply.
+// The account limits are defaults, which the admins can override per account.
+type CurrencyLimits struct {
+	PerTransaction     string `yaml:"per_transaction"`
+	Daily              string `yaml:"daily"`
+	Monthly            string `yaml:"monthly"`
+	UserPerTransaction string `yaml:"user_per_transaction"`
+	UserDaily          string `yaml:"user_daily"`
+	UserMonthly        string `yaml:"user_monthly"`
+}
+
+// Events are published to stdout or appended to a file as JSON lines. The interval is how often the relay
+// looks for new events in the outbox.
+type Events struct {
+	Publisher string        `yaml:"publisher" env:"EVENTS_PUBLISHER" env-default:"stdout"`
+	File      string        `yaml:"file" env:"EVENTS_FILE" env-default:"events.jsonl"`
+	Interval  time.Duration `yaml:"interval" env:"EVENTS_INTERVAL" env-default:"1s"`
+	BatchSize int           `yaml:"batch_size" env:"EVENTS_BATCH_SIZE" env-default:"100"`
+}
+
 type AppConfig struct {
-	Port     int      `yaml:"port" env:"PORT"`
-	Postgres Postgres `yaml:"postgres"`
+	Port           int            `yaml:"port" env:"PORT"`
+	Storage        Storage        `yaml:"storage"`
+	Postgres       Postgres       `yaml:"postgres"`
+	Idempotency    Idempotency    `yaml:"idempotency"`
+	Authentication Authentication `yaml:"authentication"`
+	Fx             Fx             `yaml:"fx"`
+	Fees           Fees           `yaml:"fees"`
+	Admin          Admin          `yaml:"admin"`
+	Scheduler      Scheduler      `yaml:"scheduler"`
+	StandingOrders StandingOrders `yaml:"standing_orders"`
+	Holds          Holds          `yaml:"holds"`
+	Limits         Limits         `yaml:"limits"`
+	Interest       Interest       `yaml:"interest"`
+	Events         Events         `yaml:"events"`
 }
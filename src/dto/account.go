@@ -6,10 +6,40 @@ import (
 )
 
 type Account struct {
-	Id      model.AccountId `json:"id"`
-	Balance decimal.Decimal `json:"balance"`
+	Id               model.AccountId     `json:"id"`
+	Name             string              `json:"name,omitempty"`
+	Type             model.AccountType   `json:"type"`
+	Currency         model.Currency      `json:"currency"`
+	Status           model.AccountStatus `json:"status"`
+	Balance          decimal.Decimal     `json:"balance"`
+	AvailableBalance decimal.Decimal     `json:"available_balance"`
+	OverdraftLimit   decimal.Decimal     `json:"overdraft_limit"`
+	Limits           *TransferLimits     `json:"limits,omitempty"`
+	InterestRate     *decimal.Decimal    `json:"interest_rate,omitempty"`
 }
 
 func AccountFromModel(account *model.Account) *Account {
-	return &Account{Id: account.Id, Balance: account.Balance}
+	result := &Account{
+		Id:               account.Id,
+		Name:             account.Name,
+		Type:             account.Type,
+		Currency:         account.Currency,
+		Status:           account.Status,
+		Balance:          account.Currency.Round(account.Balance),
+		AvailableBalance: account.Currency.Round(account.Available()),
+		OverdraftLimit:   account.Currency.Round(account.OverdraftLimit),
+		Limits:           TransferLimitsFromModel(&account.TransferLimits),
+	}
+	if account.InterestRate.IsPositive() {
+		result.InterestRate = &account.InterestRate
+	}
+	return result
+}
+
+func AccountsFromModel(accounts []model.Account) []*Account {
+	result := make([]*Account, len(accounts))
+	for i := range accounts {
+		result[i] = AccountFromModel(&accounts[i])
+	}
+	return result
 }
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+func validateAmountPrecision(amount decimal.Decimal, currency model.Currency) error {
+	if !currency.HasValidPrecision(amount) {
+		return errors.NewValidationError("amount", fmt.Sprintf("The amount can have at most %d decimal places in %s", currency.MinorUnits(), currency))
+	} else {
+		return nil
+	}
+}
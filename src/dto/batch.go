@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type Batch struct {
+	Id        model.BatchId     `json:"id"`
+	Mode      model.BatchMode   `json:"mode"`
+	Status    model.BatchStatus `json:"status"`
+	Lines     []*BatchLine      `json:"lines"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type BatchLine struct {
+	Line         int                   `json:"line"`
+	Reference    string                `json:"reference,omitempty"`
+	From         model.AccountId       `json:"from"`
+	To           model.AccountId       `json:"to"`
+	Amount       decimal.Decimal       `json:"amount"`
+	Status       model.BatchLineStatus `json:"status"`
+	TransferId   *model.TransferId     `json:"transfer_id,omitempty"`
+	ErrorType    *string               `json:"error_type,omitempty"`
+	ErrorMessage *string               `json:"error_message,omitempty"`
+}
+
+func BatchFromModel(batch *model.Batch) *Batch {
+	result := &Batch{
+		Id:        batch.Id,
+		Mode:      batch.Mode,
+		Status:    batch.Status,
+		Lines:     make([]*BatchLine, len(batch.Lines)),
+		CreatedAt: batch.CreatedAt,
+	}
+	for i, line := range batch.Lines {
+		result.Lines[i] = &BatchLine{
+			Line:         line.Line,
+			Reference:    line.Reference,
+			From:         line.From,
+			To:           line.To,
+			Amount:       line.Amount,
+			Status:       line.Status,
+			TransferId:   line.TransferId,
+			ErrorType:    line.ErrorType,
+			ErrorMessage: line.ErrorMessage,
+		}
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var batchCsvColumns = []string{"from", "to", "amount"}
+
+// readBatchCsv reads the columns by the names of the header, in any order. The reference column is optional.
+func readBatchCsv(body io.Reader) ([]BatchLineRequest, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return make([]BatchLineRequest, 0), nil
+	} else if err != nil {
+		return nil, errors.NewValidationError("file", "The file is not a valid CSV")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range batchCsvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.NewValidationError("file", fmt.Sprintf("The header has to contain the column %s", name))
+		}
+	}
+	lines := make([]BatchLineRequest, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return lines, nil
+		} else if err != nil {
+			return nil, errors.NewValidationError("file", "The file is not a valid CSV")
+		}
+		lines = append(lines, readBatchCsvLine(len(lines)+1, record, columns))
+	}
+}
+
+func readBatchCsvLine(number int, record []string, columns map[string]int) BatchLineRequest {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		} else {
+			return ""
+		}
+	}
+	line := BatchLineRequest{Line: number, Reference: value("reference")}
+	if from, err := strconv.ParseInt(value("from"), 10, 64); err != nil {
+		line.Err = errors.NewValidationError("from", "The id has to be a number")
+	} else if to, err := strconv.ParseInt(value("to"), 10, 64); err != nil {
+		line.Transfer.From = model.AccountId(from)
+		line.Err = errors.NewValidationError("to", "The id has to be a number")
+	} else if amount, err := decimal.NewFromString(value("amount")); err != nil {
+		line.Transfer.From, line.Transfer.To = model.AccountId(from), model.AccountId(to)
+		line.Err = errors.NewValidationError("amount", "The amount has to be a number")
+	} else {
+		line.Transfer = TransferRequest{From: model.AccountId(from), To: model.AccountId(to), Amount: amount}
+	}
+	return line
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"fmt"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"io"
+	"mime"
+)
+
+const (
+	maxBatchLines        = 1000
+	maxBatchReferenceLen = 35
+)
+
+type BatchRequest struct {
+	Mode  model.BatchMode
+	Lines []BatchLineRequest
+}
+
+// A line that could not be read keeps the error of reading it, so that it fails on its own and not the whole file.
+// The currency is the one given for the amount, which only a pain.001 file has.
+type BatchLineRequest struct {
+	Line      int
+	Reference string
+	Currency  model.Currency
+	Transfer  TransferRequest
+	Err       error
+}
+
+// NewBatchRequest reads a pain.001 file or a CSV file, as told by the content type. The mode is all or nothing by default.
+func NewBatchRequest(mode string, contentType string, body io.Reader) (*BatchRequest, error) {
+	request := &BatchRequest{Mode: model.AllOrNothingBatch}
+	if mode != "" {
+		request.Mode = model.BatchMode(mode)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, errors.NewValidationError("content_type", "The file has to be a pain.001 XML or a CSV file")
+	} else if mediaType == "application/xml" || mediaType == "text/xml" {
+		request.Lines, err = readPain001(body)
+	} else if mediaType == "text/csv" {
+		request.Lines, err = readBatchCsv(body)
+	} else {
+		return nil, errors.NewValidationError("content_type", "The file has to be a pain.001 XML or a CSV file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (request *BatchRequest) Validate() error {
+	if !request.Mode.IsValid() {
+		return errors.NewValidationError("mode", "The mode has to be all_or_nothing or best_effort")
+	} else if len(request.Lines) == 0 {
+		return errors.NewValidationError("file", "The file has to contain at least one transfer")
+	} else if len(request.Lines) > maxBatchLines {
+		return errors.NewValidationError("file", fmt.Sprintf("The file can contain at most %d transfers", maxBatchLines))
+	} else {
+		return nil
+	}
+}
+
+// Validate checks the line with the rules of a single transfer
+func (line *BatchLineRequest) Validate() error {
+	if line.Err != nil {
+		return line.Err
+	} else if len(line.Reference) > maxBatchReferenceLen {
+		return errors.NewValidationError("reference", fmt.Sprintf("The reference can have at most %d characters", maxBatchReferenceLen))
+	} else {
+		return line.Transfer.Validate()
+	}
+}
+
+func (request *BatchRequest) Batch(owner model.UserId) *model.Batch {
+	batch := &model.Batch{Owner: owner, Mode: request.Mode, Status: model.ProcessingBatch, Lines: make([]model.BatchLine, len(request.Lines))}
+	for i, line := range request.Lines {
+		batch.Lines[i] = model.BatchLine{
+			Line:      line.Line,
+			Reference: line.Reference,
+			From:      line.Transfer.From,
+			To:        line.Transfer.To,
+			Amount:    line.Transfer.Amount,
+			Status:    model.PendingBatchLine,
+		}
+	}
+	return batch
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+type CloseAccountRequest struct {
+	Id      model.AccountId  `json:"-"`
+	SweepTo *model.AccountId `json:"sweep_to"`
+}
+
+func (request *CloseAccountRequest) Validate() error {
+	if request.Id <= 0 {
+		return errors.NewValidationError("id", "The id has to be positive")
+	} else if request.SweepTo != nil && *request.SweepTo <= 0 {
+		return errors.NewValidationError("sweep_to", "The id has to be positive")
+	} else if request.SweepTo != nil && *request.SweepTo == request.Id {
+		return errors.NewValidationError("sweep_to", "The remaining money cannot be swept to the closed account")
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"unicode/utf8"
+)
+
+const maxAccountNameLength = 100
+
+type CreateAccountRequest struct {
+	Name     string            `json:"name"`
+	Type     model.AccountType `json:"type"`
+	Currency model.Currency    `json:"currency"`
+}
+
+func (request *CreateAccountRequest) Validate() error {
+	if utf8.RuneCountInString(request.Name) > maxAccountNameLength {
+		return errors.NewValidationError("name", "The name has to be at most 100 characters")
+	} else if request.Type != "" && !request.Type.IsValid() {
+		return errors.NewValidationError("type", "The type has to be one of 'checking', 'savings' or 'sub_account'")
+	} else if request.Currency != "" && !request.Currency.IsValid() {
+		return errors.NewValidationError("currency", "The currency has to be a supported ISO 4217 code")
+	} else {
+		return nil
+	}
+}
+
+func (request *CreateAccountRequest) Account(owner model.UserId) *model.Account {
+	account := &model.Account{Owner: owner, Name: request.Name, Type: request.Type, Currency: request.Currency}
+	if account.Type == "" {
+		account.Type = model.CheckingAccount
+	}
+	if account.Currency == "" {
+		account.Currency = model.DefaultCurrency
+	}
+	return account
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Date is a day in the format YYYY-MM-DD, which stands for the start of that day in UTC
+type Date struct {
+	time.Time
+}
+
+func NewDate(day time.Time) *Date {
+	return &Date{day}
+}
+
+func (date *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	} else if parsed, err := time.Parse(dateLayout, value); err != nil {
+		return err
+	} else {
+		date.Time = parsed
+		return nil
+	}
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(date.UTC().Format(dateLayout))
+}
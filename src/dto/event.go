@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"encoding/json"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type Event struct {
+	Id         model.EventId   `json:"id"`
+	Type       model.EventType `json:"type"`
+	Version    int             `json:"version"`
+	AccountId  model.AccountId `json:"account_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func EventFromModel(event *model.Event) *Event {
+	return &Event{
+		Id:         event.Id,
+		Type:       event.Type,
+		Version:    event.Version,
+		AccountId:  event.AccountId,
+		OccurredAt: event.CreatedAt,
+		Data:       event.Data,
+	}
+}
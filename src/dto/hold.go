@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type Hold struct {
+	Id         model.HoldId      `json:"id"`
+	Status     model.HoldStatus  `json:"status"`
+	From       model.AccountId   `json:"from"`
+	To         model.AccountId   `json:"to"`
+	Amount     decimal.Decimal   `json:"amount"`
+	Fee        decimal.Decimal   `json:"fee"`
+	Convert    bool              `json:"convert"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	TransferId *model.TransferId `json:"transfer_id,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func HoldFromModel(hold *model.Hold) *Hold {
+	return &Hold{
+		Id:         hold.Id,
+		Status:     hold.Status,
+		From:       hold.From,
+		To:         hold.To,
+		Amount:     hold.Amount,
+		Fee:        hold.Fee,
+		Convert:    hold.Convert,
+		ExpiresAt:  hold.ExpiresAt,
+		TransferId: hold.TransferId,
+		CreatedAt:  hold.CreatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+// A hold without an expiry time expires after the configured expiration
+type HoldRequest struct {
+	From      model.AccountId `json:"from"`
+	To        model.AccountId `json:"to"`
+	Amount    decimal.Decimal `json:"amount"`
+	Convert   bool            `json:"convert"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+func (request *HoldRequest) Validate(now time.Time) error {
+	if err := request.TransferRequest().Validate(); err != nil {
+		return err
+	} else if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return errors.NewValidationError("expires_at", "The expiry time has to be in the future")
+	} else {
+		return nil
+	}
+}
+
+func (request *HoldRequest) TransferRequest() *TransferRequest {
+	return &TransferRequest{From: request.From, To: request.To, Amount: request.Amount, Convert: request.Convert}
+}
+
+func (request *HoldRequest) Hold(defaultExpiresAt time.Time, fee decimal.Decimal) *model.Hold {
+	hold := &model.Hold{
+		From:      request.From,
+		To:        request.To,
+		Amount:    request.Amount,
+		Fee:       fee,
+		Convert:   request.Convert,
+		Status:    model.ActiveHold,
+		ExpiresAt: defaultExpiresAt,
+	}
+	if request.ExpiresAt != nil {
+		hold.ExpiresAt = *request.ExpiresAt
+	}
+	return hold
+}
+
+// A capture without an amount transfers the whole held amount, and the rest of a partial capture is released
+type CaptureHoldRequest struct {
+	Id     model.HoldId     `json:"-"`
+	Amount *decimal.Decimal `json:"amount,omitempty"`
+}
+
+func (request *CaptureHoldRequest) Validate(hold *model.Hold) error {
+	if request.Amount == nil {
+		return nil
+	} else if !request.Amount.IsPositive() {
+		return errors.NewValidationError("amount", "The amount has to be positive")
+	} else if request.Amount.GreaterThan(hold.Amount) {
+		return errors.NewValidationError("amount", "The captured amount cannot be more than the held amount")
+	} else {
+		return nil
+	}
+}
+
+func (request *CaptureHoldRequest) TransferRequest(hold *model.Hold) *TransferRequest {
+	transfer := &TransferRequest{From: hold.From, To: hold.To, Amount: hold.Amount, Convert: hold.Convert}
+	if request.Amount != nil {
+		transfer.Amount = *request.Amount
+	}
+	return transfer
+}
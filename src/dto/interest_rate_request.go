@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// The interest rate is yearly, for example 0.02 for 2%, and a zero rate stops the interest
+type InterestRateRequest struct {
+	AccountId    model.AccountId `json:"-"`
+	InterestRate decimal.Decimal `json:"interest_rate"`
+}
+
+func (request *InterestRateRequest) Validate() error {
+	if request.InterestRate.IsNegative() {
+		return errors.NewValidationError("interest_rate", "The interest rate cannot be negative")
+	} else if request.InterestRate.GreaterThan(decimal.NewFromInt(1)) {
+		return errors.NewValidationError("interest_rate", "The interest rate cannot be more than 1, which is 100%")
+	} else {
+		return nil
+	}
+}
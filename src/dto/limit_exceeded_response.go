@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// LimitExceededResponse tells the client how much it can still send before the limit is reached
+type LimitExceededResponse struct {
+	Message   string            `json:"message"`
+	Scope     model.LimitScope  `json:"scope"`
+	Period    model.LimitPeriod `json:"period"`
+	Limit     decimal.Decimal   `json:"limit"`
+	Remaining decimal.Decimal   `json:"remaining"`
+}
+
+func LimitExceededResponseFromError(err *errors.LimitExceededError) *LimitExceededResponse {
+	return &LimitExceededResponse{
+		Message:   err.Error(),
+		Scope:     err.Scope,
+		Period:    err.Period,
+		Limit:     err.Limit,
+		Remaining: err.Remaining,
+	}
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// A zero limit turns the overdraft off
+type OverdraftLimitRequest struct {
+	AccountId      model.AccountId `json:"-"`
+	OverdraftLimit decimal.Decimal `json:"overdraft_limit"`
+}
+
+func (request *OverdraftLimitRequest) Validate() error {
+	if request.OverdraftLimit.IsNegative() {
+		return errors.NewValidationError("overdraft_limit", "The overdraft limit cannot be negative")
+	} else {
+		return nil
+	}
+}
+
+func (request *OverdraftLimitRequest) ValidateCurrency(currency model.Currency) error {
+	if !currency.HasValidPrecision(request.OverdraftLimit) {
+		return errors.NewValidationError("overdraft_limit",
+			fmt.Sprintf("The overdraft limit can have at most %d decimal places in %s", currency.MinorUnits(), currency))
+	} else {
+		return nil
+	}
+}
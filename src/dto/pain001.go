@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// pain001Document is the part of a pain.001 customer credit transfer initiation that the bank reads. The elements
+// are matched by their local names, so that every version of the schema is accepted.
+type pain001Document struct {
+	NumberOfTransactions string           `xml:"CstmrCdtTrfInitn>GrpHdr>NbOfTxs"`
+	ControlSum           string           `xml:"CstmrCdtTrfInitn>GrpHdr>CtrlSum"`
+	Payments             []pain001Payment `xml:"CstmrCdtTrfInitn>PmtInf"`
+}
+
+type pain001Payment struct {
+	Debtor       pain001Account       `xml:"DbtrAcct"`
+	Transactions []pain001Transaction `xml:"CdtTrfTxInf"`
+}
+
+type pain001Transaction struct {
+	EndToEndId string         `xml:"PmtId>EndToEndId"`
+	Amount     pain001Amount  `xml:"Amt>InstdAmt"`
+	Creditor   pain001Account `xml:"CdtrAcct"`
+}
+
+type pain001Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// pain001Account is given by the id of the account in the bank. An account given only by its IBAN is not known.
+type pain001Account struct {
+	Id   string `xml:"Id>Othr>Id"`
+	Iban string `xml:"Id>IBAN"`
+}
+
+// readPain001 numbers the transactions in the order of the file, across all its payments
+func readPain001(body io.Reader) ([]BatchLineRequest, error) {
+	var document pain001Document
+	if err := xml.NewDecoder(body).Decode(&document); err != nil {
+		return nil, errors.NewValidationError("file", "The file is not a valid pain.001 XML")
+	}
+	lines := make([]BatchLineRequest, 0)
+	sum, summed := decimal.Zero, true
+	for _, payment := range document.Payments {
+		from, fromErr := payment.Debtor.accountId("from")
+		for _, transaction := range payment.Transactions {
+			line := BatchLineRequest{
+				Line:      len(lines) + 1,
+				Reference: strings.TrimSpace(transaction.EndToEndId),
+				Currency:  model.Currency(strings.TrimSpace(transaction.Amount.Currency)),
+				Transfer:  TransferRequest{From: from},
+				Err:       fromErr,
+			}
+			to, toErr := transaction.Creditor.accountId("to")
+			amount, amountErr := decimal.NewFromString(strings.TrimSpace(transaction.Amount.Value))
+			line.Transfer.To, line.Transfer.Amount = to, amount
+			if amountErr != nil {
+				summed = false
+				if line.Err == nil {
+					line.Err = errors.NewValidationError("amount", "The amount has to be a number")
+				}
+			} else {
+				sum = sum.Add(amount)
+			}
+			if line.Err == nil && toErr != nil {
+				line.Err = toErr
+			}
+			lines = append(lines, line)
+		}
+	}
+	if count := strings.TrimSpace(document.NumberOfTransactions); count != "" && count != strconv.Itoa(len(lines)) {
+		return nil, errors.NewValidationError("file", fmt.Sprintf("The file contains %d transfers and not %s", len(lines), count))
+	} else if controlSum := strings.TrimSpace(document.ControlSum); controlSum != "" && summed {
+		if expected, err := decimal.NewFromString(controlSum); err != nil || !expected.Equal(sum) {
+			return nil, errors.NewValidationError("file", fmt.Sprintf("The amounts of the file add up to %s and not %s", sum, controlSum))
+		}
+	}
+	return lines, nil
+}
+
+func (account *pain001Account) accountId(field string) (model.AccountId, error) {
+	if id := strings.TrimSpace(account.Id); id != "" {
+		if parsed, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return 0, errors.NewValidationError(field, "The id has to be a number")
+		} else {
+			return model.AccountId(parsed), nil
+		}
+	} else if strings.TrimSpace(account.Iban) != "" {
+		return 0, errors.NewValidationError(field, "The account has to be given by its id, IBANs are not supported")
+	} else {
+		return 0, errors.NewValidationError(field, "The account is required")
+	}
+}
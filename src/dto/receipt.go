@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+// The balance of a receipt is the one of the source account right after the transaction and its fee
+type TransferReceipt struct {
+	Id           model.TransferId        `json:"id"`
+	Status       model.TransactionStatus `json:"status"`
+	From         model.AccountId         `json:"from"`
+	To           model.AccountId         `json:"to"`
+	Amount       decimal.Decimal         `json:"amount"`
+	CreditAmount decimal.Decimal         `json:"credit_amount"`
+	FxRate       *decimal.Decimal        `json:"fx_rate,omitempty"`
+	Fee          decimal.Decimal         `json:"fee"`
+	Balance      decimal.Decimal         `json:"balance"`
+	ReversalOf   *model.TransferId       `json:"reversal_of,omitempty"`
+	ReversedBy   *model.TransferId       `json:"reversed_by,omitempty"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+type TopUpReceipt struct {
+	Id        model.LedgerEntryId     `json:"id"`
+	Status    model.TransactionStatus `json:"status"`
+	AccountId model.AccountId         `json:"account_id"`
+	Amount    decimal.Decimal         `json:"amount"`
+	Fee       decimal.Decimal         `json:"fee"`
+	Balance   decimal.Decimal         `json:"balance"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+func TransferReceiptFromModel(transfer *model.Transfer) *TransferReceipt {
+	receipt := &TransferReceipt{
+		Id:           transfer.Id,
+		Status:       transfer.Status(),
+		From:         transfer.From,
+		To:           transfer.To,
+		Amount:       transfer.Amount,
+		CreditAmount: transfer.CreditAmount,
+		Fee:          transfer.Fee,
+		Balance:      transfer.Balance,
+		ReversalOf:   transfer.ReversalOf,
+		ReversedBy:   transfer.ReversedBy,
+		CreatedAt:    transfer.CreatedAt,
+	}
+	if transfer.FxRate.Valid {
+		receipt.FxRate = &transfer.FxRate.Decimal
+	}
+	return receipt
+}
+
+func TransferReceiptsFromModel(transfers []model.Transfer) []*TransferReceipt {
+	receipts := make([]*TransferReceipt, len(transfers))
+	for i := range transfers {
+		receipts[i] = TransferReceiptFromModel(&transfers[i])
+	}
+	return receipts
+}
+
+func TopUpReceiptFromModel(entry *model.LedgerEntry) *TopUpReceipt {
+	return &TopUpReceipt{
+		Id:        entry.Id,
+		Status:    model.CompletedTransaction,
+		AccountId: entry.AccountId,
+		Amount:    entry.Amount,
+		Fee:       entry.Fee,
+		Balance:   entry.Balance,
+		CreatedAt: entry.CreatedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// A partial reversal returns whatever the receiver has left, instead of failing when they have spent part of the money
+type ReverseTransferRequest struct {
+	Id      model.TransferId `json:"-"`
+	Partial bool             `json:"partial"`
+}
+
+func (request *ReverseTransferRequest) Validate() error {
+	if request.Id <= 0 {
+		return errors.NewValidationError("id", "The id has to be positive")
+	} else {
+		return nil
+	}
+}
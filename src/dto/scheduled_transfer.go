@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type ScheduledTransfer struct {
+	Id           model.ScheduledTransferId     `json:"id"`
+	Status       model.ScheduledTransferStatus `json:"status"`
+	From         model.AccountId               `json:"from"`
+	To           model.AccountId               `json:"to"`
+	Amount       decimal.Decimal               `json:"amount"`
+	Convert      bool                          `json:"convert"`
+	ExecuteAt    time.Time                     `json:"execute_at"`
+	TransferId   *model.TransferId             `json:"transfer_id,omitempty"`
+	ErrorType    *string                       `json:"error_type,omitempty"`
+	ErrorMessage *string                       `json:"error_message,omitempty"`
+	CreatedAt    time.Time                     `json:"created_at"`
+}
+
+func ScheduledTransferFromModel(transfer *model.ScheduledTransfer) *ScheduledTransfer {
+	return &ScheduledTransfer{
+		Id:           transfer.Id,
+		Status:       transfer.Status,
+		From:         transfer.From,
+		To:           transfer.To,
+		Amount:       transfer.Amount,
+		Convert:      transfer.Convert,
+		ExecuteAt:    transfer.ExecuteAt,
+		TransferId:   transfer.TransferId,
+		ErrorType:    transfer.ErrorType,
+		ErrorMessage: transfer.ErrorMessage,
+		CreatedAt:    transfer.CreatedAt,
+	}
+}
+
+func ScheduledTransfersFromModel(transfers []model.ScheduledTransfer) []*ScheduledTransfer {
+	result := make([]*ScheduledTransfer, len(transfers))
+	for i := range transfers {
+		result[i] = ScheduledTransferFromModel(&transfers[i])
+	}
+	return result
+}
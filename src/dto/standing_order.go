@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type StandingOrder struct {
+	Id             model.StandingOrderId     `json:"id"`
+	Status         model.StandingOrderStatus `json:"status"`
+	From           model.AccountId           `json:"from"`
+	To             model.AccountId           `json:"to"`
+	Amount         decimal.Decimal           `json:"amount"`
+	Convert        bool                      `json:"convert"`
+	Frequency      model.Frequency           `json:"frequency"`
+	Day            int                       `json:"day,omitempty"`
+	EndDate        *Date                     `json:"end_date,omitempty"`
+	NextRunOn      *Date                     `json:"next_run_on,omitempty"`
+	Failures       int                       `json:"failures"`
+	LastTransferId *model.TransferId         `json:"last_transfer_id,omitempty"`
+	ErrorType      *string                   `json:"error_type,omitempty"`
+	ErrorMessage   *string                   `json:"error_message,omitempty"`
+	CreatedAt      time.Time                 `json:"created_at"`
+}
+
+// An order that has ended has no next run
+func StandingOrderFromModel(order *model.StandingOrder) *StandingOrder {
+	result := &StandingOrder{
+		Id:             order.Id,
+		Status:         order.Status,
+		From:           order.From,
+		To:             order.To,
+		Amount:         order.Amount,
+		Convert:        order.Convert,
+		Frequency:      order.Frequency,
+		Day:            order.Day,
+		Failures:       order.Failures,
+		LastTransferId: order.LastTransferId,
+		ErrorType:      order.ErrorType,
+		ErrorMessage:   order.ErrorMessage,
+		CreatedAt:      order.CreatedAt,
+	}
+	if order.EndDate != nil {
+		result.EndDate = NewDate(*order.EndDate)
+	}
+	if !order.IsEnded() {
+		result.NextRunOn = NewDate(order.NextRunOn)
+	}
+	return result
+}
+
+func StandingOrdersFromModel(orders []model.StandingOrder) []*StandingOrder {
+	result := make([]*StandingOrder, len(orders))
+	for i := range orders {
+		result[i] = StandingOrderFromModel(&orders[i])
+	}
+	return result
+}
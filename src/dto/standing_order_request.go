@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+// The start date is today when it is missing, and an order without an end date runs until it is cancelled
+type StandingOrderRequest struct {
+	Id        model.StandingOrderId `json:"-"`
+	From      model.AccountId       `json:"from"`
+	To        model.AccountId       `json:"to"`
+	Amount    decimal.Decimal       `json:"amount"`
+	Convert   bool                  `json:"convert"`
+	Frequency model.Frequency       `json:"frequency"`
+	Day       int                   `json:"day"`
+	StartDate *Date                 `json:"start_date"`
+	EndDate   *Date                 `json:"end_date"`
+}
+
+func (request *StandingOrderRequest) Validate(today time.Time) error {
+	if err := request.TransferRequest().Validate(); err != nil {
+		return err
+	} else if !request.Frequency.IsValid() {
+		return errors.NewValidationError("frequency", "The frequency has to be one of 'daily', 'weekly' or 'monthly'")
+	} else if request.Frequency == model.DailyFrequency && request.Day != 0 {
+		return errors.NewValidationError("day", "A daily order cannot have a day")
+	} else if request.Frequency == model.WeeklyFrequency && (request.Day < 1 || request.Day > 7) {
+		return errors.NewValidationError("day", "The day of a weekly order has to be between 1 (Monday) and 7 (Sunday)")
+	} else if request.Frequency == model.MonthlyFrequency && (request.Day < 1 || request.Day > 31) {
+		return errors.NewValidationError("day", "The day of a monthly order has to be between 1 and 31")
+	} else if request.StartDate != nil && request.StartDate.Before(today) {
+		return errors.NewValidationError("start_date", "The start date cannot be in the past")
+	} else if request.EndDate != nil && request.recurrence().First(request.start(today)).After(request.EndDate.Time) {
+		return errors.NewValidationError("end_date", "The order would not run before the end date")
+	} else {
+		return nil
+	}
+}
+
+func (request *StandingOrderRequest) TransferRequest() *TransferRequest {
+	return &TransferRequest{From: request.From, To: request.To, Amount: request.Amount, Convert: request.Convert}
+}
+
+// StandingOrder schedules the first run on the first day of the recurrence from the start date, but not before the earliest day
+func (request *StandingOrderRequest) StandingOrder(owner model.UserId, earliest time.Time) *model.StandingOrder {
+	order := &model.StandingOrder{
+		Recurrence: request.recurrence(),
+		Id:         request.Id,
+		Owner:      owner,
+		From:       request.From,
+		To:         request.To,
+		Amount:     request.Amount,
+		Convert:    request.Convert,
+		Status:     model.ActiveStandingOrder,
+	}
+	if request.EndDate != nil {
+		order.EndDate = &request.EndDate.Time
+	}
+	order.Reschedule(request.start(earliest))
+	return order
+}
+
+func (request *StandingOrderRequest) start(earliest time.Time) time.Time {
+	if request.StartDate != nil && request.StartDate.After(earliest) {
+		return request.StartDate.Time
+	} else {
+		return earliest
+	}
+}
+
+func (request *StandingOrderRequest) recurrence() model.Recurrence {
+	return model.Recurrence{Frequency: request.Frequency, Day: request.Day}
+}
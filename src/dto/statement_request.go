@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"net/url"
+	"time"
+)
+
+type StatementRequest struct {
+	AccountId model.AccountId
+	From      *time.Time
+	To        *time.Time
+	Format    model.StatementFormat
+}
+
+// NewStatementRequest reads the period like NewTransactionsRequest, and the format is json by default
+func NewStatementRequest(accountId model.AccountId, query url.Values) (*StatementRequest, error) {
+	request := &StatementRequest{AccountId: accountId, Format: model.JsonStatement}
+	if from := query.Get("from"); from != "" {
+		if parsed, err := parseTime(from, false); err != nil {
+			return nil, errors.NewValidationError("from", "The date must be in the format YYYY-MM-DD or RFC 3339")
+		} else {
+			request.From = &parsed
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if parsed, err := parseTime(to, true); err != nil {
+			return nil, errors.NewValidationError("to", "The date must be in the format YYYY-MM-DD or RFC 3339")
+		} else {
+			request.To = &parsed
+		}
+	}
+	if format := query.Get("format"); format != "" {
+		request.Format = model.StatementFormat(format)
+	}
+	return request, nil
+}
+
+func (request *StatementRequest) Validate() error {
+	if request.AccountId <= 0 {
+		return errors.NewValidationError("id", "The id has to be positive")
+	} else if request.From == nil {
+		return errors.NewValidationError("from", "The start of the period is required")
+	} else if request.To == nil {
+		return errors.NewValidationError("to", "The end of the period is required")
+	} else if !request.From.Before(*request.To) {
+		return errors.NewValidationError("to", "The end of the period has to be after the start")
+	} else if !request.Format.IsValid() {
+		return errors.NewValidationError("format", "The format has to be csv, json, txt or camt053")
+	} else {
+		return nil
+	}
+}
+
+func (request *StatementRequest) Statement(account *model.Account, createdAt time.Time) *model.Statement {
+	return &model.Statement{Account: account, From: request.From.UTC(), To: request.To.UTC(), CreatedAt: createdAt.UTC()}
+}
@@ -20,3 +20,7 @@ func (request *TopUpRequest) Validate() error {
 		return nil
 	}
 }
+
+func (request *TopUpRequest) ValidateCurrency(currency model.Currency) error {
+	return validateAmountPrecision(request.Amount, currency)
+}
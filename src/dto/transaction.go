@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"encoding/base64"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"strconv"
+	"time"
+)
+
+type Transaction struct {
+	Id           model.LedgerEntryId   `json:"id"`
+	Type         model.LedgerEntryType `json:"type"`
+	Amount       decimal.Decimal       `json:"amount"`
+	Balance      decimal.Decimal       `json:"balance"`
+	Counterparty *model.AccountId      `json:"counterparty,omitempty"`
+	FxRate       *decimal.Decimal      `json:"fx_rate,omitempty"`
+	Fee          *decimal.Decimal      `json:"fee,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+}
+
+func TransactionFromModel(entry *model.LedgerEntry) *Transaction {
+	transaction := &Transaction{
+		Id:           entry.Id,
+		Type:         entry.Type,
+		Amount:       entry.Amount,
+		Balance:      entry.Balance,
+		Counterparty: entry.Counterparty,
+		CreatedAt:    entry.CreatedAt,
+	}
+	if entry.FxRate.Valid {
+		transaction.FxRate = &entry.FxRate.Decimal
+	}
+	if entry.Fee.IsPositive() {
+		transaction.Fee = &entry.Fee
+	}
+	return transaction
+}
+
+func TransactionPageFromModel(page *model.LedgerPage) *TransactionPage {
+	transactions := make([]*Transaction, len(page.Entries))
+	for i := range page.Entries {
+		transactions[i] = TransactionFromModel(&page.Entries[i])
+	}
+	result := &TransactionPage{Transactions: transactions}
+	if page.Next != nil {
+		result.NextCursor = encodeCursor(*page.Next)
+	}
+	return result
+}
+
+func encodeCursor(id model.LedgerEntryId) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(int64(id), 10)))
+}
+
+func decodeCursor(cursor string) (model.LedgerEntryId, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(cursor); err != nil {
+		return 0, err
+	} else if id, err := strconv.ParseInt(string(decoded), 10, 64); err != nil {
+		return 0, err
+	} else {
+		return model.LedgerEntryId(id), nil
+	}
+}
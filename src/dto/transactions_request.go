@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTransactionsLimit = 20
+	MaxTransactionsLimit     = 100
+	dateLayout               = "2006-01-02"
+)
+
+type TransactionsRequest struct {
+	AccountId model.AccountId
+	Cursor    *model.LedgerEntryId
+	From      *time.Time
+	To        *time.Time
+	Types     []model.LedgerEntryType
+	Limit     int
+}
+
+func NewTransactionsRequest(accountId model.AccountId, query url.Values) (*TransactionsRequest, error) {
+	request := &TransactionsRequest{AccountId: accountId, Limit: DefaultTransactionsLimit}
+	if cursor := query.Get("cursor"); cursor != "" {
+		if id, err := decodeCursor(cursor); err != nil {
+			return nil, errors.NewValidationError("cursor", "The cursor is not valid")
+		} else {
+			request.Cursor = &id
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if parsed, err := strconv.Atoi(limit); err != nil {
+			return nil, errors.NewValidationError("limit", "The limit must be a number")
+		} else {
+			request.Limit = parsed
+		}
+	}
+	if from := query.Get("from"); from != "" {
+		if parsed, err := parseTime(from, false); err != nil {
+			return nil, errors.NewValidationError("from", "The date must be in the format YYYY-MM-DD or RFC 3339")
+		} else {
+			request.From = &parsed
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if parsed, err := parseTime(to, true); err != nil {
+			return nil, errors.NewValidationError("to", "The date must be in the format YYYY-MM-DD or RFC 3339")
+		} else {
+			request.To = &parsed
+		}
+	}
+	if types := query.Get("type"); types != "" {
+		for _, entryType := range strings.Split(types, ",") {
+			request.Types = append(request.Types, model.LedgerEntryType(strings.TrimSpace(entryType)))
+		}
+	}
+	return request, nil
+}
+
+// A date without time in 'to' covers the whole day, so it is moved to the start of the next day
+func parseTime(value string, endOfDay bool) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	} else if parsed, err := time.Parse(dateLayout, value); err != nil {
+		return time.Time{}, err
+	} else if endOfDay {
+		return parsed.AddDate(0, 0, 1), nil
+	} else {
+		return parsed, nil
+	}
+}
+
+func (request *TransactionsRequest) Validate() error {
+	if request.AccountId <= 0 {
+		return errors.NewValidationError("id", "The id has to be positive")
+	} else if request.Limit <= 0 || request.Limit > MaxTransactionsLimit {
+		return errors.NewValidationError("limit", "The limit has to be between 1 and "+strconv.Itoa(MaxTransactionsLimit))
+	} else if request.From != nil && request.To != nil && !request.From.Before(*request.To) {
+		return errors.NewValidationError("to", "The end of the period has to be after the start")
+	} else {
+		for _, entryType := range request.Types {
+			if !entryType.IsValid() {
+				return errors.NewValidationError("type", "Unknown transaction type '"+string(entryType)+"'")
+			}
+		}
+		return nil
+	}
+}
+
+func (request *TransactionsRequest) Filter() *model.LedgerFilter {
+	return &model.LedgerFilter{
+		Before: request.Cursor,
+		From:   request.From,
+		To:     request.To,
+		Types:  request.Types,
+		Limit:  request.Limit,
+	}
+}
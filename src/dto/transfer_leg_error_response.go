@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"golang_bank_demo/src/errors"
+)
+
+// TransferLegErrorResponse tells which leg failed, counted from 1, with the type and the message of its error
+type TransferLegErrorResponse struct {
+	Message      string `json:"message"`
+	Leg          int    `json:"leg"`
+	ErrorType    string `json:"error_type"`
+	ErrorMessage string `json:"error_message"`
+}
+
+func TransferLegErrorResponseFromError(err *errors.BatchTransferError) *TransferLegErrorResponse {
+	return &TransferLegErrorResponse{
+		Message:      err.Error(),
+		Leg:          err.Index + 1,
+		ErrorType:    errors.TypeName(err.Err),
+		ErrorMessage: err.Err.Error(),
+	}
+}
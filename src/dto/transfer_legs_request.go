@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+const maxTransferLegs = 100
+
+// TransferLegsRequest pays out from one source account to many accounts at once
+type TransferLegsRequest struct {
+	From model.AccountId `json:"from"`
+	Legs []TransferLeg   `json:"legs"`
+}
+
+type TransferLeg struct {
+	To     model.AccountId `json:"to"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// Validate checks every leg like a single transfer, and the error of an invalid leg is a BatchTransferError with its index
+func (request *TransferLegsRequest) Validate() error {
+	if request.From <= 0 {
+		return errors.NewValidationError("from", "The id has to be positive")
+	} else if len(request.Legs) == 0 {
+		return errors.NewValidationError("legs", "There has to be at least one leg")
+	} else if len(request.Legs) > maxTransferLegs {
+		return errors.NewValidationError("legs", fmt.Sprintf("There can be at most %d legs", maxTransferLegs))
+	}
+	for i, transfer := range request.TransferRequests() {
+		if err := transfer.Validate(); err != nil {
+			return &errors.BatchTransferError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (request *TransferLegsRequest) TransferRequests() []*TransferRequest {
+	requests := make([]*TransferRequest, len(request.Legs))
+	for i, leg := range request.Legs {
+		requests[i] = &TransferRequest{From: request.From, To: leg.To, Amount: leg.Amount}
+	}
+	return requests
+}
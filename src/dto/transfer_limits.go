@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// A missing limit falls back to the default of the bank
+type TransferLimits struct {
+	PerTransaction *decimal.Decimal `json:"per_transaction,omitempty"`
+	Daily          *decimal.Decimal `json:"daily,omitempty"`
+	Monthly        *decimal.Decimal `json:"monthly,omitempty"`
+}
+
+type TransferLimitsRequest struct {
+	AccountId model.AccountId `json:"-"`
+	TransferLimits
+}
+
+func (request *TransferLimitsRequest) Validate(currency model.Currency) error {
+	if err := validateLimit("per_transaction", request.PerTransaction, currency); err != nil {
+		return err
+	} else if err := validateLimit("daily", request.Daily, currency); err != nil {
+		return err
+	} else {
+		return validateLimit("monthly", request.Monthly, currency)
+	}
+}
+
+func validateLimit(field string, limit *decimal.Decimal, currency model.Currency) error {
+	if limit == nil {
+		return nil
+	} else if !limit.IsPositive() {
+		return errors.NewValidationError(field, "The limit has to be positive")
+	} else if !currency.HasValidPrecision(*limit) {
+		return errors.NewValidationError(field, fmt.Sprintf("The limit can have at most %d decimal places in %s", currency.MinorUnits(), currency))
+	} else {
+		return nil
+	}
+}
+
+func (request *TransferLimitsRequest) Limits() model.TransferLimits {
+	return model.TransferLimits{
+		PerTransactionLimit: nullDecimal(request.PerTransaction),
+		DailyLimit:          nullDecimal(request.Daily),
+		MonthlyLimit:        nullDecimal(request.Monthly),
+	}
+}
+
+// TransferLimitsFromModel returns nil when the account has no limits of its own
+func TransferLimitsFromModel(limits *model.TransferLimits) *TransferLimits {
+	if limits.IsEmpty() {
+		return nil
+	}
+	return &TransferLimits{
+		PerTransaction: decimalPointer(limits.PerTransactionLimit),
+		Daily:          decimalPointer(limits.DailyLimit),
+		Monthly:        decimalPointer(limits.MonthlyLimit),
+	}
+}
+
+func nullDecimal(value *decimal.Decimal) decimal.NullDecimal {
+	if value == nil {
+		return decimal.NullDecimal{}
+	} else {
+		return decimal.NullDecimal{Decimal: *value, Valid: true}
+	}
+}
+
+func decimalPointer(value decimal.NullDecimal) *decimal.Decimal {
+	if !value.Valid {
+		return nil
+	} else {
+		return &value.Decimal
+	}
+}
@@ -4,22 +4,54 @@ import (
 	"github.com/shopspring/decimal"
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
+	"time"
 )
 
+// A request with the execution time schedules the transfer instead of moving the money right away.
+// The origin is set by the scheduled runs only, which are made once for every origin.
 type TransferRequest struct {
-	From   model.AccountId `json:"from"`
-	To     model.AccountId `json:"to"`
-	Amount decimal.Decimal `json:"amount"`
+	From      model.AccountId `json:"from"`
+	To        model.AccountId `json:"to"`
+	Amount    decimal.Decimal `json:"amount"`
+	Convert   bool            `json:"convert"`
+	ExecuteAt *time.Time      `json:"execute_at,omitempty"`
+	Origin    *string         `json:"-"`
 }
 
+// Validate refuses a transfer to the source account itself, whose two ledger entries could not both show the right balance
 func (request *TransferRequest) Validate() error {
 	if request.From <= 0 {
 		return errors.NewValidationError("from", "The id has to be positive")
 	} else if request.To <= 0 {
 		return errors.NewValidationError("to", "The id has to be positive")
+	} else if request.To == request.From {
+		return errors.NewValidationError("to", "The money has to go to another account")
 	} else if request.Amount.LessThanOrEqual(decimal.NewFromInt(0)) {
 		return errors.NewValidationError("amount", "The amount has to be positive")
 	} else {
 		return nil
 	}
 }
+
+func (request *TransferRequest) ValidateCurrency(currency model.Currency) error {
+	return validateAmountPrecision(request.Amount, currency)
+}
+
+func (request *TransferRequest) ValidateExecuteAt(now time.Time) error {
+	if request.ExecuteAt == nil || !request.ExecuteAt.After(now) {
+		return errors.NewValidationError("execute_at", "The execution time has to be in the future")
+	} else {
+		return nil
+	}
+}
+
+func (request *TransferRequest) ScheduledTransfer(owner model.UserId) *model.ScheduledTransfer {
+	return &model.ScheduledTransfer{
+		Owner:     owner,
+		From:      request.From,
+		To:        request.To,
+		Amount:    request.Amount,
+		Convert:   request.Convert,
+		ExecuteAt: *request.ExecuteAt,
+	}
+}
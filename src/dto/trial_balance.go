@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+)
+
+type CurrencyTotal struct {
+	Currency model.Currency  `json:"currency"`
+	Debits   decimal.Decimal `json:"debits"`
+	Credits  decimal.Decimal `json:"credits"`
+}
+
+type BalanceMismatch struct {
+	AccountId      model.AccountId `json:"account_id"`
+	Balance        decimal.Decimal `json:"balance"`
+	JournalBalance decimal.Decimal `json:"journal_balance"`
+}
+
+type TrialBalance struct {
+	Balanced   bool              `json:"balanced"`
+	Totals     []CurrencyTotal   `json:"totals"`
+	Mismatches []BalanceMismatch `json:"mismatches"`
+}
+
+func TrialBalanceFromModel(trialBalance *model.TrialBalance) *TrialBalance {
+	result := &TrialBalance{
+		Balanced:   trialBalance.IsBalanced(),
+		Totals:     make([]CurrencyTotal, len(trialBalance.Totals)),
+		Mismatches: make([]BalanceMismatch, len(trialBalance.Mismatches)),
+	}
+	for i, total := range trialBalance.Totals {
+		result.Totals[i] = CurrencyTotal(total)
+	}
+	for i, mismatch := range trialBalance.Mismatches {
+		result.Mismatches[i] = BalanceMismatch(mismatch)
+	}
+	return result
+}
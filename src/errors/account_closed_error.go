@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type AccountClosedError struct {
+	AccountId model.AccountId
+}
+
+func (err *AccountClosedError) Error() string {
+	return fmt.Sprintf("The account %d is closed", err.AccountId)
+}
+
+func (err *AccountClosedError) Is(target error) bool {
+	t, ok := target.(*AccountClosedError)
+	if ok {
+		return t.AccountId == err.AccountId
+	} else {
+		return false
+	}
+}
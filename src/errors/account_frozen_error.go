@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type AccountFrozenError struct {
+	AccountId model.AccountId
+}
+
+func (err *AccountFrozenError) Error() string {
+	return fmt.Sprintf("The account %d is frozen", err.AccountId)
+}
+
+func (err *AccountFrozenError) Is(target error) bool {
+	t, ok := target.(*AccountFrozenError)
+	if ok {
+		return t.AccountId == err.AccountId
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,17 @@
+package errors
+
+import (
+	"golang_bank_demo/src/model"
+)
+
+// CheckActive returns the error explaining why money cannot move in or out of the account, or nil for active accounts
+func CheckActive(account *model.Account) error {
+	switch account.Status {
+	case model.FrozenAccount:
+		return &AccountFrozenError{AccountId: account.Id}
+	case model.ClosedAccount:
+		return &AccountClosedError{AccountId: account.Id}
+	default:
+		return nil
+	}
+}
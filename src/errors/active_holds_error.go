@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type ActiveHoldsError struct {
+	AccountId model.AccountId
+}
+
+func (err *ActiveHoldsError) Error() string {
+	return fmt.Sprintf("The account %d cannot be closed with active holds", err.AccountId)
+}
+
+func (err *ActiveHoldsError) Is(target error) bool {
+	t, ok := target.(*ActiveHoldsError)
+	if ok {
+		return t.AccountId == err.AccountId
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type AdminAccessRequiredError struct {
+	UserId model.UserId
+}
+
+func (err *AdminAccessRequiredError) Error() string {
+	return fmt.Sprintf("The user %d is not an administrator", err.UserId)
+}
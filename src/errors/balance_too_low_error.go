@@ -2,15 +2,27 @@ package errors
 
 import (
 	"fmt"
+	"github.com/shopspring/decimal"
 	"golang_bank_demo/src/model"
 )
 
+// The shortfall is how much more money the account would need, and it is zero when that is not known
 type BalanceTooLowError struct {
 	AccountId model.AccountId
+	Shortfall decimal.Decimal
+}
+
+// NewBalanceTooLowError tells how much the available money of the account is short of the amount
+func NewBalanceTooLowError(account *model.Account, amount decimal.Decimal) error {
+	return &BalanceTooLowError{AccountId: account.Id, Shortfall: amount.Sub(account.Available())}
 }
 
 func (err *BalanceTooLowError) Error() string {
-	return fmt.Sprintf("The account %d does not have enough money", err.AccountId)
+	if err.Shortfall.IsPositive() {
+		return fmt.Sprintf("The account %d does not have enough money, %s more is needed", err.AccountId, err.Shortfall)
+	} else {
+		return fmt.Sprintf("The account %d does not have enough money", err.AccountId)
+	}
 }
 
 func (err *BalanceTooLowError) Is(target error) bool {
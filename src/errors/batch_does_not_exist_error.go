@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type BatchDoesNotExistError struct {
+	BatchId model.BatchId
+}
+
+func (err *BatchDoesNotExistError) Error() string {
+	return fmt.Sprintf("The batch %d does not exist", err.BatchId)
+}
+
+func (err *BatchDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*BatchDoesNotExistError)
+	if ok {
+		return t.BatchId == err.BatchId
+	} else {
+		return false
+	}
+}
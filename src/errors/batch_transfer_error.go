@@ -0,0 +1,28 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// BatchTransferError is the error of one of the transfers made together, which are counted from zero
+type BatchTransferError struct {
+	Index int
+	Err   error
+}
+
+func (err *BatchTransferError) Error() string {
+	return fmt.Sprintf("The transfer %d of the batch failed: %s", err.Index+1, err.Err.Error())
+}
+
+func (err *BatchTransferError) Unwrap() error {
+	return err.Err
+}
+
+func (err *BatchTransferError) Is(target error) bool {
+	t, ok := target.(*BatchTransferError)
+	if ok {
+		return t.Index == err.Index
+	} else {
+		return false
+	}
+}
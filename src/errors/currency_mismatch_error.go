@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type CurrencyMismatchError struct {
+	From         model.AccountId
+	To           model.AccountId
+	FromCurrency model.Currency
+	ToCurrency   model.Currency
+}
+
+func (err *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("The account %d in %s cannot transfer money to the account %d in %s without a conversion", err.From, err.FromCurrency, err.To, err.ToCurrency)
+}
+
+func (err *CurrencyMismatchError) Is(target error) bool {
+	t, ok := target.(*CurrencyMismatchError)
+	if ok {
+		return t.From == err.From && t.To == err.To && t.FromCurrency == err.FromCurrency && t.ToCurrency == err.ToCurrency
+	} else {
+		return false
+	}
+}
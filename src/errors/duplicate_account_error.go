@@ -7,16 +7,17 @@ import (
 
 type DuplicateAccountError struct {
 	UserId model.UserId
+	Name   string
 }
 
 func (err *DuplicateAccountError) Error() string {
-	return fmt.Sprintf("The user %d already has an account", err.UserId)
+	return fmt.Sprintf("The user %d already has an account named '%s'", err.UserId, err.Name)
 }
 
 func (err *DuplicateAccountError) Is(target error) bool {
 	t, ok := target.(*DuplicateAccountError)
 	if ok {
-		return t.UserId == err.UserId
+		return t.UserId == err.UserId && t.Name == err.Name
 	} else {
 		return false
 	}
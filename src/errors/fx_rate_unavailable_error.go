@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type FxRateUnavailableError struct {
+	From model.Currency
+	To   model.Currency
+}
+
+func (err *FxRateUnavailableError) Error() string {
+	return fmt.Sprintf("The exchange rate from %s to %s is not available", err.From, err.To)
+}
+
+func (err *FxRateUnavailableError) Is(target error) bool {
+	t, ok := target.(*FxRateUnavailableError)
+	if ok {
+		return t.From == err.From && t.To == err.To
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type HoldDoesNotExistError struct {
+	HoldId model.HoldId
+}
+
+func (err *HoldDoesNotExistError) Error() string {
+	return fmt.Sprintf("The hold %d does not exist", err.HoldId)
+}
+
+func (err *HoldDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*HoldDoesNotExistError)
+	if ok {
+		return t.HoldId == err.HoldId
+	} else {
+		return false
+	}
+}
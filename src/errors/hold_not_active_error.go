@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type HoldNotActiveError struct {
+	HoldId model.HoldId
+	Status model.HoldStatus
+}
+
+func (err *HoldNotActiveError) Error() string {
+	return fmt.Sprintf("The hold %d is already %s", err.HoldId, err.Status)
+}
+
+func (err *HoldNotActiveError) Is(target error) bool {
+	t, ok := target.(*HoldNotActiveError)
+	if ok {
+		return t.HoldId == err.HoldId && t.Status == err.Status
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package errors
+
+import (
+	"fmt"
+)
+
+type IdempotencyKeyInProgressError struct {
+	Key string
+}
+
+func (err *IdempotencyKeyInProgressError) Error() string {
+	return fmt.Sprintf("The request with the idempotency key '%s' is still in progress", err.Key)
+}
+
+func (err *IdempotencyKeyInProgressError) Is(target error) bool {
+	t, ok := target.(*IdempotencyKeyInProgressError)
+	if ok {
+		return t.Key == err.Key
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package errors
+
+import (
+	"fmt"
+)
+
+type IdempotencyKeyReuseError struct {
+	Key string
+}
+
+func (err *IdempotencyKeyReuseError) Error() string {
+	return fmt.Sprintf("The idempotency key '%s' was already used for a different request", err.Key)
+}
+
+func (err *IdempotencyKeyReuseError) Is(target error) bool {
+	t, ok := target.(*IdempotencyKeyReuseError)
+	if ok {
+		return t.Key == err.Key
+	} else {
+		return false
+	}
+}
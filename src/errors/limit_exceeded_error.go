@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"strings"
+)
+
+// The remaining allowance is what could still be sent without exceeding the limit
+type LimitExceededError struct {
+	Scope     model.LimitScope
+	Id        int64
+	Period    model.LimitPeriod
+	Limit     decimal.Decimal
+	Remaining decimal.Decimal
+}
+
+func (err *LimitExceededError) Error() string {
+	return fmt.Sprintf("The transfer exceeds the %s limit of %s of the %s %d, %s is left",
+		strings.ReplaceAll(string(err.Period), "_", "-"), err.Limit, err.Scope, err.Id, err.Remaining)
+}
+
+func (err *LimitExceededError) Is(target error) bool {
+	t, ok := target.(*LimitExceededError)
+	if ok {
+		return t.Scope == err.Scope && t.Id == err.Id && t.Period == err.Period
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type NonZeroBalanceError struct {
+	AccountId model.AccountId
+}
+
+func (err *NonZeroBalanceError) Error() string {
+	return fmt.Sprintf("The account %d cannot be closed with a non-zero balance", err.AccountId)
+}
+
+func (err *NonZeroBalanceError) Is(target error) bool {
+	t, ok := target.(*NonZeroBalanceError)
+	if ok {
+		return t.AccountId == err.AccountId
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type ScheduledTransferDoesNotExistError struct {
+	ScheduledTransferId model.ScheduledTransferId
+}
+
+func (err *ScheduledTransferDoesNotExistError) Error() string {
+	return fmt.Sprintf("The scheduled transfer %d does not exist", err.ScheduledTransferId)
+}
+
+func (err *ScheduledTransferDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*ScheduledTransferDoesNotExistError)
+	if ok {
+		return t.ScheduledTransferId == err.ScheduledTransferId
+	} else {
+		return false
+	}
+}
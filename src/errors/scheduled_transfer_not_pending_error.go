@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type ScheduledTransferNotPendingError struct {
+	ScheduledTransferId model.ScheduledTransferId
+	Status              model.ScheduledTransferStatus
+}
+
+func (err *ScheduledTransferNotPendingError) Error() string {
+	return fmt.Sprintf("The scheduled transfer %d is already %s", err.ScheduledTransferId, err.Status)
+}
+
+func (err *ScheduledTransferNotPendingError) Is(target error) bool {
+	t, ok := target.(*ScheduledTransferNotPendingError)
+	if ok {
+		return t.ScheduledTransferId == err.ScheduledTransferId && t.Status == err.Status
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type StandingOrderDoesNotExistError struct {
+	StandingOrderId model.StandingOrderId
+}
+
+func (err *StandingOrderDoesNotExistError) Error() string {
+	return fmt.Sprintf("The standing order %d does not exist", err.StandingOrderId)
+}
+
+func (err *StandingOrderDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*StandingOrderDoesNotExistError)
+	if ok {
+		return t.StandingOrderId == err.StandingOrderId
+	} else {
+		return false
+	}
+}
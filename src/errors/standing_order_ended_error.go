@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type StandingOrderEndedError struct {
+	StandingOrderId model.StandingOrderId
+	Status          model.StandingOrderStatus
+}
+
+func (err *StandingOrderEndedError) Error() string {
+	return fmt.Sprintf("The standing order %d is already %s", err.StandingOrderId, err.Status)
+}
+
+func (err *StandingOrderEndedError) Is(target error) bool {
+	t, ok := target.(*StandingOrderEndedError)
+	if ok {
+		return t.StandingOrderId == err.StandingOrderId && t.Status == err.Status
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type TopUpDoesNotExistError struct {
+	TopUpId model.LedgerEntryId
+}
+
+func (err *TopUpDoesNotExistError) Error() string {
+	return fmt.Sprintf("The top-up %d does not exist", err.TopUpId)
+}
+
+func (err *TopUpDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*TopUpDoesNotExistError)
+	if ok {
+		return t.TopUpId == err.TopUpId
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,15 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type TransferAlreadyReversedError struct {
+	TransferId model.TransferId
+	ReversalId model.TransferId
+}
+
+func (err *TransferAlreadyReversedError) Error() string {
+	return fmt.Sprintf("The transfer %d is already reversed by the transfer %d", err.TransferId, err.ReversalId)
+}
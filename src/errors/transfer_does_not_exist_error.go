@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type TransferDoesNotExistError struct {
+	TransferId model.TransferId
+}
+
+func (err *TransferDoesNotExistError) Error() string {
+	return fmt.Sprintf("The transfer %d does not exist", err.TransferId)
+}
+
+func (err *TransferDoesNotExistError) Is(target error) bool {
+	t, ok := target.(*TransferDoesNotExistError)
+	if ok {
+		return t.TransferId == err.TransferId
+	} else {
+		return false
+	}
+}
@@ -0,0 +1,22 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// TypeName returns the name of the type of the error, leaving out the pointer, which tells the kinds of errors apart
+// in the responses and in the failures that are kept. An error of an unnamed type is described by its type instead.
+func TypeName(err error) string {
+	if err == nil {
+		return ""
+	}
+	errorType := reflect.TypeOf(err)
+	if errorType.Kind() == reflect.Ptr {
+		errorType = errorType.Elem()
+	}
+	if name := errorType.Name(); name != "" {
+		return name
+	} else {
+		return errorType.String()
+	}
+}
@@ -0,0 +1,14 @@
+package errors
+
+import (
+	"fmt"
+	"golang_bank_demo/src/model"
+)
+
+type UnbalancedJournalError struct {
+	Type model.JournalType
+}
+
+func (err *UnbalancedJournalError) Error() string {
+	return fmt.Sprintf("The %s journal is not balanced", err.Type)
+}
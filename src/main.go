@@ -1,37 +1,165 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/shopspring/decimal"
 	"golang_bank_demo/src/api"
 	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/postgres"
 	"golang_bank_demo/src/service"
 	"golang_bank_demo/src/storage"
 	"log"
 	"net/http"
+	"os"
 )
 
 func main() {
 	var appConfig config.AppConfig
 	if err := cleanenv.ReadConfig("config.yaml", &appConfig); err != nil {
 		log.Fatal(err)
-	} else if pgClient, err := postgres.CreateClient(&appConfig); err != nil {
+	} else if len(os.Args) > 1 {
+		if err := runCommand(&appConfig, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	} else if storages, err := createStorages(&appConfig); err != nil {
 		log.Fatal(err)
-	} else if err = postgres.SetUp(pgClient); err != nil {
+	} else if authService, err := createAuthenticationService(&appConfig.Authentication); err != nil {
+		log.Fatal(err)
+	} else if fileFxRates, err := service.NewFileFxRateProvider(appConfig.Fx.RatesFile); err != nil {
+		log.Fatal(err)
+	} else if fees, err := service.NewFileFeeProvider(appConfig.Fees.ScheduleFile); err != nil {
+		log.Fatal(err)
+	} else if limits, err := service.NewLimits(appConfig.Limits); err != nil {
+		log.Fatal(err)
+	} else if accruer, err := service.NewInterestAccruer(storages.interest, service.NewSystemClock(), &appConfig.Interest); err != nil {
+		log.Fatal(err)
+	} else if publisher, err := createEventPublisher(&appConfig.Events); err != nil {
 		log.Fatal(err)
 	} else {
-		accountStorage := storage.NewPostgresAccountStorage(pgClient)
-		accountService := service.NewAccountService(accountStorage)
-		authService := service.NewStubAuthenticationService()
+		fxRates := service.NewSpreadFxRateProvider(fileFxRates, decimal.NewFromFloat(appConfig.Fx.Spread))
+		accountService := service.NewAccountService(storages.account, storages.ledger, storages.scheduled, fxRates, fees, limits)
+		idempotencyService := service.NewIdempotencyService(storages.idempotency, appConfig.Idempotency.Expiration)
 		auth := api.NewAuthenticatedApi(authService)
-		accountApi := api.NewAccountApi(accountService, auth)
+		idempotency := api.NewIdempotentApi(idempotencyService)
+		accountApi := api.NewAccountApi(accountService, auth, idempotency)
+		adminService := service.NewAdminService(storages.account, storages.journal, adminUsers(&appConfig.Admin))
+		adminApi := api.NewAdminApi(adminService, auth)
+		clock := service.NewSystemClock()
+		standingOrderService := service.NewStandingOrderService(accountService, storages.standingOrder, clock)
+		standingOrderApi := api.NewStandingOrderApi(standingOrderService, auth, idempotency)
+		holdService := service.NewHoldService(accountService, storages.holds, clock, &appConfig.Holds, limits)
+		holdApi := api.NewHoldApi(holdService, auth, idempotency)
+		batchService := service.NewBatchService(accountService, storages.batches)
+		batchApi := api.NewBatchApi(batchService, auth, idempotency)
+		router := batchApi.Register(holdApi.Register(standingOrderApi.Register(adminApi.Register(accountApi.Router()))))
+		scheduler := service.NewTransferScheduler(accountService, storages.scheduled, &appConfig.Scheduler)
+		go scheduler.Run(context.Background())
+		executor := service.NewStandingOrderExecutor(accountService, storages.standingOrder, clock, &appConfig.StandingOrders)
+		go executor.Run(context.Background())
+		expirer := service.NewHoldExpirer(storages.holds, clock, appConfig.Holds.Interval)
+		go expirer.Run(context.Background())
+		keyExpirer := service.NewIdempotencyKeyExpirer(storages.idempotency, &appConfig.Idempotency)
+		go keyExpirer.Run(context.Background())
+		go accruer.Run(context.Background())
+		relay := service.NewOutboxRelay(storages.outbox, publisher, &appConfig.Events)
+		go relay.Run(context.Background())
 
 		done := make(chan bool)
 		go func() {
-			log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", appConfig.Port), accountApi.Router()))
+			log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", appConfig.Port), router))
 		}()
 		log.Printf("Server started on port %v", appConfig.Port)
 		<-done
 	}
 }
+
+type storages struct {
+	account       storage.AccountStorage
+	ledger        storage.LedgerStorage
+	idempotency   storage.IdempotencyStorage
+	journal       storage.JournalStorage
+	scheduled     storage.ScheduledTransferStorage
+	standingOrder storage.StandingOrderStorage
+	holds         storage.HoldStorage
+	interest      storage.InterestStorage
+	batches       storage.BatchStorage
+	outbox        storage.OutboxStorage
+}
+
+// createStorages connects to Postgres, or keeps everything in memory for demos, which loses the data on restart
+func createStorages(appConfig *config.AppConfig) (*storages, error) {
+	switch appConfig.Storage.Type {
+	case "postgres":
+		if pgClient, err := postgres.CreateClient(appConfig); err != nil {
+			return nil, err
+		} else if err = postgres.SetUp(pgClient); err != nil {
+			return nil, err
+		} else {
+			return &storages{
+				account:       storage.NewPostgresAccountStorage(pgClient),
+				ledger:        storage.NewPostgresLedgerStorage(pgClient),
+				idempotency:   storage.NewPostgresIdempotencyStorage(pgClient),
+				journal:       storage.NewPostgresJournalStorage(pgClient),
+				scheduled:     storage.NewPostgresScheduledTransferStorage(pgClient),
+				standingOrder: storage.NewPostgresStandingOrderStorage(pgClient),
+				holds:         storage.NewPostgresHoldStorage(pgClient),
+				interest:      storage.NewPostgresInterestStorage(pgClient),
+				batches:       storage.NewPostgresBatchStorage(pgClient),
+				outbox:        storage.NewPostgresOutboxStorage(pgClient),
+			}, nil
+		}
+	case "memory":
+		ledger := storage.NewInMemoryLedgerStorage()
+		accounts := storage.NewInMemoryAccountStorage(ledger)
+		scheduled := storage.NewInMemoryScheduledTransferStorage()
+		standingOrders := storage.NewInMemoryStandingOrderStorage()
+		accounts.SetSchedules(scheduled, standingOrders)
+		return &storages{
+			account:       accounts,
+			ledger:        ledger,
+			idempotency:   storage.NewInMemoryIdempotencyStorage(),
+			journal:       accounts,
+			scheduled:     scheduled,
+			standingOrder: standingOrders,
+			holds:         accounts,
+			interest:      accounts,
+			batches:       storage.NewInMemoryBatchStorage(),
+			outbox:        accounts,
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown storage type '%s'", appConfig.Storage.Type)
+	}
+}
+
+func adminUsers(adminConfig *config.Admin) []model.UserId {
+	users := make([]model.UserId, len(adminConfig.Users))
+	for i, user := range adminConfig.Users {
+		users[i] = model.UserId(user)
+	}
+	return users
+}
+
+func createAuthenticationService(authConfig *config.Authentication) (service.AuthenticationService, error) {
+	switch authConfig.Type {
+	case "stub":
+		return service.NewStubAuthenticationService(), nil
+	case "jwt":
+		return service.NewJwtAuthenticationService(&authConfig.Jwt)
+	default:
+		return nil, fmt.Errorf("Unknown authentication type '%s'", authConfig.Type)
+	}
+}
+
+func createEventPublisher(eventsConfig *config.Events) (service.EventPublisher, error) {
+	switch eventsConfig.Publisher {
+	case "stdout":
+		return service.NewWriterEventPublisher(os.Stdout), nil
+	case "file":
+		return service.NewFileEventPublisher(eventsConfig.File)
+	default:
+		return nil, fmt.Errorf("Unknown event publisher '%s'", eventsConfig.Publisher)
+	}
+}
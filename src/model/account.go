@@ -2,10 +2,35 @@ package model
 
 import (
 	"github.com/shopspring/decimal"
+	"time"
 )
 
+// The balance is the money booked on the account, and the held money is reserved by its active holds.
+// The balance can go below zero down to the negative overdraft limit, which the admins set and is zero for new accounts.
+// The interest rate is yearly, for example 0.02 for 2%, and the accrued interest is what the account earned this month so far.
+// The rate applies from the day it was last changed on, and no earlier day is accrued after that.
 type Account struct {
-	Id      AccountId       `db:"id"`
-	Owner   UserId          `db:"owner_id"`
-	Balance decimal.Decimal `db:"balance"`
+	Id              AccountId       `db:"id"`
+	Owner           UserId          `db:"owner_id"`
+	Name            string          `db:"name"`
+	Type            AccountType     `db:"type"`
+	Currency        Currency        `db:"currency"`
+	Status          AccountStatus   `db:"status"`
+	Balance         decimal.Decimal `db:"balance"`
+	Held            decimal.Decimal `db:"held"`
+	OverdraftLimit  decimal.Decimal `db:"overdraft_limit"`
+	InterestRate    decimal.Decimal `db:"interest_rate"`
+	AccruedInterest decimal.Decimal `db:"accrued_interest"`
+	InterestSince   *time.Time      `db:"interest_rate_since"`
+	// TransferLimits overrides the default limits of the outgoing transfers
+	TransferLimits
+}
+
+// Available returns the money that can still be spent, including what is left of the overdraft
+func (account *Account) Available() decimal.Decimal {
+	return account.Balance.Sub(account.Held).Add(account.OverdraftLimit)
+}
+
+func (account *Account) IsOverdrawn() bool {
+	return account.Balance.IsNegative() && account.Type != SystemAccount
 }
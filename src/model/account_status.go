@@ -0,0 +1,9 @@
+package model
+
+type AccountStatus string
+
+const (
+	ActiveAccount AccountStatus = "active"
+	FrozenAccount AccountStatus = "frozen"
+	ClosedAccount AccountStatus = "closed"
+)
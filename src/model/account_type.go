@@ -0,0 +1,18 @@
+package model
+
+type AccountType string
+
+const (
+	CheckingAccount   AccountType = "checking"
+	SavingsAccount    AccountType = "savings"
+	SubAccountAccount AccountType = "sub_account"
+)
+
+func (accountType AccountType) IsValid() bool {
+	switch accountType {
+	case CheckingAccount, SavingsAccount, SubAccountAccount:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// BatchMode tells what happens to the other transfers of a batch when one of them fails
+type BatchMode string
+
+const (
+	// AllOrNothingBatch makes all the transfers in one transaction, or none of them
+	AllOrNothingBatch BatchMode = "all_or_nothing"
+	// BestEffortBatch makes every transfer on its own, and keeps going after a failed one
+	BestEffortBatch BatchMode = "best_effort"
+)
+
+func (mode BatchMode) IsValid() bool {
+	switch mode {
+	case AllOrNothingBatch, BestEffortBatch:
+		return true
+	default:
+		return false
+	}
+}
+
+type BatchStatus string
+
+const (
+	ProcessingBatch         BatchStatus = "processing"
+	CompletedBatch          BatchStatus = "completed"
+	PartiallyCompletedBatch BatchStatus = "partially_completed"
+	FailedBatch             BatchStatus = "failed"
+)
+
+type BatchLineStatus string
+
+const (
+	PendingBatchLine   BatchLineStatus = "pending"
+	CompletedBatchLine BatchLineStatus = "completed"
+	FailedBatchLine    BatchLineStatus = "failed"
+	// SkippedBatchLine is a valid transfer that was not made, because another one of its all or nothing batch failed
+	SkippedBatchLine BatchLineStatus = "skipped"
+)
+
+// A batch is a file of transfers from the accounts of its owner. It stays processing while its transfers are made,
+// and then it is completed, partially completed when some of its transfers failed, or failed when none was made.
+type Batch struct {
+	Id        BatchId     `db:"id"`
+	Owner     UserId      `db:"owner_id"`
+	Mode      BatchMode   `db:"mode"`
+	Status    BatchStatus `db:"status"`
+	Lines     []BatchLine `db:"-"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+// A line is one transfer of the batch, numbered in the order of the file. A line that could not be read
+// keeps what could be read of it, and the error of a failed line is kept with the name of its type.
+type BatchLine struct {
+	BatchId      BatchId         `db:"batch_id"`
+	Line         int             `db:"line"`
+	Reference    string          `db:"reference"`
+	From         AccountId       `db:"from_id"`
+	To           AccountId       `db:"to_id"`
+	Amount       decimal.Decimal `db:"amount"`
+	Status       BatchLineStatus `db:"status"`
+	TransferId   *TransferId     `db:"transfer_id"`
+	ErrorType    *string         `db:"error_type"`
+	ErrorMessage *string         `db:"error_message"`
+}
+
+// Finish sets the status of the batch from the ones of its lines
+func (batch *Batch) Finish() {
+	completed := 0
+	for _, line := range batch.Lines {
+		if line.Status == CompletedBatchLine {
+			completed++
+		}
+	}
+	if completed == len(batch.Lines) {
+		batch.Status = CompletedBatch
+	} else if completed > 0 {
+		batch.Status = PartiallyCompletedBatch
+	} else {
+		batch.Status = FailedBatch
+	}
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type Currency string
+
+const DefaultCurrency Currency = "EUR"
+
+// The number of digits after the decimal separator by ISO 4217
+var currencyMinorUnits = map[Currency]int32{
+	"AED": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "CZK": 2,
+	"DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3,
+	"JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3, "PLN": 2, "RON": 2, "SAR": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TND": 3, "TRY": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+func (currency Currency) IsValid() bool {
+	_, found := currencyMinorUnits[currency]
+	return found
+}
+
+func (currency Currency) MinorUnits() int32 {
+	return currencyMinorUnits[currency]
+}
+
+func (currency Currency) Round(amount decimal.Decimal) decimal.Decimal {
+	return amount.Round(currency.MinorUnits())
+}
+
+func (currency Currency) HasValidPrecision(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(currency.MinorUnits()))
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type EventType string
+
+const (
+	AccountCreatedEvent   EventType = "AccountCreated"
+	AccountToppedUpEvent  EventType = "AccountToppedUp"
+	MoneyTransferredEvent EventType = "MoneyTransferred"
+)
+
+// An event is written to the outbox together with the change it tells about, and it is published after that change
+// has been committed. The events of an account are published in the order they were written, and the version
+// of an event goes up when its data changes in a way that its consumers have to know about.
+type Event struct {
+	Id          EventId         `db:"id"`
+	AccountId   AccountId       `db:"account_id"`
+	Type        EventType       `db:"type"`
+	Version     int             `db:"version"`
+	Data        json.RawMessage `db:"data"`
+	CreatedAt   time.Time       `db:"created_at"`
+	PublishedAt *time.Time      `db:"published_at"`
+}
+
+type EventData interface {
+	EventType() EventType
+	EventVersion() int
+}
+
+// NewEvent is the event of the account, which is the source account of a transfer
+func NewEvent(accountId AccountId, data EventData) (*Event, error) {
+	if encoded, err := json.Marshal(data); err != nil {
+		return nil, err
+	} else {
+		return &Event{AccountId: accountId, Type: data.EventType(), Version: data.EventVersion(), Data: encoded}, nil
+	}
+}
+
+type AccountCreated struct {
+	AccountId AccountId   `json:"account_id"`
+	Owner     UserId      `json:"owner"`
+	Type      AccountType `json:"type"`
+	Currency  Currency    `json:"currency"`
+	Name      string      `json:"name,omitempty"`
+}
+
+func NewAccountCreated(account *Account) *AccountCreated {
+	return &AccountCreated{AccountId: account.Id, Owner: account.Owner, Type: account.Type, Currency: account.Currency, Name: account.Name}
+}
+
+func (data *AccountCreated) EventType() EventType {
+	return AccountCreatedEvent
+}
+
+func (data *AccountCreated) EventVersion() int {
+	return 1
+}
+
+// AccountToppedUp has the balance of the account after the top-up and its fee
+type AccountToppedUp struct {
+	AccountId     AccountId       `json:"account_id"`
+	LedgerEntryId LedgerEntryId   `json:"ledger_entry_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Fee           decimal.Decimal `json:"fee"`
+	Currency      Currency        `json:"currency"`
+	Balance       decimal.Decimal `json:"balance"`
+}
+
+func NewAccountToppedUp(entry *LedgerEntry, currency Currency) *AccountToppedUp {
+	return &AccountToppedUp{
+		AccountId:     entry.AccountId,
+		LedgerEntryId: entry.Id,
+		Amount:        entry.Amount,
+		Fee:           entry.Fee,
+		Currency:      currency,
+		Balance:       entry.Balance,
+	}
+}
+
+func (data *AccountToppedUp) EventType() EventType {
+	return AccountToppedUpEvent
+}
+
+func (data *AccountToppedUp) EventVersion() int {
+	return 1
+}
+
+// MoneyTransferred is the amount in the currency of the source account and the credit amount in the one of the target account.
+// The fee is paid by the source account on top of the amount.
+type MoneyTransferred struct {
+	TransferId     TransferId       `json:"transfer_id"`
+	From           AccountId        `json:"from"`
+	To             AccountId        `json:"to"`
+	Amount         decimal.Decimal  `json:"amount"`
+	Currency       Currency         `json:"currency"`
+	CreditAmount   decimal.Decimal  `json:"credit_amount"`
+	CreditCurrency Currency         `json:"credit_currency"`
+	FxRate         *decimal.Decimal `json:"fx_rate,omitempty"`
+	Fee            decimal.Decimal  `json:"fee"`
+	ReversalOf     *TransferId      `json:"reversal_of,omitempty"`
+}
+
+func NewMoneyTransferred(transfer *Transfer, currency, creditCurrency Currency) *MoneyTransferred {
+	data := &MoneyTransferred{
+		TransferId:     transfer.Id,
+		From:           transfer.From,
+		To:             transfer.To,
+		Amount:         transfer.Amount,
+		Currency:       currency,
+		CreditAmount:   transfer.CreditAmount,
+		CreditCurrency: creditCurrency,
+		Fee:            transfer.Fee,
+		ReversalOf:     transfer.ReversalOf,
+	}
+	if transfer.FxRate.Valid {
+		data.FxRate = &transfer.FxRate.Decimal
+	}
+	return data
+}
+
+func (data *MoneyTransferred) EventType() EventType {
+	return MoneyTransferredEvent
+}
+
+func (data *MoneyTransferred) EventVersion() int {
+	return 1
+}
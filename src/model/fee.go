@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type FeeOperation string
+
+const (
+	TopUpOperation    FeeOperation = "top_up"
+	TransferOperation FeeOperation = "transfer"
+)
+
+func (operation FeeOperation) IsValid() bool {
+	switch operation {
+	case TopUpOperation, TransferOperation:
+		return true
+	default:
+		return false
+	}
+}
+
+// FeeTier applies to the amounts up to its bound, and the last tier can leave the bound out to cover all larger amounts.
+// The amounts above the bound of the last tier are charged by the flat part and the rate of the rule itself.
+type FeeTier struct {
+	UpTo decimal.NullDecimal
+	Flat decimal.Decimal
+	Rate decimal.Decimal
+}
+
+// A FeeRule charges a flat part plus a rate of the amount, for example 0.01 for 1%, taken from the tier of the amount
+// when the rule has tiers. The fee is then capped by the minimum and the maximum.
+// A rule without account type applies to every account type.
+type FeeRule struct {
+	Operation   FeeOperation
+	AccountType AccountType
+	Flat        decimal.Decimal
+	Rate        decimal.Decimal
+	Tiers       []FeeTier
+	Min         decimal.NullDecimal
+	Max         decimal.NullDecimal
+}
+
+func (rule *FeeRule) Matches(operation FeeOperation, accountType AccountType) bool {
+	return rule.Operation == operation && (rule.AccountType == "" || rule.AccountType == accountType)
+}
+
+// Fee is in the currency of the account, and it is rounded to the minor units of that currency
+func (rule *FeeRule) Fee(amount decimal.Decimal, currency Currency) decimal.Decimal {
+	flat, rate := rule.Flat, rule.Rate
+	for _, tier := range rule.Tiers {
+		if !tier.UpTo.Valid || amount.LessThanOrEqual(tier.UpTo.Decimal) {
+			flat, rate = tier.Flat, tier.Rate
+			break
+		}
+	}
+	fee := flat.Add(amount.Mul(rate))
+	if rule.Min.Valid && fee.LessThan(rule.Min.Decimal) {
+		fee = rule.Min.Decimal
+	}
+	if rule.Max.Valid && fee.GreaterThan(rule.Max.Decimal) {
+		fee = rule.Max.Decimal
+	}
+	return currency.Round(fee)
+}
+
+// FeeSchedule is ordered, and the first rule that matches the operation and the account type sets the fee
+type FeeSchedule []FeeRule
+
+func (schedule FeeSchedule) Fee(operation FeeOperation, account *Account, amount decimal.Decimal) decimal.Decimal {
+	for i := range schedule {
+		if schedule[i].Matches(operation, account.Type) {
+			return schedule[i].Fee(amount, account.Currency)
+		}
+	}
+	return decimal.Zero
+}
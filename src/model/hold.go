@@ -0,0 +1,36 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type HoldStatus string
+
+const (
+	ActiveHold   HoldStatus = "active"
+	CapturedHold HoldStatus = "captured"
+	ReleasedHold HoldStatus = "released"
+	ExpiredHold  HoldStatus = "expired"
+)
+
+// A hold reserves money on the source account for a later transfer to the target account.
+// While it is active, the amount and the fee of the transfer count towards the held money of the account
+// and cannot be spent otherwise. A captured hold refers to the transfer it made, which can be smaller than the held amount.
+type Hold struct {
+	Id         HoldId          `db:"id"`
+	From       AccountId       `db:"from_id"`
+	To         AccountId       `db:"to_id"`
+	Amount     decimal.Decimal `db:"amount"`
+	Fee        decimal.Decimal `db:"fee"`
+	Convert    bool            `db:"convert"`
+	Status     HoldStatus      `db:"status"`
+	ExpiresAt  time.Time       `db:"expires_at"`
+	TransferId *TransferId     `db:"transfer_id"`
+	CreatedAt  time.Time       `db:"created_at"`
+}
+
+// Held is the money that the hold reserves on the source account
+func (hold *Hold) Held() decimal.Decimal {
+	return hold.Amount.Add(hold.Fee)
+}
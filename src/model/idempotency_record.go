@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// The status code and the response are empty while the first request is still in progress
+type IdempotencyRecord struct {
+	UserId      UserId    `db:"user_id"`
+	Key         string    `db:"idempotency_key"`
+	RequestHash string    `db:"request_hash"`
+	StatusCode  *int      `db:"status_code"`
+	Response    []byte    `db:"response"`
+	CreatedAt   time.Time `db:"created_at"`
+}
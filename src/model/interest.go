@@ -0,0 +1,93 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// DayCount is the convention that tells which fraction of the yearly interest rate accrues on a day
+type DayCount string
+
+const (
+	Actual365 DayCount = "ACT/365"
+	Actual360 DayCount = "ACT/360"
+	Thirty360 DayCount = "30/360"
+)
+
+// The daily interest is kept with this many decimal places until it is capitalized
+const interestPrecision = 20
+
+func (dayCount DayCount) IsValid() bool {
+	switch dayCount {
+	case Actual365, Actual360, Thirty360:
+		return true
+	default:
+		return false
+	}
+}
+
+// DayFraction returns the part of the year from the day to the next one, as the days counted and the days of the year.
+// ACT/365 counts every year as 365 days, leap years included. 30/360 counts every month as 30 days,
+// so one day of a 31 day month accrues nothing, and the last day of February accrues the days it lacks as well.
+func (dayCount DayCount) DayFraction(day time.Time) (int64, int64) {
+	switch dayCount {
+	case Actual360:
+		return 1, 360
+	case Thirty360:
+		return thirty360Days(day, day.AddDate(0, 0, 1)), 360
+	default:
+		return 1, 365
+	}
+}
+
+// thirty360Days counts the days between the dates by the 30/360 ISDA rules
+func thirty360Days(from, to time.Time) int64 {
+	fromDay, toDay := from.Day(), to.Day()
+	if fromDay == 31 {
+		fromDay = 30
+	}
+	if toDay == 31 && fromDay == 30 {
+		toDay = 30
+	}
+	return int64(360*(to.Year()-from.Year()) + 30*(int(to.Month())-int(from.Month())) + toDay - fromDay)
+}
+
+func isLastDayOfMonth(day time.Time) bool {
+	return day.AddDate(0, 0, 1).Day() == 1
+}
+
+// InterestAccrual is the interest of one account for one day. The amount is not rounded, and it is added to the interest
+// accrued on the account during the month. On the last day of the month, the accrued interest is capitalized in whole
+// minor units of the currency by the journal, and the remainder is left accrued for the next month.
+type InterestAccrual struct {
+	AccountId   AccountId       `db:"account_id"`
+	Date        time.Time       `db:"date"`
+	Balance     decimal.Decimal `db:"balance"`
+	Rate        decimal.Decimal `db:"rate"`
+	Amount      decimal.Decimal `db:"amount"`
+	Accrued     decimal.Decimal `db:"accrued"`
+	Capitalized decimal.Decimal `db:"capitalized"`
+	JournalId   *JournalId      `db:"journal_id"`
+}
+
+// NewInterestAccrual accrues the day on the balance the account had at the end of it, and only a positive balance earns interest
+func NewInterestAccrual(account *Account, balance decimal.Decimal, day time.Time, dayCount DayCount) *InterestAccrual {
+	accrual := &InterestAccrual{
+		AccountId:   account.Id,
+		Date:        day,
+		Balance:     balance,
+		Rate:        account.InterestRate,
+		Amount:      decimal.Zero,
+		Capitalized: decimal.Zero,
+	}
+	if balance.IsPositive() {
+		days, yearDays := dayCount.DayFraction(day)
+		accrual.Amount = balance.Mul(account.InterestRate).Mul(decimal.NewFromInt(days)).DivRound(decimal.NewFromInt(yearDays), interestPrecision)
+	}
+	accrual.Accrued = account.AccruedInterest.Add(accrual.Amount)
+	if isLastDayOfMonth(day) {
+		accrual.Capitalized = accrual.Accrued.Truncate(account.Currency.MinorUnits())
+		accrual.Accrued = accrual.Accrued.Sub(accrual.Capitalized)
+	}
+	return accrual
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type JournalType string
+
+const (
+	OpeningJournal  JournalType = "opening"
+	TopUpJournal    JournalType = "top_up"
+	TransferJournal JournalType = "transfer"
+	ReversalJournal JournalType = "reversal"
+	InterestJournal JournalType = "interest"
+)
+
+// A positive amount credits the account and a negative amount debits it,
+// so that it is added to the balance of the account as is
+type Posting struct {
+	JournalId JournalId       `db:"journal_id"`
+	AccountId AccountId       `db:"account_id"`
+	Currency  Currency        `db:"currency"`
+	Amount    decimal.Decimal `db:"amount"`
+}
+
+type Journal struct {
+	Id        JournalId   `db:"id"`
+	Type      JournalType `db:"type"`
+	Postings  []Posting   `db:"-"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+// Debited tells which accounts the journal takes money from in total. Only those have to stay within their overdraft
+// limit, so that a top-up can repay an account overdrawn past a lowered limit, even with a fee charged on it.
+func (journal *Journal) Debited() map[AccountId]bool {
+	sums := make(map[AccountId]decimal.Decimal)
+	for _, posting := range journal.Postings {
+		sums[posting.AccountId] = sums[posting.AccountId].Add(posting.Amount)
+	}
+	debited := make(map[AccountId]bool)
+	for accountId, sum := range sums {
+		debited[accountId] = sum.IsNegative()
+	}
+	return debited
+}
+
+// IsBalanced tells whether the postings of every currency sum up to zero
+func (journal *Journal) IsBalanced() bool {
+	if len(journal.Postings) < 2 {
+		return false
+	}
+	sums := make(map[Currency]decimal.Decimal)
+	for _, posting := range journal.Postings {
+		sums[posting.Currency] = sums[posting.Currency].Add(posting.Amount)
+	}
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type LedgerEntryType string
+
+const (
+	TopUpEntry       LedgerEntryType = "top_up"
+	TransferInEntry  LedgerEntryType = "transfer_in"
+	TransferOutEntry LedgerEntryType = "transfer_out"
+	InterestEntry    LedgerEntryType = "interest"
+	// OpeningEntry brings forward a balance that the account had before the ledger was kept
+	OpeningEntry LedgerEntryType = "opening"
+)
+
+func (entryType LedgerEntryType) IsValid() bool {
+	switch entryType {
+	case TopUpEntry, TransferInEntry, TransferOutEntry, InterestEntry, OpeningEntry:
+		return true
+	default:
+		return false
+	}
+}
+
+// The amount is signed, and the fee charged with it is positive.
+// The balance is the account balance right after the entry and its fee are applied.
+type LedgerEntry struct {
+	Id           LedgerEntryId       `db:"id"`
+	AccountId    AccountId           `db:"account_id"`
+	JournalId    *JournalId          `db:"journal_id"`
+	Type         LedgerEntryType     `db:"type"`
+	Amount       decimal.Decimal     `db:"amount"`
+	Balance      decimal.Decimal     `db:"balance"`
+	Counterparty *AccountId          `db:"counterparty_id"`
+	FxRate       decimal.NullDecimal `db:"fx_rate"`
+	Fee          decimal.Decimal     `db:"fee"`
+	CreatedAt    time.Time           `db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+type LedgerFilter struct {
+	Before *LedgerEntryId
+	From   *time.Time
+	To     *time.Time
+	Types  []LedgerEntryType
+	Limit  int
+}
@@ -0,0 +1,6 @@
+package model
+
+type LedgerPage struct {
+	Entries []LedgerEntry
+	Next    *LedgerEntryId
+}
@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+type Frequency string
+
+const (
+	DailyFrequency   Frequency = "daily"
+	WeeklyFrequency  Frequency = "weekly"
+	MonthlyFrequency Frequency = "monthly"
+)
+
+func (frequency Frequency) IsValid() bool {
+	switch frequency {
+	case DailyFrequency, WeeklyFrequency, MonthlyFrequency:
+		return true
+	default:
+		return false
+	}
+}
+
+// Recurrence is the calendar rule of a standing order. The day is the ISO weekday from 1 (Monday) to 7 (Sunday)
+// for weekly orders, the day of the month from 1 to 31 for monthly orders, and it is not used for daily orders.
+type Recurrence struct {
+	Frequency Frequency `db:"frequency"`
+	Day       int       `db:"day"`
+}
+
+// RunsOn tells whether the rule falls on the day.
+// A monthly rule on a day that the month does not have falls on the last day of that month.
+func (recurrence Recurrence) RunsOn(day time.Time) bool {
+	switch recurrence.Frequency {
+	case WeeklyFrequency:
+		weekday := int(day.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		return weekday == recurrence.Day
+	case MonthlyFrequency:
+		lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if recurrence.Day > lastDay {
+			return day.Day() == lastDay
+		}
+		return day.Day() == recurrence.Day
+	default:
+		return true
+	}
+}
+
+// First returns the first day on or after the given day on which the rule falls
+func (recurrence Recurrence) First(day time.Time) time.Time {
+	return recurrence.Next(Today(day).AddDate(0, 0, -1))
+}
+
+// Next returns the first day after the given day on which the rule falls
+func (recurrence Recurrence) Next(day time.Time) time.Time {
+	next := Today(day).AddDate(0, 0, 1)
+	for !recurrence.RunsOn(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Today returns the start of the day of the given time in UTC, which is when the standing orders of that day are due
+func Today(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type ScheduledTransferStatus string
+
+const (
+	PendingTransfer   ScheduledTransferStatus = "pending"
+	ExecutedTransfer  ScheduledTransferStatus = "executed"
+	FailedTransfer    ScheduledTransferStatus = "failed"
+	CancelledTransfer ScheduledTransferStatus = "cancelled"
+)
+
+// A scheduled transfer is executed like a transfer request of its owner once it is due.
+// An executed one refers to the transfer it made, and a failed one keeps the type and the message of the error.
+// Attempts counts the failed executions, and after a server error the transfer is due again later.
+type ScheduledTransfer struct {
+	Id           ScheduledTransferId     `db:"id"`
+	Owner        UserId                  `db:"owner_id"`
+	From         AccountId               `db:"from_id"`
+	To           AccountId               `db:"to_id"`
+	Amount       decimal.Decimal         `db:"amount"`
+	Convert      bool                    `db:"convert"`
+	ExecuteAt    time.Time               `db:"execute_at"`
+	Status       ScheduledTransferStatus `db:"status"`
+	TransferId   *TransferId             `db:"transfer_id"`
+	ErrorType    *string                 `db:"error_type"`
+	ErrorMessage *string                 `db:"error_message"`
+	Attempts     int                     `db:"attempts"`
+	CreatedAt    time.Time               `db:"created_at"`
+}
+
+// Origin is the origin of the transfer that executes the scheduled transfer
+func (transfer *ScheduledTransfer) Origin() string {
+	return fmt.Sprintf("scheduled_transfer:%d", transfer.Id)
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type StandingOrderStatus string
+
+const (
+	ActiveStandingOrder    StandingOrderStatus = "active"
+	SuspendedStandingOrder StandingOrderStatus = "suspended"
+	FinishedStandingOrder  StandingOrderStatus = "finished"
+	CancelledStandingOrder StandingOrderStatus = "cancelled"
+)
+
+// A standing order makes a transfer request of its owner on every day of its recurrence until the end date.
+// NextRunOn is the day of the next transfer, and DueAt is when it is tried, which is later when a failed run is retried.
+// Failures counts the failed runs in a row, and the error of the last one is kept until the order runs again.
+type StandingOrder struct {
+	Recurrence
+	Id             StandingOrderId     `db:"id"`
+	Owner          UserId              `db:"owner_id"`
+	From           AccountId           `db:"from_id"`
+	To             AccountId           `db:"to_id"`
+	Amount         decimal.Decimal     `db:"amount"`
+	Convert        bool                `db:"convert"`
+	EndDate        *time.Time          `db:"end_date"`
+	NextRunOn      time.Time           `db:"next_run_on"`
+	DueAt          time.Time           `db:"due_at"`
+	Status         StandingOrderStatus `db:"status"`
+	Failures       int                 `db:"failures"`
+	LastTransferId *TransferId         `db:"last_transfer_id"`
+	ErrorType      *string             `db:"error_type"`
+	ErrorMessage   *string             `db:"error_message"`
+	CreatedAt      time.Time           `db:"created_at"`
+}
+
+// Origin is the origin of the transfer of the next run, which stays the same when the run is retried
+func (order *StandingOrder) Origin() string {
+	return fmt.Sprintf("standing_order:%d:%s", order.Id, order.NextRunOn.Format("2006-01-02"))
+}
+
+// IsEnded tells whether the order can neither run nor be changed any more
+func (order *StandingOrder) IsEnded() bool {
+	return order.Status == FinishedStandingOrder || order.Status == CancelledStandingOrder
+}
+
+// Reschedule sets the next run to the first day of the recurrence on or after the given day,
+// and finishes the order when that is after its end date
+func (order *StandingOrder) Reschedule(day time.Time) {
+	order.NextRunOn = order.Recurrence.First(day)
+	order.DueAt = order.NextRunOn
+	if order.EndDate != nil && order.NextRunOn.After(*order.EndDate) {
+		order.Status = FinishedStandingOrder
+	}
+}
@@ -0,0 +1,3 @@
+package model
+
+type StandingOrderId int64
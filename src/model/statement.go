@@ -0,0 +1,45 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type StatementFormat string
+
+const (
+	CsvStatement  StatementFormat = "csv"
+	JsonStatement StatementFormat = "json"
+	TextStatement StatementFormat = "txt"
+	// Camt053Statement is the ISO 20022 bank to customer statement camt.053.001.08, which ERP systems import
+	Camt053Statement StatementFormat = "camt053"
+)
+
+func (format StatementFormat) IsValid() bool {
+	switch format {
+	case CsvStatement, JsonStatement, TextStatement, Camt053Statement:
+		return true
+	default:
+		return false
+	}
+}
+
+// Statement covers the entries created from the start of the period until before its end.
+// The opening balance is the balance of the account right before the period starts, and the closing balance
+// is the one right before it ends. Both are known before the first entry, since some formats lead with them.
+type Statement struct {
+	Account        *Account
+	From           time.Time
+	To             time.Time
+	CreatedAt      time.Time
+	OpeningBalance decimal.Decimal
+	ClosingBalance decimal.Decimal
+}
+
+// StatementWriter writes a statement while it is read, so that a long period is never held in memory.
+// The entries come oldest first.
+type StatementWriter interface {
+	Begin(statement *Statement) error
+	Entry(entry *LedgerEntry) error
+	End() error
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"fmt"
+)
+
+// System accounts belong to the bank itself, one of each kind per currency
+type SystemAccountKind string
+
+const (
+	CashInClearingAccount SystemAccountKind = "cash_in_clearing"
+	FeesAccount           SystemAccountKind = "fees"
+	FxAccount             SystemAccountKind = "fx"
+	InterestAccount       SystemAccountKind = "interest"
+)
+
+const (
+	SystemUser    UserId      = 0
+	SystemAccount AccountType = "system"
+)
+
+func SystemAccountName(kind SystemAccountKind, currency Currency) string {
+	return fmt.Sprintf("%s %s", kind, currency)
+}
+
+func NewSystemAccount(kind SystemAccountKind, currency Currency) *Account {
+	return &Account{Owner: SystemUser, Name: SystemAccountName(kind, currency), Type: SystemAccount, Currency: currency}
+}
@@ -0,0 +1,8 @@
+package model
+
+type TransactionStatus string
+
+const (
+	CompletedTransaction TransactionStatus = "completed"
+	ReversedTransaction  TransactionStatus = "reversed"
+)
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// The amount is debited from the source account in its currency, and the credit amount is credited to the target account
+// in its currency. The exchange rate is only set for transfers between different currencies.
+// A reversal moves the money of the transfer it compensates back, fully or partly.
+// The fee is charged to the source account on top of the amount, in its currency.
+// The balance is the one of the source account right after the transfer and its fee.
+// The origin names the scheduled run that made the transfer, and no two transfers have the same one.
+type Transfer struct {
+	Id           TransferId          `db:"id"`
+	JournalId    JournalId           `db:"journal_id"`
+	From         AccountId           `db:"from_id"`
+	To           AccountId           `db:"to_id"`
+	Amount       decimal.Decimal     `db:"amount"`
+	CreditAmount decimal.Decimal     `db:"credit_amount"`
+	FxRate       decimal.NullDecimal `db:"fx_rate"`
+	Fee          decimal.Decimal     `db:"fee"`
+	Balance      decimal.Decimal     `db:"balance"`
+	ReversalOf   *TransferId         `db:"reversal_of"`
+	ReversedBy   *TransferId         `db:"reversed_by"`
+	Origin       *string             `db:"origin"`
+	CreatedAt    time.Time           `db:"created_at"`
+}
+
+func NewTransfer(from, to AccountId, amount decimal.Decimal) *Transfer {
+	return &Transfer{From: from, To: to, Amount: amount, CreditAmount: amount, Fee: decimal.Zero}
+}
+
+func (transfer *Transfer) JournalType() JournalType {
+	if transfer.ReversalOf != nil {
+		return ReversalJournal
+	} else {
+		return TransferJournal
+	}
+}
+
+func (transfer *Transfer) Status() TransactionStatus {
+	if transfer.ReversedBy != nil {
+		return ReversedTransaction
+	} else {
+		return CompletedTransaction
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type LimitPeriod string
+
+const (
+	PerTransactionPeriod LimitPeriod = "per_transaction"
+	DailyPeriod          LimitPeriod = "daily"
+	MonthlyPeriod        LimitPeriod = "monthly"
+)
+
+// The daily and monthly limits are rolling windows that end at the time of the transfer
+const (
+	DailyWindow   = 24 * time.Hour
+	MonthlyWindow = 30 * 24 * time.Hour
+)
+
+type LimitScope string
+
+const (
+	AccountLimitScope LimitScope = "account"
+	UserLimitScope    LimitScope = "user"
+)
+
+// TransferLimits caps the money sent in one transfer, in the daily window and in the monthly window,
+// in the currency of the source account. A missing limit does not apply.
+type TransferLimits struct {
+	PerTransactionLimit decimal.NullDecimal `db:"per_transaction_limit"`
+	DailyLimit          decimal.NullDecimal `db:"daily_limit"`
+	MonthlyLimit        decimal.NullDecimal `db:"monthly_limit"`
+}
+
+// Override replaces the limits with the ones that are set in the overrides
+func (limits TransferLimits) Override(overrides TransferLimits) TransferLimits {
+	if overrides.PerTransactionLimit.Valid {
+		limits.PerTransactionLimit = overrides.PerTransactionLimit
+	}
+	if overrides.DailyLimit.Valid {
+		limits.DailyLimit = overrides.DailyLimit
+	}
+	if overrides.MonthlyLimit.Valid {
+		limits.MonthlyLimit = overrides.MonthlyLimit
+	}
+	return limits
+}
+
+func (limits TransferLimits) IsEmpty() bool {
+	return !limits.PerTransactionLimit.Valid && !limits.DailyLimit.Valid && !limits.MonthlyLimit.Valid
+}
+
+// Limits are the default limits of every currency, and a currency without them has no default limits
+type Limits map[Currency]CurrencyLimits
+
+// CurrencyLimits are the defaults for every account in the currency, which an account can override,
+// and the limits of all the accounts of a user in the currency together
+type CurrencyLimits struct {
+	Account TransferLimits
+	User    TransferLimits
+}
+
+func (limits Limits) Of(currency Currency) CurrencyLimits {
+	return limits[currency]
+}
+
+// TransferUsage is what was sent in the windows before a transfer
+type TransferUsage struct {
+	Daily   decimal.Decimal `db:"daily"`
+	Monthly decimal.Decimal `db:"monthly"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Debits and credits are the sums of all the negative and positive postings in the currency
+type CurrencyTotal struct {
+	Currency Currency        `db:"currency"`
+	Debits   decimal.Decimal `db:"debits"`
+	Credits  decimal.Decimal `db:"credits"`
+}
+
+// BalanceMismatch is an account whose cached balance differs from the sum of its postings
+type BalanceMismatch struct {
+	AccountId      AccountId       `db:"account_id"`
+	Balance        decimal.Decimal `db:"balance"`
+	JournalBalance decimal.Decimal `db:"journal_balance"`
+}
+
+type TrialBalance struct {
+	Totals     []CurrencyTotal
+	Mismatches []BalanceMismatch
+}
+
+func (trialBalance *TrialBalance) IsBalanced() bool {
+	for _, total := range trialBalance.Totals {
+		if !total.Debits.Equal(total.Credits) {
+			return false
+		}
+	}
+	return len(trialBalance.Mismatches) == 0
+}
@@ -17,6 +17,336 @@ var migrations = &migrate.MemoryMigrationSource{
 				")"},
 			Down: []string{"DROP TABLE accounts"},
 		},
+		{
+			Id: "2",
+			Up: []string{
+				"CREATE TABLE ledger_entries (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"account_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"type VARCHAR(32) NOT NULL," +
+					"amount DECIMAL NOT NULL," +
+					"balance DECIMAL NOT NULL," +
+					"counterparty_id BIGINT REFERENCES accounts(id)," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE INDEX ledger_entries_account_id_idx ON ledger_entries (account_id, id)",
+				// The existing balances open the history of their accounts, so that it explains all of their money
+				"INSERT INTO ledger_entries (account_id, type, amount, balance) SELECT id, 'opening', balance, balance FROM accounts WHERE balance <> 0",
+				"CREATE FUNCTION ledger_entries_immutable() RETURNS trigger AS $$ " +
+					"BEGIN RAISE EXCEPTION 'ledger entries are immutable'; END; " +
+					"$$ LANGUAGE plpgsql",
+				"CREATE TRIGGER ledger_entries_immutable BEFORE UPDATE OR DELETE ON ledger_entries " +
+					"FOR EACH ROW EXECUTE FUNCTION ledger_entries_immutable()",
+			},
+			Down: []string{
+				"DROP TABLE ledger_entries",
+				"DROP FUNCTION ledger_entries_immutable",
+			},
+		},
+		{
+			Id: "3",
+			Up: []string{"CREATE TABLE idempotency_keys (" +
+				"user_id BIGINT NOT NULL," +
+				"idempotency_key VARCHAR(255) NOT NULL," +
+				"request_hash VARCHAR(64) NOT NULL," +
+				"status_code INT," +
+				"response BYTEA," +
+				"created_at TIMESTAMPTZ NOT NULL DEFAULT now()," +
+				"PRIMARY KEY(user_id, idempotency_key)" +
+				")"},
+			Down: []string{"DROP TABLE idempotency_keys"},
+		},
+		{
+			Id: "4",
+			Up: []string{
+				"ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_owner_id_key",
+				"ALTER TABLE accounts ADD COLUMN name VARCHAR(100) NOT NULL DEFAULT ''",
+				"ALTER TABLE accounts ADD COLUMN type VARCHAR(32) NOT NULL DEFAULT 'checking'",
+				"CREATE INDEX accounts_owner_id_idx ON accounts (owner_id)",
+				"CREATE UNIQUE INDEX accounts_owner_id_name_idx ON accounts (owner_id, name) WHERE name <> ''",
+			},
+			// The unique owner constraint is not restored, because users may already have several accounts
+			Down: []string{
+				"DROP INDEX accounts_owner_id_name_idx",
+				"DROP INDEX accounts_owner_id_idx",
+				"ALTER TABLE accounts DROP COLUMN type",
+				"ALTER TABLE accounts DROP COLUMN name",
+			},
+		},
+		{
+			Id:   "5",
+			Up:   []string{"ALTER TABLE accounts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR'"},
+			Down: []string{"ALTER TABLE accounts DROP COLUMN currency"},
+		},
+		{
+			Id:   "6",
+			Up:   []string{"ALTER TABLE ledger_entries ADD COLUMN fx_rate DECIMAL"},
+			Down: []string{"ALTER TABLE ledger_entries DROP COLUMN fx_rate"},
+		},
+		{
+			Id:   "7",
+			Up:   []string{"ALTER TABLE accounts ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'"},
+			Down: []string{"ALTER TABLE accounts DROP COLUMN status"},
+		},
+		{
+			Id: "8",
+			Up: []string{
+				"CREATE TABLE journals (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"type VARCHAR(32) NOT NULL," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE TABLE postings (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"journal_id BIGINT NOT NULL REFERENCES journals(id)," +
+					"account_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"currency CHAR(3) NOT NULL," +
+					"amount DECIMAL NOT NULL" +
+					")",
+				"CREATE INDEX postings_journal_id_idx ON postings (journal_id)",
+				"CREATE INDEX postings_account_id_idx ON postings (account_id)",
+				"CREATE FUNCTION journals_immutable() RETURNS trigger AS $$ " +
+					"BEGIN RAISE EXCEPTION 'journals and postings are immutable'; END; " +
+					"$$ LANGUAGE plpgsql",
+				"CREATE TRIGGER journals_immutable BEFORE UPDATE OR DELETE ON journals " +
+					"FOR EACH ROW EXECUTE FUNCTION journals_immutable()",
+				"CREATE TRIGGER postings_immutable BEFORE UPDATE OR DELETE ON postings " +
+					"FOR EACH ROW EXECUTE FUNCTION journals_immutable()",
+				"ALTER TABLE ledger_entries ADD COLUMN journal_id BIGINT REFERENCES journals(id)",
+				// The existing balances are brought forward in one opening journal, as if they were topped up
+				"INSERT INTO accounts (owner_id, name, type, currency) " +
+					"SELECT DISTINCT 0, 'cash_in_clearing ' || currency, 'system', currency FROM accounts WHERE balance <> 0",
+				"INSERT INTO journals (type) SELECT 'opening' WHERE EXISTS (SELECT 1 FROM accounts WHERE balance <> 0)",
+				"UPDATE accounts s SET balance = -t.total " +
+					"FROM (SELECT currency, SUM(balance) AS total FROM accounts WHERE type <> 'system' GROUP BY currency) t " +
+					"WHERE s.type = 'system' AND s.currency = t.currency",
+				"INSERT INTO postings (journal_id, account_id, currency, amount) " +
+					"SELECT (SELECT MAX(id) FROM journals), id, currency, balance FROM accounts WHERE balance <> 0",
+			},
+			Down: []string{
+				"ALTER TABLE ledger_entries DROP COLUMN journal_id",
+				"DROP TABLE postings",
+				"DROP TABLE journals",
+				"DROP FUNCTION journals_immutable",
+				"DELETE FROM accounts WHERE type = 'system'",
+			},
+		},
+		{
+			Id: "9",
+			Up: []string{
+				"CREATE TABLE transfers (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"journal_id BIGINT NOT NULL REFERENCES journals(id)," +
+					"from_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"to_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"amount DECIMAL NOT NULL," +
+					"credit_amount DECIMAL NOT NULL," +
+					"fx_rate DECIMAL," +
+					// A transfer can only be reversed once
+					"reversal_of BIGINT UNIQUE REFERENCES transfers(id)," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+			},
+			Down: []string{"DROP TABLE transfers"},
+		},
+		{
+			Id: "10",
+			Up: []string{
+				"CREATE TABLE scheduled_transfers (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"owner_id BIGINT NOT NULL," +
+					"from_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"to_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"amount DECIMAL NOT NULL," +
+					"convert BOOLEAN NOT NULL DEFAULT false," +
+					"execute_at TIMESTAMPTZ NOT NULL," +
+					"status VARCHAR(16) NOT NULL DEFAULT 'pending'," +
+					"transfer_id BIGINT REFERENCES transfers(id)," +
+					"error_type VARCHAR(64)," +
+					"error_message TEXT," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE INDEX scheduled_transfers_owner_id_idx ON scheduled_transfers (owner_id)",
+				"CREATE INDEX scheduled_transfers_due_idx ON scheduled_transfers (execute_at) WHERE status = 'pending'",
+			},
+			Down: []string{"DROP TABLE scheduled_transfers"},
+		},
+		{
+			Id: "11",
+			Up: []string{
+				"CREATE TABLE standing_orders (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"owner_id BIGINT NOT NULL," +
+					"from_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"to_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"amount DECIMAL NOT NULL," +
+					"convert BOOLEAN NOT NULL DEFAULT false," +
+					"frequency VARCHAR(16) NOT NULL," +
+					"day INT NOT NULL DEFAULT 0," +
+					"end_date TIMESTAMPTZ," +
+					"next_run_on TIMESTAMPTZ NOT NULL," +
+					"due_at TIMESTAMPTZ NOT NULL," +
+					"status VARCHAR(16) NOT NULL DEFAULT 'active'," +
+					"failures INT NOT NULL DEFAULT 0," +
+					"last_transfer_id BIGINT REFERENCES transfers(id)," +
+					"error_type VARCHAR(64)," +
+					"error_message TEXT," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE INDEX standing_orders_owner_id_idx ON standing_orders (owner_id)",
+				"CREATE INDEX standing_orders_due_idx ON standing_orders (due_at) WHERE status = 'active'",
+			},
+			Down: []string{"DROP TABLE standing_orders"},
+		},
+		{
+			Id: "12",
+			Up: []string{
+				"ALTER TABLE accounts ADD COLUMN held DECIMAL NOT NULL DEFAULT 0",
+				"CREATE TABLE holds (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"from_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"to_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"amount DECIMAL NOT NULL," +
+					"convert BOOLEAN NOT NULL DEFAULT false," +
+					"status VARCHAR(16) NOT NULL DEFAULT 'active'," +
+					"expires_at TIMESTAMPTZ NOT NULL," +
+					"transfer_id BIGINT REFERENCES transfers(id)," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE INDEX holds_from_id_idx ON holds (from_id)",
+				"CREATE INDEX holds_expires_at_idx ON holds (expires_at) WHERE status = 'active'",
+			},
+			Down: []string{
+				"DROP TABLE holds",
+				"ALTER TABLE accounts DROP COLUMN held",
+			},
+		},
+		{
+			Id:   "13",
+			Up:   []string{"ALTER TABLE accounts ADD COLUMN overdraft_limit DECIMAL NOT NULL DEFAULT 0"},
+			Down: []string{"ALTER TABLE accounts DROP COLUMN overdraft_limit"},
+		},
+		{
+			Id: "14",
+			Up: []string{
+				"ALTER TABLE accounts ADD COLUMN per_transaction_limit DECIMAL",
+				"ALTER TABLE accounts ADD COLUMN daily_limit DECIMAL",
+				"ALTER TABLE accounts ADD COLUMN monthly_limit DECIMAL",
+				"CREATE INDEX transfers_from_id_created_at_idx ON transfers (from_id, created_at)",
+			},
+			Down: []string{
+				"DROP INDEX transfers_from_id_created_at_idx",
+				"ALTER TABLE accounts DROP COLUMN monthly_limit",
+				"ALTER TABLE accounts DROP COLUMN daily_limit",
+				"ALTER TABLE accounts DROP COLUMN per_transaction_limit",
+			},
+		},
+		{
+			Id: "15",
+			Up: []string{
+				"ALTER TABLE transfers ADD COLUMN fee DECIMAL NOT NULL DEFAULT 0",
+				"ALTER TABLE ledger_entries ADD COLUMN fee DECIMAL NOT NULL DEFAULT 0",
+			},
+			Down: []string{
+				"ALTER TABLE ledger_entries DROP COLUMN fee",
+				"ALTER TABLE transfers DROP COLUMN fee",
+			},
+		},
+		{
+			Id: "16",
+			Up: []string{
+				"ALTER TABLE accounts ADD COLUMN interest_rate DECIMAL NOT NULL DEFAULT 0",
+				"ALTER TABLE accounts ADD COLUMN accrued_interest DECIMAL NOT NULL DEFAULT 0",
+				"CREATE TABLE interest_accruals (" +
+					"account_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"date DATE NOT NULL," +
+					"balance DECIMAL NOT NULL," +
+					"rate DECIMAL NOT NULL," +
+					"amount DECIMAL NOT NULL," +
+					"accrued DECIMAL NOT NULL," +
+					"capitalized DECIMAL NOT NULL," +
+					"journal_id BIGINT REFERENCES journals(id)," +
+					"PRIMARY KEY (account_id, date)" +
+					")",
+			},
+			Down: []string{
+				"DROP TABLE interest_accruals",
+				"ALTER TABLE accounts DROP COLUMN accrued_interest",
+				"ALTER TABLE accounts DROP COLUMN interest_rate",
+			},
+		},
+		{
+			Id:   "17",
+			Up:   []string{"CREATE INDEX ledger_entries_account_id_created_at_idx ON ledger_entries (account_id, created_at)"},
+			Down: []string{"DROP INDEX ledger_entries_account_id_created_at_idx"},
+		},
+		{
+			Id: "18",
+			Up: []string{
+				"CREATE TABLE batches (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"owner_id BIGINT NOT NULL," +
+					"mode VARCHAR(16) NOT NULL," +
+					"status VARCHAR(32) NOT NULL," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()" +
+					")",
+				"CREATE TABLE batch_lines (" +
+					"batch_id BIGINT NOT NULL REFERENCES batches(id)," +
+					"line INT NOT NULL," +
+					"reference VARCHAR(35) NOT NULL DEFAULT ''," +
+					"from_id BIGINT NOT NULL," +
+					"to_id BIGINT NOT NULL," +
+					"amount DECIMAL NOT NULL," +
+					"status VARCHAR(16) NOT NULL," +
+					"transfer_id BIGINT REFERENCES transfers(id)," +
+					"error_type VARCHAR(64)," +
+					"error_message TEXT," +
+					"PRIMARY KEY (batch_id, line)" +
+					")",
+			},
+			Down: []string{"DROP TABLE batch_lines", "DROP TABLE batches"},
+		},
+		{
+			Id: "19",
+			Up: []string{
+				"CREATE TABLE outbox (" +
+					"id BIGSERIAL PRIMARY KEY," +
+					"account_id BIGINT NOT NULL REFERENCES accounts(id)," +
+					"type VARCHAR(64) NOT NULL," +
+					"version INT NOT NULL," +
+					"data JSONB NOT NULL," +
+					"created_at TIMESTAMPTZ NOT NULL DEFAULT now()," +
+					"published_at TIMESTAMPTZ" +
+					")",
+				"CREATE INDEX outbox_pending_idx ON outbox (id) WHERE published_at IS NULL",
+			},
+			Down: []string{"DROP TABLE outbox"},
+		},
+		{
+			Id:   "20",
+			Up:   []string{"ALTER TABLE transfers ADD COLUMN origin VARCHAR(64) UNIQUE"},
+			Down: []string{"ALTER TABLE transfers DROP COLUMN origin"},
+		},
+		{
+			Id:   "21",
+			Up:   []string{"ALTER TABLE holds ADD COLUMN fee DECIMAL NOT NULL DEFAULT 0"},
+			Down: []string{"ALTER TABLE holds DROP COLUMN fee"},
+		},
+		{
+			Id:   "22",
+			Up:   []string{"CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at)"},
+			Down: []string{"DROP INDEX idempotency_keys_created_at_idx"},
+		},
+		{
+			Id:   "23",
+			Up:   []string{"ALTER TABLE scheduled_transfers ADD COLUMN attempts INT NOT NULL DEFAULT 0"},
+			Down: []string{"ALTER TABLE scheduled_transfers DROP COLUMN attempts"},
+		},
+		{
+			Id:   "24",
+			Up:   []string{"ALTER TABLE accounts ADD COLUMN interest_rate_since DATE"},
+			Down: []string{"ALTER TABLE accounts DROP COLUMN interest_rate_since"},
+		},
 	},
 }
 
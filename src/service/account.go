@@ -1,29 +1,58 @@
 package service
 
 import (
+	"github.com/shopspring/decimal"
 	"golang_bank_demo/src/dto"
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/storage"
+	"time"
 )
 
 type AccountService interface {
-	Create(user model.UserId) (*model.Account, error)
+	Create(request *dto.CreateAccountRequest, user model.UserId) (*model.Account, error)
 	Get(accountId model.AccountId, user model.UserId) (*model.Account, error)
-	TopUp(request *dto.TopUpRequest, user model.UserId) error
-	Transfer(request *dto.TransferRequest, user model.UserId) error
+	List(user model.UserId) ([]model.Account, error)
+	TopUp(request *dto.TopUpRequest, user model.UserId) (*model.LedgerEntry, error)
+	Transfer(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error)
+	// TransferAll makes all the transfers or none of them. The error of the transfer that fails is a BatchTransferError.
+	TransferAll(requests []*dto.TransferRequest, user model.UserId) ([]model.Transfer, error)
+	// TransferLegs pays out from one account to many in one go. The error of the leg that fails is a BatchTransferError.
+	TransferLegs(request *dto.TransferLegsRequest, user model.UserId) ([]model.Transfer, error)
+	Quote(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error)
+	GetTransfer(transferId model.TransferId, user model.UserId) (*model.Transfer, error)
+	GetTopUp(topUpId model.LedgerEntryId, user model.UserId) (*model.LedgerEntry, error)
+	Schedule(request *dto.TransferRequest, user model.UserId) (*model.ScheduledTransfer, error)
+	ScheduledTransfers(user model.UserId) ([]model.ScheduledTransfer, error)
+	CancelScheduled(id model.ScheduledTransferId, user model.UserId) (*model.ScheduledTransfer, error)
+	Transactions(request *dto.TransactionsRequest, user model.UserId) (*model.LedgerPage, error)
+	// Statement checks the request and the access before the writer gets anything, so errors can still be answered normally
+	Statement(request *dto.StatementRequest, user model.UserId, writer model.StatementWriter) error
+	Freeze(accountId model.AccountId, user model.UserId) (*model.Account, error)
+	Close(request *dto.CloseAccountRequest, user model.UserId) (*model.Account, error)
 }
 
 type RealAccountService struct {
-	storage storage.AccountStorage
+	storage   storage.AccountStorage
+	ledger    storage.LedgerStorage
+	scheduled storage.ScheduledTransferStorage
+	fxRates   FxRateProvider
+	fees      FeeProvider
+	limits    model.Limits
 }
 
-func NewAccountService(accountStorage storage.AccountStorage) AccountService {
-	return &RealAccountService{storage: accountStorage}
+func NewAccountService(accountStorage storage.AccountStorage, ledgerStorage storage.LedgerStorage,
+	scheduledStorage storage.ScheduledTransferStorage, fxRates FxRateProvider, fees FeeProvider, limits model.Limits) AccountService {
+	return &RealAccountService{storage: accountStorage, ledger: ledgerStorage, scheduled: scheduledStorage, fxRates: fxRates, fees: fees,
+		limits: limits}
 }
 
-func (service *RealAccountService) Create(user model.UserId) (*model.Account, error) {
-	return service.storage.Create(user)
+func (service *RealAccountService) Create(request *dto.CreateAccountRequest, user model.UserId) (*model.Account, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	} else {
+		return service.storage.Create(request.Account(user))
+	}
 }
 
 func (service *RealAccountService) Get(accountId model.AccountId, user model.UserId) (*model.Account, error) {
@@ -36,28 +65,245 @@ func (service *RealAccountService) Get(accountId model.AccountId, user model.Use
 	}
 }
 
-func (service *RealAccountService) TopUp(request *dto.TopUpRequest, user model.UserId) error {
+func (service *RealAccountService) List(user model.UserId) ([]model.Account, error) {
+	return service.storage.ListByOwner(user)
+}
+
+func (service *RealAccountService) TopUp(request *dto.TopUpRequest, user model.UserId) (*model.LedgerEntry, error) {
 	if err := request.Validate(); err != nil {
-		return err
+		return nil, err
 	} else if account, err := service.storage.Get(request.Id); err != nil {
-		return err
+		return nil, err
 	} else if account.Owner != user {
-		return &errors.ForbiddenAccountAccessError{AccountId: request.Id, UserId: user}
+		return nil, &errors.ForbiddenAccountAccessError{AccountId: request.Id, UserId: user}
+	} else if err := errors.CheckActive(account); err != nil {
+		return nil, err
+	} else if err := request.ValidateCurrency(account.Currency); err != nil {
+		return nil, err
+	} else if fee, err := service.fees.Fee(model.TopUpOperation, account, request.Amount); err != nil {
+		return nil, err
+	} else {
+		return service.storage.TopUp(request.Id, request.Amount, fee)
+	}
+}
+
+// Transfer charges the fee of the source account type, which the source account has to cover on top of the amount
+func (service *RealAccountService) Transfer(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error) {
+	if made, err := service.madeTransfer(request); err != nil || made != nil {
+		return made, err
+	} else if transfer, fromAccount, err := service.prepareTransfer(request, user); err != nil {
+		return nil, err
+	} else if transfer.Fee, err = service.fees.Fee(model.TransferOperation, fromAccount, transfer.Amount); err != nil {
+		return nil, err
+	} else {
+		transfer.Origin = request.Origin
+		return service.storage.TransferWithinLimits(transfer, service.limits)
+	}
+}
+
+// madeTransfer finds the transfer that was already made for the origin of the request. A scheduled run is tried
+// again when its outcome could not be stored after the transfer, and then it gets the transfer instead of paying twice.
+func (service *RealAccountService) madeTransfer(request *dto.TransferRequest) (*model.Transfer, error) {
+	if request.Origin == nil {
+		return nil, nil
+	} else {
+		return service.storage.GetTransferByOrigin(*request.Origin)
+	}
+}
+
+func (service *RealAccountService) TransferAll(requests []*dto.TransferRequest, user model.UserId) ([]model.Transfer, error) {
+	if transfers, err := service.prepareTransfers(requests, user); err != nil {
+		return nil, err
 	} else {
-		return service.storage.TopUp(request.Id, request.Amount)
+		return service.storage.TransferAll(transfers, service.limits)
 	}
 }
 
-func (service *RealAccountService) Transfer(request *dto.TransferRequest, user model.UserId) error {
+// TransferLegs checks the source account before the legs, so that its errors are not blamed on the first leg
+func (service *RealAccountService) TransferLegs(request *dto.TransferLegsRequest, user model.UserId) ([]model.Transfer, error) {
 	if err := request.Validate(); err != nil {
-		return err
+		return nil, err
+	} else if fromAccount, err := service.Get(request.From, user); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(fromAccount); err != nil {
+		return nil, err
+	} else if transfers, err := service.prepareTransfers(request.TransferRequests(), user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.TransferLegs(transfers, service.limits)
+	}
+}
+
+func (service *RealAccountService) prepareTransfers(requests []*dto.TransferRequest, user model.UserId) ([]*model.Transfer, error) {
+	transfers := make([]*model.Transfer, len(requests))
+	for i, request := range requests {
+		if transfer, fromAccount, err := service.prepareTransfer(request, user); err != nil {
+			return nil, &errors.BatchTransferError{Index: i, Err: err}
+		} else if transfer.Fee, err = service.fees.Fee(model.TransferOperation, fromAccount, transfer.Amount); err != nil {
+			return nil, &errors.BatchTransferError{Index: i, Err: err}
+		} else {
+			transfers[i] = transfer
+		}
+	}
+	return transfers, nil
+}
+
+// Quote checks the request like a transfer and returns the transfer it would make with its fee, without looking
+// at the balance, which only matters when the money is moved
+func (service *RealAccountService) Quote(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error) {
+	if transfer, fromAccount, err := service.prepareTransfer(request, user); err != nil {
+		return nil, err
+	} else if transfer.Fee, err = service.fees.Fee(model.TransferOperation, fromAccount, transfer.Amount); err != nil {
+		return nil, err
+	} else {
+		return transfer, nil
+	}
+}
+
+func (service *RealAccountService) Schedule(request *dto.TransferRequest, user model.UserId) (*model.ScheduledTransfer, error) {
+	if err := request.ValidateExecuteAt(time.Now()); err != nil {
+		return nil, err
+	} else if _, err := service.Quote(request, user); err != nil {
+		return nil, err
+	} else {
+		return service.scheduled.Create(request.ScheduledTransfer(user))
+	}
+}
+
+func (service *RealAccountService) ScheduledTransfers(user model.UserId) ([]model.ScheduledTransfer, error) {
+	return service.scheduled.ListByOwner(user)
+}
+
+func (service *RealAccountService) CancelScheduled(id model.ScheduledTransferId, user model.UserId) (*model.ScheduledTransfer, error) {
+	if transfer, err := service.scheduled.Get(id); err != nil {
+		return nil, err
+	} else if transfer.Owner != user {
+		return nil, &errors.ForbiddenAccountAccessError{AccountId: transfer.From, UserId: user}
+	} else {
+		return service.scheduled.Cancel(id)
+	}
+}
+
+func (service *RealAccountService) prepareTransfer(request *dto.TransferRequest, user model.UserId) (*model.Transfer, *model.Account, error) {
+	if err := request.Validate(); err != nil {
+		return nil, nil, err
 	} else if fromAccount, err := service.storage.Get(request.From); err != nil {
-		return err
+		return nil, nil, err
 	} else if fromAccount.Owner != user {
-		return &errors.ForbiddenAccountAccessError{AccountId: request.From, UserId: user}
-	} else if err = service.storage.Transfer(request.From, request.To, request.Amount); err != nil {
+		return nil, nil, &errors.ForbiddenAccountAccessError{AccountId: request.From, UserId: user}
+	} else if err := errors.CheckActive(fromAccount); err != nil {
+		return nil, nil, err
+	} else if err := request.ValidateCurrency(fromAccount.Currency); err != nil {
+		return nil, nil, err
+	} else if toAccount, err := service.getCounterparty(request.To); err != nil {
+		return nil, nil, err
+	} else if err := errors.CheckActive(toAccount); err != nil {
+		return nil, nil, err
+	} else {
+		transfer, err := service.quote(request, fromAccount, toAccount)
+		return transfer, fromAccount, err
+	}
+}
+
+// GetTransfer shows the receipt only to the owner of the source account, because it contains the balance of that account
+func (service *RealAccountService) GetTransfer(transferId model.TransferId, user model.UserId) (*model.Transfer, error) {
+	if transfer, err := service.storage.GetTransfer(transferId); err != nil {
+		return nil, err
+	} else if _, err := service.Get(transfer.From, user); err != nil {
+		return nil, err
+	} else {
+		return transfer, nil
+	}
+}
+
+func (service *RealAccountService) GetTopUp(topUpId model.LedgerEntryId, user model.UserId) (*model.LedgerEntry, error) {
+	if entry, err := service.ledger.GetTopUp(topUpId); err != nil {
+		return nil, err
+	} else if _, err := service.Get(entry.AccountId, user); err != nil {
+		return nil, err
+	} else {
+		return entry, nil
+	}
+}
+
+// getCounterparty hides the system accounts, because the customers can only move money to each other
+func (service *RealAccountService) getCounterparty(accountId model.AccountId) (*model.Account, error) {
+	if account, err := service.storage.Get(accountId); err != nil {
+		return nil, err
+	} else if account.Type == model.SystemAccount {
+		return nil, &errors.AccountDoesNotExistError{AccountId: accountId}
+	} else {
+		return account, nil
+	}
+}
+
+func (service *RealAccountService) quote(request *dto.TransferRequest, fromAccount, toAccount *model.Account) (*model.Transfer, error) {
+	if fromAccount.Currency == toAccount.Currency {
+		return model.NewTransfer(request.From, request.To, request.Amount), nil
+	} else if !request.Convert {
+		return nil, &errors.CurrencyMismatchError{From: request.From, To: request.To, FromCurrency: fromAccount.Currency, ToCurrency: toAccount.Currency}
+	} else if rate, err := service.fxRates.Rate(fromAccount.Currency, toAccount.Currency); err != nil {
+		return nil, err
+	} else if creditAmount := toAccount.Currency.Round(request.Amount.Mul(rate)); !creditAmount.IsPositive() {
+		return nil, errors.NewValidationError("amount", "The amount is too small to be converted to "+string(toAccount.Currency))
+	} else {
+		return &model.Transfer{
+			From:         request.From,
+			To:           request.To,
+			Amount:       request.Amount,
+			CreditAmount: creditAmount,
+			FxRate:       decimal.NullDecimal{Decimal: rate, Valid: true},
+			Fee:          decimal.Zero,
+		}, nil
+	}
+}
+
+func (service *RealAccountService) Transactions(request *dto.TransactionsRequest, user model.UserId) (*model.LedgerPage, error) {
+	filter := request.Filter()
+	// One extra entry tells whether there is a next page
+	filter.Limit = request.Limit + 1
+	if err := request.Validate(); err != nil {
+		return nil, err
+	} else if _, err := service.Get(request.AccountId, user); err != nil {
+		return nil, err
+	} else if entries, err := service.ledger.List(request.AccountId, filter); err != nil {
+		return nil, err
+	} else if len(entries) > request.Limit {
+		entries = entries[:request.Limit]
+		return &model.LedgerPage{Entries: entries, Next: &entries[len(entries)-1].Id}, nil
+	} else {
+		return &model.LedgerPage{Entries: entries}, nil
+	}
+}
+
+func (service *RealAccountService) Statement(request *dto.StatementRequest, user model.UserId, writer model.StatementWriter) error {
+	if err := request.Validate(); err != nil {
 		return err
+	} else if account, err := service.Get(request.AccountId, user); err != nil {
+		return err
+	} else {
+		return service.ledger.Statement(request.Statement(account, time.Now()), writer)
+	}
+}
+
+func (service *RealAccountService) Freeze(accountId model.AccountId, user model.UserId) (*model.Account, error) {
+	if _, err := service.Get(accountId, user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.SetStatus(accountId, model.FrozenAccount)
+	}
+}
+
+func (service *RealAccountService) Close(request *dto.CloseAccountRequest, user model.UserId) (*model.Account, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	} else if _, err := service.Get(request.Id, user); err != nil {
+		return nil, err
+	} else if request.SweepTo == nil {
+		return service.storage.Close(request.Id, nil)
+	} else if _, err := service.getCounterparty(*request.SweepTo); err != nil {
+		return nil, err
 	} else {
-		return nil
+		return service.storage.Close(request.Id, request.SweepTo)
 	}
 }
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+)
+
+type AdminService interface {
+	TrialBalance(user model.UserId) (*model.TrialBalance, error)
+	Reverse(request *dto.ReverseTransferRequest, user model.UserId) (*model.Transfer, error)
+	SetOverdraftLimit(request *dto.OverdraftLimitRequest, user model.UserId) (*model.Account, error)
+	OverdrawnAccounts(user model.UserId) ([]model.Account, error)
+	SetTransferLimits(request *dto.TransferLimitsRequest, user model.UserId) (*model.Account, error)
+	SetInterestRate(request *dto.InterestRateRequest, user model.UserId) (*model.Account, error)
+	Unfreeze(accountId model.AccountId, user model.UserId) (*model.Account, error)
+}
+
+type RealAdminService struct {
+	accounts storage.AccountStorage
+	journal  storage.JournalStorage
+	admins   map[model.UserId]bool
+}
+
+func NewAdminService(accountStorage storage.AccountStorage, journalStorage storage.JournalStorage, admins []model.UserId) AdminService {
+	adminSet := make(map[model.UserId]bool, len(admins))
+	for _, admin := range admins {
+		adminSet[admin] = true
+	}
+	return &RealAdminService{accounts: accountStorage, journal: journalStorage, admins: adminSet}
+}
+
+func (service *RealAdminService) TrialBalance(user model.UserId) (*model.TrialBalance, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else {
+		return service.journal.TrialBalance()
+	}
+}
+
+func (service *RealAdminService) Reverse(request *dto.ReverseTransferRequest, user model.UserId) (*model.Transfer, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else if err := request.Validate(); err != nil {
+		return nil, err
+	} else {
+		return service.accounts.Reverse(request.Id, request.Partial)
+	}
+}
+
+// SetOverdraftLimit hides the system accounts, which have no limit at all
+func (service *RealAdminService) SetOverdraftLimit(request *dto.OverdraftLimitRequest, user model.UserId) (*model.Account, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else if err := request.Validate(); err != nil {
+		return nil, err
+	} else if account, err := service.accounts.Get(request.AccountId); err != nil {
+		return nil, err
+	} else if account.Type == model.SystemAccount {
+		return nil, &errors.AccountDoesNotExistError{AccountId: request.AccountId}
+	} else if err := request.ValidateCurrency(account.Currency); err != nil {
+		return nil, err
+	} else {
+		return service.accounts.SetOverdraftLimit(request.AccountId, request.OverdraftLimit)
+	}
+}
+
+func (service *RealAdminService) SetTransferLimits(request *dto.TransferLimitsRequest, user model.UserId) (*model.Account, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else if account, err := service.accounts.Get(request.AccountId); err != nil {
+		return nil, err
+	} else if account.Type == model.SystemAccount {
+		return nil, &errors.AccountDoesNotExistError{AccountId: request.AccountId}
+	} else if err := request.Validate(account.Currency); err != nil {
+		return nil, err
+	} else {
+		return service.accounts.SetTransferLimits(request.AccountId, request.Limits())
+	}
+}
+
+func (service *RealAdminService) SetInterestRate(request *dto.InterestRateRequest, user model.UserId) (*model.Account, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else if err := request.Validate(); err != nil {
+		return nil, err
+	} else if account, err := service.accounts.Get(request.AccountId); err != nil {
+		return nil, err
+	} else if account.Type == model.SystemAccount {
+		return nil, &errors.AccountDoesNotExistError{AccountId: request.AccountId}
+	} else {
+		return service.accounts.SetInterestRate(request.AccountId, request.InterestRate)
+	}
+}
+
+// Unfreeze is for the admins only, as a frozen account may have been compromised, and its owner's credentials with it
+func (service *RealAdminService) Unfreeze(accountId model.AccountId, user model.UserId) (*model.Account, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else if account, err := service.accounts.Get(accountId); err != nil {
+		return nil, err
+	} else if account.Type == model.SystemAccount {
+		return nil, &errors.AccountDoesNotExistError{AccountId: accountId}
+	} else {
+		return service.accounts.SetStatus(accountId, model.ActiveAccount)
+	}
+}
+
+func (service *RealAdminService) OverdrawnAccounts(user model.UserId) ([]model.Account, error) {
+	if err := service.checkAdmin(user); err != nil {
+		return nil, err
+	} else {
+		return service.accounts.ListOverdrawn()
+	}
+}
+
+func (service *RealAdminService) checkAdmin(user model.UserId) error {
+	if !service.admins[user] {
+		return &errors.AdminAccessRequiredError{UserId: user}
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+)
+
+type BatchService interface {
+	// Create runs the batch before it returns, and the lines that could not be read fail on their own
+	Create(request *dto.BatchRequest, user model.UserId) (*model.Batch, error)
+	Get(id model.BatchId, user model.UserId) (*model.Batch, error)
+}
+
+type RealBatchService struct {
+	accounts AccountService
+	storage  storage.BatchStorage
+}
+
+func NewBatchService(accountService AccountService, batchStorage storage.BatchStorage) BatchService {
+	return &RealBatchService{accounts: accountService, storage: batchStorage}
+}
+
+// Create stores the batch as processing before the first transfer, so that it can be looked up while it runs
+func (service *RealBatchService) Create(request *dto.BatchRequest, user model.UserId) (*model.Batch, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	failures := make([]error, len(request.Lines))
+	for i := range request.Lines {
+		failures[i] = service.check(&request.Lines[i], user)
+	}
+	batch, err := service.storage.Create(request.Batch(user))
+	if err != nil {
+		return nil, err
+	}
+	if batch.Mode == model.AllOrNothingBatch {
+		service.runAllOrNothing(batch, request, failures, user)
+	} else {
+		service.runBestEffort(batch, request, failures, user)
+	}
+	batch.Finish()
+	return service.storage.Finish(batch)
+}
+
+func (service *RealBatchService) Get(id model.BatchId, user model.UserId) (*model.Batch, error) {
+	if batch, err := service.storage.Get(id); err != nil {
+		return nil, err
+	} else if batch.Owner != user {
+		return nil, &errors.ForbiddenAccountAccessError{AccountId: batch.Lines[0].From, UserId: user}
+	} else {
+		return batch, nil
+	}
+}
+
+// check validates the line like a transfer, and the currency of its amount against the one of the source account
+func (service *RealBatchService) check(line *dto.BatchLineRequest, user model.UserId) error {
+	if err := line.Validate(); err != nil {
+		return err
+	} else if _, err := service.accounts.Quote(&line.Transfer, user); err != nil {
+		return err
+	} else if line.Currency == "" {
+		return nil
+	} else if account, err := service.accounts.Get(line.Transfer.From, user); err != nil {
+		return err
+	} else if account.Currency != line.Currency {
+		return errors.NewValidationError("currency", "The currency has to be the one of the source account")
+	} else {
+		return nil
+	}
+}
+
+// runAllOrNothing makes no transfer when one of the lines is invalid, and the lines that were not at fault are skipped
+func (service *RealBatchService) runAllOrNothing(batch *model.Batch, request *dto.BatchRequest, failures []error, user model.UserId) {
+	requests := make([]*dto.TransferRequest, len(request.Lines))
+	for i := range request.Lines {
+		requests[i] = &request.Lines[i].Transfer
+		if failures[i] != nil {
+			skipOthers(batch, failures)
+			return
+		}
+	}
+	if transfers, err := service.accounts.TransferAll(requests, user); err == nil {
+		for i := range batch.Lines {
+			completeLine(&batch.Lines[i], &transfers[i])
+		}
+	} else if failed, ok := err.(*errors.BatchTransferError); ok {
+		failures[failed.Index] = failed.Err
+		skipOthers(batch, failures)
+	} else {
+		for i := range failures {
+			failures[i] = err
+		}
+		skipOthers(batch, failures)
+	}
+}
+
+func (service *RealBatchService) runBestEffort(batch *model.Batch, request *dto.BatchRequest, failures []error, user model.UserId) {
+	for i := range batch.Lines {
+		if failures[i] != nil {
+			failLine(&batch.Lines[i], failures[i])
+		} else if transfer, err := service.accounts.Transfer(&request.Lines[i].Transfer, user); err != nil {
+			failLine(&batch.Lines[i], err)
+		} else {
+			completeLine(&batch.Lines[i], transfer)
+		}
+	}
+}
+
+func skipOthers(batch *model.Batch, failures []error) {
+	for i := range batch.Lines {
+		if failures[i] != nil {
+			failLine(&batch.Lines[i], failures[i])
+		} else {
+			batch.Lines[i].Status = model.SkippedBatchLine
+		}
+	}
+}
+
+func completeLine(line *model.BatchLine, transfer *model.Transfer) {
+	line.Status = model.CompletedBatchLine
+	line.TransferId = &transfer.Id
+}
+
+// failLine keeps the error with the name of its type, like the failed run of a standing order
+func failLine(line *model.BatchLine, err error) {
+	errorType, message := errors.TypeName(err), err.Error()
+	line.Status = model.FailedBatchLine
+	line.ErrorType = &errorType
+	line.ErrorMessage = &message
+}
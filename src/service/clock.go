@@ -0,0 +1,18 @@
+package service
+
+import "time"
+
+// Clock tells the current time, so that the time dependent services can be tested at any date
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func NewSystemClock() Clock {
+	return &SystemClock{}
+}
+
+func (clock *SystemClock) Now() time.Time {
+	return time.Now()
+}
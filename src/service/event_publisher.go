@@ -0,0 +1,46 @@
+package service
+
+import (
+	"encoding/json"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"io"
+	"os"
+	"sync"
+)
+
+// EventPublisher delivers the events of the outbox to their consumers. The relay publishes an event again when
+// the publishing failed or the server stopped before it was recorded, so the consumers have to deduplicate by the id.
+type EventPublisher interface {
+	Publish(event *model.Event) error
+}
+
+// WriterEventPublisher writes every event as a line of JSON, which is enough for local testing
+type WriterEventPublisher struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+func NewWriterEventPublisher(out io.Writer) EventPublisher {
+	return &WriterEventPublisher{out: out}
+}
+
+// NewFileEventPublisher appends the events to the file
+func NewFileEventPublisher(path string) (EventPublisher, error) {
+	if file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err != nil {
+		return nil, err
+	} else {
+		return NewWriterEventPublisher(file), nil
+	}
+}
+
+func (publisher *WriterEventPublisher) Publish(event *model.Event) error {
+	if line, err := json.Marshal(dto.EventFromModel(event)); err != nil {
+		return err
+	} else {
+		publisher.mutex.Lock()
+		defer publisher.mutex.Unlock()
+		_, err = publisher.out.Write(append(line, '\n'))
+		return err
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sync"
+	"time"
+)
+
+type FeeProvider interface {
+	// Fee returns the fee of the operation on the account, in the currency of the account
+	Fee(operation model.FeeOperation, account *model.Account, amount decimal.Decimal) (decimal.Decimal, error)
+}
+
+type StaticFeeProvider struct {
+	schedule model.FeeSchedule
+}
+
+func NewStaticFeeProvider(schedule model.FeeSchedule) FeeProvider {
+	return &StaticFeeProvider{schedule: schedule}
+}
+
+func (provider *StaticFeeProvider) Fee(operation model.FeeOperation, account *model.Account, amount decimal.Decimal) (decimal.Decimal, error) {
+	return provider.schedule.Fee(operation, account, amount), nil
+}
+
+type FileFeeProvider struct {
+	path       string
+	mutex      sync.RWMutex
+	schedule   model.FeeSchedule
+	modifiedAt time.Time
+}
+
+// NewFileFeeProvider loads the fee schedule from a yaml file, which is read again whenever it changes
+func NewFileFeeProvider(path string) (FeeProvider, error) {
+	provider := &FileFeeProvider{path: path}
+	if err := provider.reload(); err != nil {
+		return nil, err
+	} else {
+		return provider, nil
+	}
+}
+
+type feeScheduleFile struct {
+	Rules []feeRuleFile `yaml:"rules"`
+}
+
+type feeRuleFile struct {
+	Operation   model.FeeOperation `yaml:"operation"`
+	AccountType model.AccountType  `yaml:"account_type"`
+	Flat        string             `yaml:"flat"`
+	Rate        string             `yaml:"rate"`
+	Tiers       []feeTierFile      `yaml:"tiers"`
+	Min         string             `yaml:"min"`
+	Max         string             `yaml:"max"`
+}
+
+type feeTierFile struct {
+	UpTo string `yaml:"up_to"`
+	Flat string `yaml:"flat"`
+	Rate string `yaml:"rate"`
+}
+
+func (provider *FileFeeProvider) Fee(operation model.FeeOperation, account *model.Account, amount decimal.Decimal) (decimal.Decimal, error) {
+	if err := provider.reload(); err != nil {
+		return decimal.Decimal{}, &errors.InternalServerError{Err: err}
+	}
+	provider.mutex.RLock()
+	defer provider.mutex.RUnlock()
+	return provider.schedule.Fee(operation, account, amount), nil
+}
+
+func (provider *FileFeeProvider) reload() error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	var file feeScheduleFile
+	if info, err := os.Stat(provider.path); err != nil {
+		return err
+	} else if provider.schedule != nil && !info.ModTime().After(provider.modifiedAt) {
+		return nil
+	} else if content, err := os.ReadFile(provider.path); err != nil {
+		return err
+	} else if err := yaml.Unmarshal(content, &file); err != nil {
+		return err
+	} else {
+		schedule := make(model.FeeSchedule, len(file.Rules))
+		for i := range file.Rules {
+			if rule, err := file.Rules[i].rule(); err != nil {
+				return fmt.Errorf("Invalid fee rule %d in %s: %w", i+1, provider.path, err)
+			} else {
+				schedule[i] = *rule
+			}
+		}
+		provider.schedule = schedule
+		provider.modifiedAt = info.ModTime()
+		return nil
+	}
+}
+
+func (file *feeRuleFile) rule() (*model.FeeRule, error) {
+	rule := &model.FeeRule{Operation: file.Operation, AccountType: file.AccountType, Tiers: make([]model.FeeTier, len(file.Tiers))}
+	var err error
+	if !file.Operation.IsValid() {
+		return nil, fmt.Errorf("the operation has to be 'top_up' or 'transfer'")
+	} else if file.AccountType != "" && !file.AccountType.IsValid() {
+		return nil, fmt.Errorf("unknown account type '%s'", file.AccountType)
+	} else if rule.Flat, err = parseFeeAmount("flat", file.Flat); err != nil {
+		return nil, err
+	} else if rule.Rate, err = parseFeeAmount("rate", file.Rate); err != nil {
+		return nil, err
+	} else if rule.Min, err = parseOptionalFeeAmount("min", file.Min); err != nil {
+		return nil, err
+	} else if rule.Max, err = parseOptionalFeeAmount("max", file.Max); err != nil {
+		return nil, err
+	} else if rule.Min.Valid && rule.Max.Valid && rule.Min.Decimal.GreaterThan(rule.Max.Decimal) {
+		return nil, fmt.Errorf("the min cannot be more than the max")
+	}
+	for i := range file.Tiers {
+		if rule.Tiers[i], err = file.Tiers[i].tier(); err != nil {
+			return nil, fmt.Errorf("tier %d: %w", i+1, err)
+		} else if i > 0 && (!rule.Tiers[i-1].UpTo.Valid || rule.Tiers[i].UpTo.Valid && !rule.Tiers[i].UpTo.Decimal.GreaterThan(rule.Tiers[i-1].UpTo.Decimal)) {
+			return nil, fmt.Errorf("tier %d: the tiers have to be ordered by up_to, and only the last one can leave it out", i+1)
+		}
+	}
+	return rule, nil
+}
+
+func (file *feeTierFile) tier() (tier model.FeeTier, err error) {
+	if tier.UpTo, err = parseOptionalFeeAmount("up_to", file.UpTo); err != nil {
+		return
+	} else if tier.Flat, err = parseFeeAmount("flat", file.Flat); err != nil {
+		return
+	} else {
+		tier.Rate, err = parseFeeAmount("rate", file.Rate)
+		return
+	}
+}
+
+func parseFeeAmount(field, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	} else if amount, err := decimal.NewFromString(value); err != nil {
+		return amount, fmt.Errorf("invalid %s: %w", field, err)
+	} else if amount.IsNegative() {
+		return amount, fmt.Errorf("the %s cannot be negative", field)
+	} else {
+		return amount, nil
+	}
+}
+
+func parseOptionalFeeAmount(field, value string) (decimal.NullDecimal, error) {
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	} else if amount, err := parseFeeAmount(field, value); err != nil {
+		return decimal.NullDecimal{}, err
+	} else {
+		return decimal.NewNullDecimal(amount), nil
+	}
+}
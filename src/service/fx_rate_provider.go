@@ -0,0 +1,124 @@
+package service
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FxRateProvider interface {
+	// Rate returns how many units of the target currency one unit of the source currency buys
+	Rate(from, to model.Currency) (decimal.Decimal, error)
+}
+
+type StaticFxRateProvider struct {
+	rates map[string]decimal.Decimal
+}
+
+// NewStaticFxRateProvider takes the rates by currency pairs in the format 'EUR/USD'.
+// The inverse pairs are derived, so that each pair has to be given only once.
+func NewStaticFxRateProvider(rates map[string]decimal.Decimal) FxRateProvider {
+	return &StaticFxRateProvider{rates: rates}
+}
+
+func (provider *StaticFxRateProvider) Rate(from, to model.Currency) (decimal.Decimal, error) {
+	return lookUpRate(provider.rates, from, to)
+}
+
+type FileFxRateProvider struct {
+	path       string
+	mutex      sync.RWMutex
+	rates      map[string]decimal.Decimal
+	modifiedAt time.Time
+}
+
+// NewFileFxRateProvider loads the rates from a yaml file, which is read again whenever it changes
+func NewFileFxRateProvider(path string) (FxRateProvider, error) {
+	provider := &FileFxRateProvider{path: path}
+	if err := provider.reload(); err != nil {
+		return nil, err
+	} else {
+		return provider, nil
+	}
+}
+
+type fxRatesFile struct {
+	Rates map[string]string `yaml:"rates"`
+}
+
+func (provider *FileFxRateProvider) Rate(from, to model.Currency) (decimal.Decimal, error) {
+	if err := provider.reload(); err != nil {
+		return decimal.Decimal{}, &errors.InternalServerError{Err: err}
+	}
+	provider.mutex.RLock()
+	defer provider.mutex.RUnlock()
+	return lookUpRate(provider.rates, from, to)
+}
+
+func (provider *FileFxRateProvider) reload() error {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	var file fxRatesFile
+	if info, err := os.Stat(provider.path); err != nil {
+		return err
+	} else if provider.rates != nil && !info.ModTime().After(provider.modifiedAt) {
+		return nil
+	} else if content, err := os.ReadFile(provider.path); err != nil {
+		return err
+	} else if err := yaml.Unmarshal(content, &file); err != nil {
+		return err
+	} else {
+		rates := make(map[string]decimal.Decimal, len(file.Rates))
+		for pair, value := range file.Rates {
+			if rate, err := decimal.NewFromString(value); err != nil {
+				return fmt.Errorf("Invalid exchange rate of '%s' in %s: %w", pair, provider.path, err)
+			} else if !rate.IsPositive() {
+				return fmt.Errorf("The exchange rate of '%s' in %s has to be positive", pair, provider.path)
+			} else {
+				rates[strings.ToUpper(pair)] = rate
+			}
+		}
+		provider.rates = rates
+		provider.modifiedAt = info.ModTime()
+		return nil
+	}
+}
+
+type SpreadFxRateProvider struct {
+	provider FxRateProvider
+	spread   decimal.Decimal
+}
+
+// NewSpreadFxRateProvider makes the customer rate worse than the underlying rate by the spread, for example 0.005 for 0.5%
+func NewSpreadFxRateProvider(provider FxRateProvider, spread decimal.Decimal) FxRateProvider {
+	return &SpreadFxRateProvider{provider: provider, spread: spread}
+}
+
+func (provider *SpreadFxRateProvider) Rate(from, to model.Currency) (decimal.Decimal, error) {
+	if rate, err := provider.provider.Rate(from, to); err != nil {
+		return decimal.Decimal{}, err
+	} else if from == to {
+		return rate, nil
+	} else {
+		return rate.Mul(decimal.NewFromInt(1).Sub(provider.spread)), nil
+	}
+}
+
+func lookUpRate(rates map[string]decimal.Decimal, from, to model.Currency) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	} else if rate, found := rates[string(from)+"/"+string(to)]; found {
+		return rate, nil
+	} else if inverseRate, found := rates[string(to)+"/"+string(from)]; found {
+		return decimal.NewFromInt(1).Div(inverseRate), nil
+	} else {
+		return decimal.Decimal{}, &errors.FxRateUnavailableError{From: from, To: to}
+	}
+}
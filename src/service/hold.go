@@ -0,0 +1,76 @@
+package service
+
+import (
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+)
+
+type HoldService interface {
+	Place(request *dto.HoldRequest, user model.UserId) (*model.Hold, error)
+	Get(id model.HoldId, user model.UserId) (*model.Hold, error)
+	Capture(request *dto.CaptureHoldRequest, user model.UserId) (*model.Transfer, error)
+	Release(id model.HoldId, user model.UserId) (*model.Hold, error)
+}
+
+type RealHoldService struct {
+	accounts AccountService
+	storage  storage.HoldStorage
+	clock    Clock
+	config   *config.Holds
+	limits   model.Limits
+}
+
+func NewHoldService(accountService AccountService, holdStorage storage.HoldStorage, clock Clock, holdConfig *config.Holds,
+	limits model.Limits) HoldService {
+	return &RealHoldService{accounts: accountService, storage: holdStorage, clock: clock, config: holdConfig, limits: limits}
+}
+
+// Place reserves the fee of the transfer together with the amount, so that the capture can pay it
+func (service *RealHoldService) Place(request *dto.HoldRequest, user model.UserId) (*model.Hold, error) {
+	now := service.clock.Now()
+	if err := request.Validate(now); err != nil {
+		return nil, err
+	} else if transfer, err := service.accounts.Quote(request.TransferRequest(), user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.PlaceHold(request.Hold(now.Add(service.config.Expiration), transfer.Fee))
+	}
+}
+
+// Get shows the hold only to the owner of the source account, like the transfer it makes
+func (service *RealHoldService) Get(id model.HoldId, user model.UserId) (*model.Hold, error) {
+	if hold, err := service.storage.GetHold(id); err != nil {
+		return nil, err
+	} else if _, err := service.accounts.Get(hold.From, user); err != nil {
+		return nil, err
+	} else {
+		return hold, nil
+	}
+}
+
+// Capture refuses a hold that has expired but has not been released by the expirer yet.
+// The captured transfer is charged its fee and counts against the limits like a transfer made directly.
+func (service *RealHoldService) Capture(request *dto.CaptureHoldRequest, user model.UserId) (*model.Transfer, error) {
+	if hold, err := service.Get(request.Id, user); err != nil {
+		return nil, err
+	} else if hold.Status == model.ActiveHold && !hold.ExpiresAt.After(service.clock.Now()) {
+		return nil, &errors.HoldNotActiveError{HoldId: hold.Id, Status: model.ExpiredHold}
+	} else if err := request.Validate(hold); err != nil {
+		return nil, err
+	} else if transfer, err := service.accounts.Quote(request.TransferRequest(hold), user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.CaptureHold(hold.Id, transfer, service.limits)
+	}
+}
+
+func (service *RealHoldService) Release(id model.HoldId, user model.UserId) (*model.Hold, error) {
+	if _, err := service.Get(id, user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.ReleaseHold(id)
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// HoldExpirer releases the holds that were neither captured nor released before they expired
+type HoldExpirer struct {
+	storage  storage.HoldStorage
+	clock    Clock
+	interval time.Duration
+}
+
+func NewHoldExpirer(holdStorage storage.HoldStorage, clock Clock, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{storage: holdStorage, clock: clock, interval: interval}
+}
+
+func (expirer *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+	for {
+		if expired, err := expirer.ExpireDue(); err != nil {
+			log.Printf("Expiring the holds failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d holds", expired)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (expirer *HoldExpirer) ExpireDue() (int, error) {
+	return expirer.storage.ExpireHolds(expirer.clock.Now())
+}
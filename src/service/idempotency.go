@@ -0,0 +1,50 @@
+package service
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService interface {
+	Begin(user model.UserId, key string, requestHash string) (*model.IdempotencyRecord, error)
+	Complete(user model.UserId, key string, statusCode int, response []byte) error
+	Release(user model.UserId, key string) error
+}
+
+type RealIdempotencyService struct {
+	storage    storage.IdempotencyStorage
+	expiration time.Duration
+}
+
+func NewIdempotencyService(idempotencyStorage storage.IdempotencyStorage, expiration time.Duration) IdempotencyService {
+	return &RealIdempotencyService{storage: idempotencyStorage, expiration: expiration}
+}
+
+// Begin returns nil when the request has to be executed, and the completed record when its response has to be replayed
+func (service *RealIdempotencyService) Begin(user model.UserId, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, errors.NewValidationError("Idempotency-Key", "The idempotency key has to be at most 255 characters")
+	} else if record, err := service.storage.Reserve(user, key, requestHash, service.expiration); err != nil {
+		return nil, err
+	} else if record == nil {
+		return nil, nil
+	} else if record.RequestHash != requestHash {
+		return nil, &errors.IdempotencyKeyReuseError{Key: key}
+	} else if record.StatusCode == nil {
+		return nil, &errors.IdempotencyKeyInProgressError{Key: key}
+	} else {
+		return record, nil
+	}
+}
+
+func (service *RealIdempotencyService) Complete(user model.UserId, key string, statusCode int, response []byte) error {
+	return service.storage.Complete(user, key, statusCode, response)
+}
+
+func (service *RealIdempotencyService) Release(user model.UserId, key string) error {
+	return service.storage.Release(user, key)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// IdempotencyKeyExpirer deletes the expired idempotency keys, which are otherwise only overwritten when the same key is reused
+type IdempotencyKeyExpirer struct {
+	storage    storage.IdempotencyStorage
+	expiration time.Duration
+	interval   time.Duration
+}
+
+func NewIdempotencyKeyExpirer(idempotencyStorage storage.IdempotencyStorage, config *config.Idempotency) *IdempotencyKeyExpirer {
+	return &IdempotencyKeyExpirer{storage: idempotencyStorage, expiration: config.Expiration, interval: config.Interval}
+}
+
+func (expirer *IdempotencyKeyExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+	for {
+		if deleted, err := expirer.DeleteExpired(); err != nil {
+			log.Printf("Deleting the expired idempotency keys failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired idempotency keys", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (expirer *IdempotencyKeyExpirer) DeleteExpired() (int, error) {
+	return expirer.storage.DeleteExpired(expirer.expiration)
+}
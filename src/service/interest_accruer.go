@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// InterestAccruer accrues the interest of every finished day on the interest bearing accounts,
+// which capitalizes it at the end of every month. Accruing a day again changes nothing, so the server
+// and the accrue-interest command can both run it.
+type InterestAccruer struct {
+	storage  storage.InterestStorage
+	clock    Clock
+	interval time.Duration
+	dayCount model.DayCount
+}
+
+func NewInterestAccruer(interestStorage storage.InterestStorage, clock Clock, interestConfig *config.Interest) (*InterestAccruer, error) {
+	if dayCount := model.DayCount(interestConfig.DayCount); !dayCount.IsValid() {
+		return nil, fmt.Errorf("Unknown day count convention '%s'", interestConfig.DayCount)
+	} else {
+		return &InterestAccruer{storage: interestStorage, clock: clock, interval: interestConfig.Interval, dayCount: dayCount}, nil
+	}
+}
+
+func (accruer *InterestAccruer) Run(ctx context.Context) {
+	ticker := time.NewTicker(accruer.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := accruer.AccrueDue(); err != nil {
+			log.Printf("Accruing the interest failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AccrueDue accrues every day that has finished by the clock and has not been accrued yet
+func (accruer *InterestAccruer) AccrueDue() (int, error) {
+	return accruer.Accrue(model.Today(accruer.clock.Now()).AddDate(0, 0, -1))
+}
+
+// Accrue accrues every interest bearing account through the day, and returns on how many accounts it did.
+// An account catches up in order from the day after the last one accrued on it, so the days missed while nothing
+// accrued are not lost, and an account that has never accrued starts at the day itself.
+// A failed account does not stop the others, and the first failure is returned once all of them are done.
+func (accruer *InterestAccruer) Accrue(day time.Time) (int, error) {
+	day = model.Today(day)
+	if !day.Before(model.Today(accruer.clock.Now())) {
+		return 0, fmt.Errorf("The day %s has not finished yet", day.Format("2006-01-02"))
+	}
+	accounts, err := accruer.storage.ListInterestBearing()
+	if err != nil {
+		return 0, err
+	}
+	accrued := 0
+	var failure error
+	for i := range accounts {
+		if err := accruer.accrueThrough(&accounts[i], day); err != nil && failure == nil {
+			failure = err
+		} else if err == nil {
+			accrued++
+		}
+	}
+	return accrued, failure
+}
+
+// accrueThrough does not catch up the days before the rate was changed, which would accrue at the new rate.
+// It stops at the first day that fails, because every later day adds to the interest accrued by it.
+func (accruer *InterestAccruer) accrueThrough(account *model.Account, last time.Time) error {
+	first := last
+	if accrued, err := accruer.storage.LastAccruedDay(account.Id); err != nil {
+		return fmt.Errorf("account %d: %w", account.Id, err)
+	} else if accrued != nil {
+		first = accrued.AddDate(0, 0, 1)
+	}
+	if account.InterestSince != nil && first.Before(*account.InterestSince) {
+		first = *account.InterestSince
+	}
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if _, err := accruer.storage.AccrueInterest(account.Id, day, accruer.dayCount); err != nil {
+			return fmt.Errorf("account %d on %s: %w", account.Id, day.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
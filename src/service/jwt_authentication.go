@@ -0,0 +1,148 @@
+package service
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+)
+
+type JwtAuthenticationService struct {
+	secret    []byte
+	keys      map[string]*rsa.PublicKey
+	issuer    string
+	audience  string
+	userClaim string
+	parser    *jwt.Parser
+}
+
+func NewJwtAuthenticationService(jwtConfig *config.Jwt) (AuthenticationService, error) {
+	service := &JwtAuthenticationService{
+		keys:      make(map[string]*rsa.PublicKey),
+		issuer:    jwtConfig.Issuer,
+		audience:  jwtConfig.Audience,
+		userClaim: jwtConfig.UserClaim,
+	}
+	var methods []string
+	if jwtConfig.Secret != "" {
+		service.secret = []byte(jwtConfig.Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if jwtConfig.PublicKeyFile != "" {
+		if pem, err := os.ReadFile(jwtConfig.PublicKeyFile); err != nil {
+			return nil, err
+		} else if key, err := jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+			return nil, err
+		} else {
+			service.keys[""] = key
+		}
+	}
+	if jwtConfig.JwksFile != "" {
+		if err := service.loadJwks(jwtConfig.JwksFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(service.keys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("The jwt authentication requires a secret, a public key file or a jwks file")
+	} else if service.userClaim == "" {
+		return nil, fmt.Errorf("The jwt authentication requires a user claim")
+	}
+	service.parser = jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithJSONNumber())
+	return service, nil
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func (service *JwtAuthenticationService) loadJwks(path string) error {
+	var keySet jwks
+	if content, err := os.ReadFile(path); err != nil {
+		return err
+	} else if err := json.Unmarshal(content, &keySet); err != nil {
+		return err
+	}
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		} else if n, err := base64.RawURLEncoding.DecodeString(key.N); err != nil {
+			return fmt.Errorf("Invalid modulus of the jwks key '%s': %w", key.Kid, err)
+		} else if e, err := base64.RawURLEncoding.DecodeString(key.E); err != nil {
+			return fmt.Errorf("Invalid exponent of the jwks key '%s': %w", key.Kid, err)
+		} else {
+			service.keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		}
+	}
+	return nil
+}
+
+func (service *JwtAuthenticationService) GetUser(token string) (*model.UserId, error) {
+	claims := jwt.MapClaims{}
+	if _, err := service.parser.ParseWithClaims(token, claims, service.key); err != nil {
+		return nil, authError
+	} else if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, authError
+	} else if service.issuer != "" && !claims.VerifyIssuer(service.issuer, true) {
+		return nil, authError
+	} else if service.audience != "" && !claims.VerifyAudience(service.audience, true) {
+		return nil, authError
+	} else if userId, err := parseUserId(claims[service.userClaim]); err != nil {
+		return nil, authError
+	} else {
+		return &userId, nil
+	}
+}
+
+func (service *JwtAuthenticationService) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return service.secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, found := service.keys[kid]; found {
+			return key, nil
+		} else if key, found := service.keys[""]; found {
+			return key, nil
+		} else {
+			return nil, fmt.Errorf("Unknown key id '%s'", kid)
+		}
+	default:
+		return nil, fmt.Errorf("Unexpected signing method '%s'", token.Method.Alg())
+	}
+}
+
+func parseUserId(claim interface{}) (model.UserId, error) {
+	var id int64
+	var err error
+	switch value := claim.(type) {
+	case json.Number:
+		id, err = value.Int64()
+	case string:
+		id, err = strconv.ParseInt(value, 10, 64)
+	default:
+		err = fmt.Errorf("The user claim has an unexpected type %T", claim)
+	}
+	if err != nil {
+		return 0, err
+	} else if id <= 0 {
+		return 0, fmt.Errorf("The user id has to be positive")
+	} else {
+		return model.UserId(id), nil
+	}
+}
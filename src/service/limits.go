@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+)
+
+// NewLimits reads the default limits of every currency from the configuration
+func NewLimits(limitConfig config.Limits) (model.Limits, error) {
+	limits := make(model.Limits, len(limitConfig))
+	for code, currencyConfig := range limitConfig {
+		if currency := model.Currency(code); !currency.IsValid() {
+			return nil, fmt.Errorf("Unknown currency '%s' in the limits", code)
+		} else if currencyLimits, err := newCurrencyLimits(&currencyConfig); err != nil {
+			return nil, fmt.Errorf("Invalid limits of %s: %w", code, err)
+		} else {
+			limits[currency] = *currencyLimits
+		}
+	}
+	return limits, nil
+}
+
+func newCurrencyLimits(currencyConfig *config.CurrencyLimits) (limits *model.CurrencyLimits, err error) {
+	limits = &model.CurrencyLimits{}
+	if limits.Account.PerTransactionLimit, err = parseLimit("per_transaction", currencyConfig.PerTransaction); err != nil {
+		return nil, err
+	} else if limits.Account.DailyLimit, err = parseLimit("daily", currencyConfig.Daily); err != nil {
+		return nil, err
+	} else if limits.Account.MonthlyLimit, err = parseLimit("monthly", currencyConfig.Monthly); err != nil {
+		return nil, err
+	} else if limits.User.PerTransactionLimit, err = parseLimit("user_per_transaction", currencyConfig.UserPerTransaction); err != nil {
+		return nil, err
+	} else if limits.User.DailyLimit, err = parseLimit("user_daily", currencyConfig.UserDaily); err != nil {
+		return nil, err
+	} else if limits.User.MonthlyLimit, err = parseLimit("user_monthly", currencyConfig.UserMonthly); err != nil {
+		return nil, err
+	} else {
+		return limits, nil
+	}
+}
+
+// parseLimit leaves out a missing or zero limit, which does not apply
+func parseLimit(field, value string) (decimal.NullDecimal, error) {
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	} else if limit, err := decimal.NewFromString(value); err != nil {
+		return decimal.NullDecimal{}, fmt.Errorf("invalid %s: %w", field, err)
+	} else if limit.IsNegative() {
+		return decimal.NullDecimal{}, fmt.Errorf("the %s cannot be negative", field)
+	} else if limit.IsZero() {
+		return decimal.NullDecimal{}, nil
+	} else {
+		return decimal.NewNullDecimal(limit), nil
+	}
+}
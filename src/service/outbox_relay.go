@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// OutboxRelay publishes the events that the account changes wrote to the outbox. The events are published in the
+// order they were written, and a failed event holds back the later ones until it is published, which keeps the
+// events of every account in order.
+type OutboxRelay struct {
+	storage   storage.OutboxStorage
+	publisher EventPublisher
+	interval  time.Duration
+	batchSize int
+}
+
+func NewOutboxRelay(outboxStorage storage.OutboxStorage, publisher EventPublisher, eventsConfig *config.Events) *OutboxRelay {
+	return &OutboxRelay{storage: outboxStorage, publisher: publisher, interval: eventsConfig.Interval, batchSize: eventsConfig.BatchSize}
+}
+
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := relay.PublishPending(); err != nil {
+			log.Printf("Publishing the events failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes the pending events batch by batch until the outbox is empty or an event fails
+func (relay *OutboxRelay) PublishPending() (int, error) {
+	total := 0
+	for {
+		published, err := relay.storage.Publish(relay.batchSize, relay.publisher.Publish)
+		total += published
+		if err != nil || published < relay.batchSize {
+			return total, err
+		}
+	}
+}
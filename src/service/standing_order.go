@@ -0,0 +1,77 @@
+package service
+
+import (
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+)
+
+type StandingOrderService interface {
+	Create(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error)
+	Get(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error)
+	List(user model.UserId) ([]model.StandingOrder, error)
+	Update(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error)
+	Cancel(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error)
+}
+
+type RealStandingOrderService struct {
+	accounts AccountService
+	storage  storage.StandingOrderStorage
+	clock    Clock
+}
+
+func NewStandingOrderService(accountService AccountService, orderStorage storage.StandingOrderStorage, clock Clock) StandingOrderService {
+	return &RealStandingOrderService{accounts: accountService, storage: orderStorage, clock: clock}
+}
+
+func (service *RealStandingOrderService) Create(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error) {
+	today := model.Today(service.clock.Now())
+	if err := request.Validate(today); err != nil {
+		return nil, err
+	} else if _, err := service.accounts.Quote(request.TransferRequest(), user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.Create(request.StandingOrder(user, today))
+	}
+}
+
+func (service *RealStandingOrderService) Get(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error) {
+	if order, err := service.storage.Get(id); err != nil {
+		return nil, err
+	} else if order.Owner != user {
+		return nil, &errors.ForbiddenAccountAccessError{AccountId: order.From, UserId: user}
+	} else {
+		return order, nil
+	}
+}
+
+func (service *RealStandingOrderService) List(user model.UserId) ([]model.StandingOrder, error) {
+	return service.storage.ListByOwner(user)
+}
+
+// Update reactivates a suspended order. An order that has already run today does not run again today with the new terms.
+func (service *RealStandingOrderService) Update(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error) {
+	today := model.Today(service.clock.Now())
+	earliest := today
+	if err := request.Validate(today); err != nil {
+		return nil, err
+	} else if order, err := service.Get(request.Id, user); err != nil {
+		return nil, err
+	} else if _, err := service.accounts.Quote(request.TransferRequest(), user); err != nil {
+		return nil, err
+	} else {
+		if order.NextRunOn.After(today) {
+			earliest = today.AddDate(0, 0, 1)
+		}
+		return service.storage.Update(request.StandingOrder(user, earliest))
+	}
+}
+
+func (service *RealStandingOrderService) Cancel(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error) {
+	if _, err := service.Get(id, user); err != nil {
+		return nil, err
+	} else {
+		return service.storage.Cancel(id)
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// StandingOrderExecutor makes the due runs of the standing orders as transfer requests of their owners.
+// A failed run is retried after the retry interval, and the order is suspended when the retries are used up.
+// The runs missed while no executor was running are made up one after another.
+type StandingOrderExecutor struct {
+	accounts AccountService
+	storage  storage.StandingOrderStorage
+	clock    Clock
+	config   *config.StandingOrders
+}
+
+func NewStandingOrderExecutor(accountService AccountService, orderStorage storage.StandingOrderStorage, clock Clock,
+	orderConfig *config.StandingOrders) *StandingOrderExecutor {
+	return &StandingOrderExecutor{accounts: accountService, storage: orderStorage, clock: clock, config: orderConfig}
+}
+
+func (executor *StandingOrderExecutor) Run(ctx context.Context) {
+	ticker := time.NewTicker(executor.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := executor.ExecuteDue(); err != nil {
+			log.Printf("Executing the standing orders failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExecuteDue makes the runs due by now, and stops when the outcome of a run cannot be stored
+func (executor *StandingOrderExecutor) ExecuteDue() error {
+	now := executor.clock.Now()
+	for {
+		if found, err := executor.storage.ExecuteDue(now, func(order *model.StandingOrder) error {
+			return executor.execute(order, now)
+		}); err != nil {
+			return err
+		} else if !found {
+			return nil
+		}
+	}
+}
+
+// execute counts a server error as a failed run too, so that the order is retried later instead of holding up the others
+func (executor *StandingOrderExecutor) execute(order *model.StandingOrder, now time.Time) error {
+	origin := order.Origin()
+	request := &dto.TransferRequest{From: order.From, To: order.To, Amount: order.Amount, Convert: order.Convert, Origin: &origin}
+	if transfer, err := executor.accounts.Transfer(request, order.Owner); err == nil {
+		order.LastTransferId = &transfer.Id
+		order.Failures = 0
+		order.ErrorType = nil
+		order.ErrorMessage = nil
+		order.Reschedule(order.NextRunOn.AddDate(0, 0, 1))
+		return nil
+	} else {
+		errorType, message := errors.TypeName(err), err.Error()
+		order.Failures++
+		order.ErrorType = &errorType
+		order.ErrorMessage = &message
+		if order.Failures > executor.config.MaxRetries {
+			order.Status = model.SuspendedStandingOrder
+		} else {
+			order.DueAt = now.Add(executor.config.RetryInterval)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"log"
+	"time"
+)
+
+// TransferScheduler executes the due scheduled transfers as transfer requests of their owners.
+// Several replicas can run it at the same time, because the storage hands every due transfer to one of them only.
+// A transfer that fails with a server error is retried later with a growing delay, and it fails when the attempts are used up.
+type TransferScheduler struct {
+	accounts AccountService
+	storage  storage.ScheduledTransferStorage
+	config   *config.Scheduler
+}
+
+func NewTransferScheduler(accountService AccountService, scheduledStorage storage.ScheduledTransferStorage,
+	schedulerConfig *config.Scheduler) *TransferScheduler {
+	return &TransferScheduler{accounts: accountService, storage: scheduledStorage, config: schedulerConfig}
+}
+
+func (scheduler *TransferScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.config.Interval)
+	defer ticker.Stop()
+	for {
+		if err := scheduler.ExecuteDue(time.Now()); err != nil {
+			log.Printf("Executing the scheduled transfers failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExecuteDue executes the transfers due at the given time, and stops when the outcome of a transfer cannot be stored
+func (scheduler *TransferScheduler) ExecuteDue(now time.Time) error {
+	for {
+		if found, err := scheduler.storage.ExecuteDue(now, func(scheduled *model.ScheduledTransfer) error {
+			return scheduler.execute(scheduled, now)
+		}); err != nil {
+			return err
+		} else if !found {
+			return nil
+		}
+	}
+}
+
+// execute records why the transfer failed. A server error only counts as an attempt, and the transfer is due again
+// after the retry interval, which doubles with every attempt, until the attempts are used up.
+func (scheduler *TransferScheduler) execute(scheduled *model.ScheduledTransfer, now time.Time) error {
+	origin := scheduled.Origin()
+	request := &dto.TransferRequest{From: scheduled.From, To: scheduled.To, Amount: scheduled.Amount, Convert: scheduled.Convert, Origin: &origin}
+	if transfer, err := scheduler.accounts.Transfer(request, scheduled.Owner); err == nil {
+		scheduled.Status = model.ExecutedTransfer
+		scheduled.TransferId = &transfer.Id
+		return nil
+	} else if _, ok := err.(*errors.InternalServerError); ok && scheduled.Attempts+1 < scheduler.config.MaxAttempts {
+		scheduled.Attempts++
+		scheduled.ExecuteAt = now.Add(scheduler.config.RetryInterval << (scheduled.Attempts - 1))
+		log.Printf("Scheduled transfer %d failed, retrying at %s: %v", scheduled.Id, scheduled.ExecuteAt.Format(time.RFC3339), err)
+		return nil
+	} else {
+		errorType, message := errors.TypeName(err), err.Error()
+		scheduled.Attempts++
+		scheduled.Status = model.FailedTransfer
+		scheduled.ErrorType = &errorType
+		scheduled.ErrorMessage = &message
+		return nil
+	}
+}
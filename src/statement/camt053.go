@@ -0,0 +1,233 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"io"
+	"strconv"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+const (
+	camtDate     = "2006-01-02"
+	camtDateTime = "2006-01-02T15:04:05Z"
+)
+
+// camt053Codes are the ISO bank transaction codes of the entry types, as the domain, the family and the sub family
+var camt053Codes = map[model.LedgerEntryType][3]string{
+	model.TopUpEntry:       {"PMNT", "CNTR", "CDPT"},
+	model.TransferInEntry:  {"PMNT", "RCDT", "BOOK"},
+	model.TransferOutEntry: {"PMNT", "ICDT", "BOOK"},
+	model.InterestEntry:    {"ACMT", "MCOP", "INTR"},
+	model.OpeningEntry:     {"ACMT", "OTHR", "OTHR"},
+}
+
+// camt053Writer writes the statement as one camt.053.001.08 document. The balances come before the entries
+// in the schema, which is why the storage reads the closing balance before the first entry.
+type camt053Writer struct {
+	target    io.Writer
+	out       *xml.Encoder
+	statement *model.Statement
+}
+
+type camtAmount struct {
+	Currency model.Currency `xml:"Ccy,attr"`
+	Value    string         `xml:",chardata"`
+}
+
+type camtGroupHeader struct {
+	MessageId string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+type camtPeriod struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camtAccount struct {
+	Id       string         `xml:"Id>Othr>Id"`
+	Currency model.Currency `xml:"Ccy"`
+	Name     string         `xml:"Nm,omitempty"`
+}
+
+type camtBalance struct {
+	Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        string     `xml:"Dt>Dt"`
+}
+
+type camtEntry struct {
+	Reference         string              `xml:"NtryRef"`
+	Amount            camtAmount          `xml:"Amt"`
+	CreditDebit       string              `xml:"CdtDbtInd"`
+	Status            string              `xml:"Sts>Cd"`
+	BookingDate       string              `xml:"BookgDt>DtTm"`
+	ValueDate         string              `xml:"ValDt>Dt"`
+	ServicerReference string              `xml:"AcctSvcrRef,omitempty"`
+	TransactionCode   camtTransactionCode `xml:"BkTxCd"`
+	Charges           *camtCharges        `xml:"Chrgs"`
+	Transaction       camtTransaction     `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTransactionCode struct {
+	Domain      string `xml:"Domn>Cd"`
+	Family      string `xml:"Domn>Fmly>Cd"`
+	SubFamily   string `xml:"Domn>Fmly>SubFmlyCd"`
+	Proprietary string `xml:"Prtry>Cd"`
+}
+
+// camtCharges is the fee of the entry, which the amount of the entry includes
+type camtCharges struct {
+	Total       camtAmount `xml:"TtlChrgsAndTaxAmt"`
+	Amount      camtAmount `xml:"Rcrd>Amt"`
+	CreditDebit string     `xml:"Rcrd>CdtDbtInd"`
+	Included    bool       `xml:"Rcrd>ChrgInclInd"`
+}
+
+// camtTransaction is the movement without its fee, with the other account of a transfer
+type camtTransaction struct {
+	ServicerReference string       `xml:"Refs>AcctSvcrRef,omitempty"`
+	TransactionId     string       `xml:"Refs>TxId"`
+	Amount            camtAmount   `xml:"Amt"`
+	CreditDebit       string       `xml:"CdtDbtInd"`
+	Parties           *camtParties `xml:"RltdPties"`
+}
+
+// camtParties is the debtor account of money coming in, or the creditor account of money going out
+type camtParties struct {
+	DebtorAccount   *camtAccountId `xml:"DbtrAcct"`
+	CreditorAccount *camtAccountId `xml:"CdtrAcct"`
+}
+
+type camtAccountId struct {
+	Id string `xml:"Id>Othr>Id"`
+}
+
+func newCamt053Writer(out io.Writer) *camt053Writer {
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	return &camt053Writer{target: out, out: encoder}
+}
+
+// Begin leaves the statement element open after the balances, so that the entries can follow them
+func (writer *camt053Writer) Begin(statement *model.Statement) error {
+	writer.statement = statement
+	account := statement.Account
+	createdAt := statement.CreatedAt.UTC()
+	document := xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}}}
+	if _, err := io.WriteString(writer.target, xml.Header); err != nil {
+		return err
+	} else if err := writer.out.EncodeToken(document); err != nil {
+		return err
+	} else if err := writer.out.EncodeToken(camtElement("BkToCstmrStmt")); err != nil {
+		return err
+	} else if err := writer.element("GrpHdr", &camtGroupHeader{
+		MessageId: fmt.Sprintf("%d-%s", account.Id, createdAt.Format("20060102150405")),
+		CreatedAt: createdAt.Format(camtDateTime),
+	}); err != nil {
+		return err
+	} else if err := writer.out.EncodeToken(camtElement("Stmt")); err != nil {
+		return err
+	} else if err := writer.element("Id", fmt.Sprintf("%d-%s-%s", account.Id, statement.From.UTC().Format("20060102"), statement.To.UTC().Format("20060102"))); err != nil {
+		return err
+	} else if err := writer.element("CreDtTm", createdAt.Format(camtDateTime)); err != nil {
+		return err
+	} else if err := writer.element("FrToDt", &camtPeriod{From: statement.From.UTC().Format(camtDateTime), To: statement.To.UTC().Format(camtDateTime)}); err != nil {
+		return err
+	} else if err := writer.element("Acct", &camtAccount{Id: strconv.FormatInt(int64(account.Id), 10), Currency: account.Currency, Name: account.Name}); err != nil {
+		return err
+	} else if err := writer.balance("OPBD", statement.OpeningBalance, statement.From); err != nil {
+		return err
+	} else {
+		// the closing balance is dated on the last day of the period, which ends right before its end
+		return writer.balance("CLBD", statement.ClosingBalance, statement.To.Add(-1))
+	}
+}
+
+// Entry books the amount together with the fee, so that the entries add up from the opening to the closing balance
+func (writer *camt053Writer) Entry(entry *model.LedgerEntry) error {
+	currency := writer.statement.Account.Currency
+	booked := entry.Amount.Sub(entry.Fee)
+	codes, ok := camt053Codes[entry.Type]
+	if !ok {
+		codes = [3]string{"XTND", "NTAV", "NTAV"}
+	}
+	reference := strconv.FormatInt(int64(entry.Id), 10)
+	ntry := &camtEntry{
+		Reference:       reference,
+		Amount:          camtAmount{Currency: currency, Value: formatAmount(booked.Abs(), currency)},
+		CreditDebit:     creditDebit(booked),
+		Status:          "BOOK",
+		BookingDate:     entry.CreatedAt.UTC().Format(camtDateTime),
+		ValueDate:       entry.CreatedAt.UTC().Format(camtDate),
+		TransactionCode: camtTransactionCode{Domain: codes[0], Family: codes[1], SubFamily: codes[2], Proprietary: string(entry.Type)},
+		Transaction: camtTransaction{
+			TransactionId: reference,
+			Amount:        camtAmount{Currency: currency, Value: formatAmount(entry.Amount.Abs(), currency)},
+			CreditDebit:   creditDebit(entry.Amount),
+		},
+	}
+	if entry.JournalId != nil {
+		ntry.ServicerReference = strconv.FormatInt(int64(*entry.JournalId), 10)
+		ntry.Transaction.ServicerReference = ntry.ServicerReference
+	}
+	if entry.Fee.IsPositive() {
+		fee := camtAmount{Currency: currency, Value: formatAmount(entry.Fee, currency)}
+		ntry.Charges = &camtCharges{Total: fee, Amount: fee, CreditDebit: "DBIT", Included: true}
+	}
+	if entry.Counterparty != nil {
+		counterparty := &camtAccountId{Id: strconv.FormatInt(int64(*entry.Counterparty), 10)}
+		if entry.Amount.IsNegative() {
+			ntry.Transaction.Parties = &camtParties{CreditorAccount: counterparty}
+		} else {
+			ntry.Transaction.Parties = &camtParties{DebtorAccount: counterparty}
+		}
+	}
+	return writer.element("Ntry", ntry)
+}
+
+func (writer *camt053Writer) End() error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := writer.out.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := writer.out.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer.target, "\n")
+	return err
+}
+
+func (writer *camt053Writer) balance(code string, balance decimal.Decimal, at time.Time) error {
+	currency := writer.statement.Account.Currency
+	return writer.element("Bal", &camtBalance{
+		Code:        code,
+		Amount:      camtAmount{Currency: currency, Value: formatAmount(balance.Abs(), currency)},
+		CreditDebit: creditDebit(balance),
+		Date:        at.UTC().Format(camtDate),
+	})
+}
+
+func (writer *camt053Writer) element(name string, value interface{}) error {
+	return writer.out.EncodeElement(value, camtElement(name))
+}
+
+func camtElement(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// creditDebit tells the direction of an amount, because the amounts of camt.053 are never negative
+func creditDebit(amount decimal.Decimal) string {
+	if amount.IsNegative() {
+		return "DBIT"
+	} else {
+		return "CRDT"
+	}
+}
@@ -0,0 +1,57 @@
+package statement
+
+import (
+	"encoding/csv"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvWriter writes one row per entry between the opening and the closing balance rows, with the times in RFC 3339
+type csvWriter struct {
+	out       *csv.Writer
+	statement *model.Statement
+}
+
+func newCsvWriter(out io.Writer) *csvWriter {
+	return &csvWriter{out: csv.NewWriter(out)}
+}
+
+func (writer *csvWriter) Begin(statement *model.Statement) error {
+	writer.statement = statement
+	if err := writer.out.Write([]string{"date", "type", "counterparty", "amount", "fee", "balance"}); err != nil {
+		return err
+	} else {
+		return writer.balance(statement.From, "opening_balance", statement.OpeningBalance)
+	}
+}
+
+func (writer *csvWriter) Entry(entry *model.LedgerEntry) error {
+	currency := writer.statement.Account.Currency
+	counterparty := ""
+	if entry.Counterparty != nil {
+		counterparty = strconv.FormatInt(int64(*entry.Counterparty), 10)
+	}
+	return writer.out.Write([]string{
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		string(entry.Type),
+		counterparty,
+		formatAmount(entry.Amount, currency),
+		formatAmount(entry.Fee, currency),
+		formatAmount(entry.Balance, currency),
+	})
+}
+
+func (writer *csvWriter) End() error {
+	if err := writer.balance(writer.statement.To, "closing_balance", writer.statement.ClosingBalance); err != nil {
+		return err
+	}
+	writer.out.Flush()
+	return writer.out.Error()
+}
+
+func (writer *csvWriter) balance(at time.Time, rowType string, balance decimal.Decimal) error {
+	return writer.out.Write([]string{at.UTC().Format(time.RFC3339), rowType, "", "", "", formatAmount(balance, writer.statement.Account.Currency)})
+}
@@ -0,0 +1,69 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+	"io"
+	"time"
+)
+
+// jsonWriter writes one object, whose transactions are the same as the ones of the transactions endpoint
+type jsonWriter struct {
+	out       *bufio.Writer
+	statement *model.Statement
+	entries   int
+}
+
+type jsonHeader struct {
+	AccountId      model.AccountId `json:"account_id"`
+	Currency       model.Currency  `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance string          `json:"opening_balance"`
+}
+
+func newJsonWriter(out io.Writer) *jsonWriter {
+	return &jsonWriter{out: bufio.NewWriter(out)}
+}
+
+// Begin leaves the header object open, so that the transactions and the closing balance can be added to it
+func (writer *jsonWriter) Begin(statement *model.Statement) error {
+	writer.statement = statement
+	header, err := json.Marshal(&jsonHeader{
+		AccountId:      statement.Account.Id,
+		Currency:       statement.Account.Currency,
+		From:           statement.From,
+		To:             statement.To,
+		OpeningBalance: formatAmount(statement.OpeningBalance, statement.Account.Currency),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = writer.out.WriteString(string(header[:len(header)-1]) + `,"transactions":[`)
+	return err
+}
+
+func (writer *jsonWriter) Entry(entry *model.LedgerEntry) error {
+	transaction, err := json.Marshal(dto.TransactionFromModel(entry))
+	if err != nil {
+		return err
+	}
+	if writer.entries++; writer.entries > 1 {
+		transaction = append([]byte{','}, transaction...)
+	}
+	_, err = writer.out.Write(transaction)
+	return err
+}
+
+func (writer *jsonWriter) End() error {
+	closing, err := json.Marshal(formatAmount(writer.statement.ClosingBalance, writer.statement.Account.Currency))
+	if err != nil {
+		return err
+	} else if _, err := writer.out.WriteString(`],"closing_balance":` + string(closing) + "}\n"); err != nil {
+		return err
+	} else {
+		return writer.out.Flush()
+	}
+}
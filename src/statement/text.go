@@ -0,0 +1,66 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The columns have a fixed width, because aligning them to the widest value would need the whole period in memory
+const textRow = "%-19s  %-16s  %16s  %10s  %16s  %s"
+
+type textWriter struct {
+	out       *bufio.Writer
+	statement *model.Statement
+}
+
+func newTextWriter(out io.Writer) *textWriter {
+	return &textWriter{out: bufio.NewWriter(out)}
+}
+
+func (writer *textWriter) Begin(statement *model.Statement) error {
+	writer.statement = statement
+	title := fmt.Sprintf("Statement of the account %d in %s\n", statement.Account.Id, statement.Account.Currency)
+	if statement.Account.Name != "" {
+		title = fmt.Sprintf("Statement of the account %d (%s) in %s\n", statement.Account.Id, statement.Account.Name, statement.Account.Currency)
+	}
+	period := fmt.Sprintf("From %s until %s UTC\n\n", statement.From.UTC().Format(timeLayout), statement.To.UTC().Format(timeLayout))
+	if _, err := writer.out.WriteString(title + period); err != nil {
+		return err
+	} else if err := writer.row("Date", "Type", "Amount", "Fee", "Balance", "Counterparty"); err != nil {
+		return err
+	} else {
+		return writer.balance("Opening balance", statement.OpeningBalance)
+	}
+}
+
+func (writer *textWriter) Entry(entry *model.LedgerEntry) error {
+	currency := writer.statement.Account.Currency
+	counterparty := ""
+	if entry.Counterparty != nil {
+		counterparty = strconv.FormatInt(int64(*entry.Counterparty), 10)
+	}
+	return writer.row(entry.CreatedAt.UTC().Format(timeLayout), entry.Type,
+		formatAmount(entry.Amount, currency), formatAmount(entry.Fee, currency), formatAmount(entry.Balance, currency), counterparty)
+}
+
+func (writer *textWriter) End() error {
+	if err := writer.balance("Closing balance", writer.statement.ClosingBalance); err != nil {
+		return err
+	} else {
+		return writer.out.Flush()
+	}
+}
+
+func (writer *textWriter) balance(label string, balance decimal.Decimal) error {
+	return writer.row("", label, "", "", formatAmount(balance, writer.statement.Account.Currency), "")
+}
+
+func (writer *textWriter) row(columns ...interface{}) error {
+	_, err := writer.out.WriteString(strings.TrimRight(fmt.Sprintf(textRow, columns...), " ") + "\n")
+	return err
+}
@@ -0,0 +1,41 @@
+package statement
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+	"io"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// NewWriter returns the writer of the format, which writes every entry out as soon as its buffer fills up
+func NewWriter(format model.StatementFormat, out io.Writer) model.StatementWriter {
+	switch format {
+	case model.CsvStatement:
+		return newCsvWriter(out)
+	case model.TextStatement:
+		return newTextWriter(out)
+	case model.Camt053Statement:
+		return newCamt053Writer(out)
+	default:
+		return newJsonWriter(out)
+	}
+}
+
+func ContentType(format model.StatementFormat) string {
+	switch format {
+	case model.CsvStatement:
+		return "text/csv; charset=utf-8"
+	case model.TextStatement:
+		return "text/plain; charset=utf-8"
+	case model.Camt053Statement:
+		return "application/xml"
+	default:
+		return "application/json"
+	}
+}
+
+// formatAmount always shows all the minor units of the currency, as the accounting expects
+func formatAmount(amount decimal.Decimal, currency model.Currency) string {
+	return amount.StringFixed(currency.MinorUnits())
+}
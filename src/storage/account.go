@@ -7,13 +7,37 @@ import (
 	"github.com/shopspring/decimal"
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
+	"time"
 )
 
 type AccountStorage interface {
-	Create(owner model.UserId) (*model.Account, error)
+	Create(account *model.Account) (*model.Account, error)
 	Get(accountId model.AccountId) (*model.Account, error)
-	TopUp(accountId model.AccountId, amount decimal.Decimal) error
-	Transfer(from, to model.AccountId, amount decimal.Decimal) error
+	ListByOwner(owner model.UserId) ([]model.Account, error)
+	ListOverdrawn() ([]model.Account, error)
+	// TopUp charges the fee to the account right after crediting the amount
+	TopUp(accountId model.AccountId, amount, fee decimal.Decimal) (*model.LedgerEntry, error)
+	Transfer(transfer *model.Transfer) (*model.Transfer, error)
+	// TransferWithinLimits makes the transfer only when the source account and its owner stay within the limits
+	TransferWithinLimits(transfer *model.Transfer, limits model.Limits) (*model.Transfer, error)
+	// TransferAll makes all the transfers of one owner within the limits, or none of them.
+	// The error of the first transfer that fails is a BatchTransferError with its index.
+	TransferAll(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error)
+	// TransferLegs makes the transfers from one source account within the limits, or none of them, when the source
+	// covers their total. The error of the first transfer that fails is a BatchTransferError with its index.
+	TransferLegs(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error)
+	GetTransfer(transferId model.TransferId) (*model.Transfer, error)
+	// GetTransferByOrigin returns nil when no transfer has been made for the origin
+	GetTransferByOrigin(origin string) (*model.Transfer, error)
+	Reverse(transferId model.TransferId, partial bool) (*model.Transfer, error)
+	SetStatus(accountId model.AccountId, status model.AccountStatus) (*model.Account, error)
+	// SetOverdraftLimit does not touch the balance, so an account can be left overdrawn beyond a lowered limit
+	SetOverdraftLimit(accountId model.AccountId, limit decimal.Decimal) (*model.Account, error)
+	SetTransferLimits(accountId model.AccountId, limits model.TransferLimits) (*model.Account, error)
+	// SetInterestRate applies from today, and keeps the interest accrued so far. A changed rate is never applied to the earlier days
+	// that have not been accrued yet, which are skipped instead.
+	SetInterestRate(accountId model.AccountId, rate decimal.Decimal) (*model.Account, error)
+	Close(accountId model.AccountId, sweepTo *model.AccountId) (*model.Account, error)
 }
 
 const uniqueConstraintErrorCode = pq.ErrorCode("23505")
@@ -26,28 +50,92 @@ func NewPostgresAccountStorage(db *sqlx.DB) AccountStorage {
 	return &PostgresAccountStorage{db}
 }
 
-func (storage *PostgresAccountStorage) Create(owner model.UserId) (*model.Account, error) {
-	newId := model.AccountId(-1)
-	if err := storage.db.Get(&newId, "INSERT INTO accounts (owner_id) VALUES ($1) RETURNING id", owner); err == nil {
-		return &model.Account{Id: newId, Owner: owner, Balance: decimal.NewFromInt(0)}, nil
-	} else if pgErr := err.(*pq.Error); pgErr.Code == uniqueConstraintErrorCode {
-		return nil, &errors.DuplicateAccountError{UserId: owner}
+func (storage *PostgresAccountStorage) Create(account *model.Account) (*model.Account, error) {
+	created := *account
+	created.Status = model.ActiveAccount
+	created.Balance = decimal.NewFromInt(0)
+	created.Held = decimal.NewFromInt(0)
+	created.OverdraftLimit = decimal.NewFromInt(0)
+	created.InterestRate = decimal.NewFromInt(0)
+	created.AccruedInterest = decimal.NewFromInt(0)
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if err := tx.Get(&created.Id, "INSERT INTO accounts (owner_id, name, type, currency) VALUES ($1, $2, $3, $4) RETURNING id",
+			account.Owner, account.Name, account.Type, account.Currency); err != nil {
+			return err
+		} else {
+			return insertEvent(tx, created.Id, model.NewAccountCreated(&created))
+		}
+	})
+	if err == nil {
+		return &created, nil
+	} else if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == uniqueConstraintErrorCode {
+		return nil, &errors.DuplicateAccountError{UserId: account.Owner, Name: account.Name}
+	} else if _, ok := err.(*errors.InternalServerError); ok {
+		return nil, err
 	} else {
-		return nil, &errors.InternalServerError{Err: pgErr}
+		return nil, &errors.InternalServerError{Err: err}
 	}
 }
 
 func (storage *PostgresAccountStorage) Get(accountId model.AccountId) (account *model.Account, err error) {
-	storage.executeInTransaction(func(tx *sqlx.Tx) error {
+	executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
 		account, err = storage.get(tx, accountId)
 		return err
 	})
 	return
 }
 
+func (storage *PostgresAccountStorage) ListByOwner(owner model.UserId) ([]model.Account, error) {
+	accounts := make([]model.Account, 0)
+	if err := storage.db.Select(&accounts, "SELECT * FROM accounts WHERE owner_id = $1 ORDER BY id", owner); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return accounts, nil
+	}
+}
+
+func (storage *PostgresAccountStorage) ListOverdrawn() ([]model.Account, error) {
+	accounts := make([]model.Account, 0)
+	if err := storage.db.Select(&accounts, "SELECT * FROM accounts WHERE balance < 0 AND type <> $1 ORDER BY id", model.SystemAccount); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return accounts, nil
+	}
+}
+
 func (storage *PostgresAccountStorage) get(tx *sqlx.Tx, accountId model.AccountId) (*model.Account, error) {
+	return storage.selectAccount(tx, "SELECT * FROM accounts WHERE id=$1", accountId)
+}
+
+func (storage *PostgresAccountStorage) lock(tx *sqlx.Tx, accountId model.AccountId) (*model.Account, error) {
+	return storage.selectAccount(tx, "SELECT * FROM accounts WHERE id=$1 FOR UPDATE", accountId)
+}
+
+// lockAccounts locks the existing accounts ordered by id, so that concurrent transactions over the same accounts cannot deadlock.
+// The system accounts are locked after the customer ones, because they take part in most of the transactions.
+func lockAccounts(tx *sqlx.Tx, accountIds ...model.AccountId) (map[model.AccountId]*model.Account, error) {
+	accounts := make([]model.Account, 0, len(accountIds))
+	if err := tx.Select(&accounts, "SELECT * FROM accounts WHERE id = ANY($1) ORDER BY type = $2, id FOR UPDATE", pq.Array(accountIds), model.SystemAccount); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	}
+	locked := make(map[model.AccountId]*model.Account, len(accounts))
+	for i := range accounts {
+		locked[accounts[i].Id] = &accounts[i]
+	}
+	return locked, nil
+}
+
+func lockedAccount(locked map[model.AccountId]*model.Account, accountId model.AccountId) (*model.Account, error) {
+	if account, ok := locked[accountId]; ok {
+		return account, nil
+	} else {
+		return nil, &errors.AccountDoesNotExistError{AccountId: accountId}
+	}
+}
+
+func (storage *PostgresAccountStorage) selectAccount(tx *sqlx.Tx, query string, accountId model.AccountId) (*model.Account, error) {
 	account := &model.Account{}
-	if err := tx.Get(account, "SELECT * FROM accounts WHERE id=$1", accountId); err == nil {
+	if err := tx.Get(account, query, accountId); err == nil {
 		return account, nil
 	} else if err == sql.ErrNoRows {
 		return nil, &errors.AccountDoesNotExistError{AccountId: accountId}
@@ -56,52 +144,489 @@ func (storage *PostgresAccountStorage) get(tx *sqlx.Tx, accountId model.AccountI
 	}
 }
 
-func (storage *PostgresAccountStorage) TopUp(accountId model.AccountId, amount decimal.Decimal) error {
-	if result, err := storage.db.Exec("UPDATE accounts SET balance = balance + $2 WHERE id = $1", accountId, amount); err != nil {
-		return &errors.InternalServerError{Err: err}
-	} else if rowsAffected, err := result.RowsAffected(); err != nil {
+func (storage *PostgresAccountStorage) TopUp(accountId model.AccountId, amount, fee decimal.Decimal) (*model.LedgerEntry, error) {
+	entry := &model.LedgerEntry{AccountId: accountId, Type: model.TopUpEntry, Amount: amount, Fee: fee}
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if account, err := storage.lock(tx, accountId); err != nil {
+			return err
+		} else if err := errors.CheckActive(account); err != nil {
+			return err
+		} else if clearingId, err := systemAccount(tx, model.CashInClearingAccount, account.Currency); err != nil {
+			return err
+		} else if fees, err := feePostings(tx, accountId, account.Currency, fee); err != nil {
+			return err
+		} else if journal, balances, err := postJournal(tx, model.TopUpJournal, append([]model.Posting{
+			{AccountId: clearingId, Currency: account.Currency, Amount: amount.Neg()},
+			{AccountId: accountId, Currency: account.Currency, Amount: amount},
+		}, fees...)...); err != nil {
+			return err
+		} else {
+			entry.JournalId = &journal.Id
+			entry.Balance = balanceAfter(journal, balances, accountId)
+			if err := insertLedgerEntry(tx, entry); err != nil {
+				return err
+			} else {
+				return insertEvent(tx, accountId, model.NewAccountToppedUp(entry, account.Currency))
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (storage *PostgresAccountStorage) Transfer(transfer *model.Transfer) (*model.Transfer, error) {
+	created := *transfer
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if locked, err := lockAccounts(tx, transfer.From, transfer.To); err != nil {
+			return err
+		} else if fromAccount, err := lockedAccount(locked, transfer.From); err != nil {
+			return err
+		} else if err := errors.CheckActive(fromAccount); err != nil {
+			return err
+		} else {
+			return storage.transfer(tx, &created, locked)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// TransferWithinLimits locks the owner before the accounts, so that the concurrent transfers of a user are counted one after another
+func (storage *PostgresAccountStorage) TransferWithinLimits(transfer *model.Transfer, limits model.Limits) (*model.Transfer, error) {
+	created := *transfer
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if err := lockOwnerOf(tx, transfer.From); err != nil {
+			return err
+		} else {
+			return storage.transferWithinLimits(tx, &created, limits)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// TransferAll locks all the accounts up front, and reads them again before every transfer to see the ones made before it
+func (storage *PostgresAccountStorage) TransferAll(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	var created []model.Transfer
+	accountIds := make([]model.AccountId, 0, 2*len(transfers))
+	for _, transfer := range transfers {
+		accountIds = append(accountIds, transfer.From, transfer.To)
+	}
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		created = make([]model.Transfer, len(transfers))
+		if len(transfers) == 0 {
+			return nil
+		} else if err := lockOwnerOf(tx, transfers[0].From); err != nil {
+			return &errors.BatchTransferError{Index: 0, Err: err}
+		} else if _, err := lockAccounts(tx, accountIds...); err != nil {
+			return err
+		}
+		for i, transfer := range transfers {
+			created[i] = *transfer
+			if err := storage.transferWithinLimits(tx, &created[i], limits); err != nil {
+				return batchTransferError(i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// TransferLegs locks the source account once for all the legs, and keeps the locked row up to date after every leg
+func (storage *PostgresAccountStorage) TransferLegs(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	var created []model.Transfer
+	accountIds := make([]model.AccountId, 0, len(transfers)+1)
+	for _, transfer := range transfers {
+		accountIds = append(accountIds, transfer.To)
+	}
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		created = make([]model.Transfer, len(transfers))
+		if len(transfers) == 0 {
+			return nil
+		}
+		from := transfers[0].From
+		if err := lockOwnerOf(tx, from); err != nil {
+			return err
+		} else if locked, err := lockAccounts(tx, append(accountIds, from)...); err != nil {
+			return err
+		} else if source, err := lockedAccount(locked, from); err != nil {
+			return err
+		} else if err := errors.CheckActive(source); err != nil {
+			return err
+		} else if total := totalDebit(transfers); source.Available().LessThan(total) {
+			return errors.NewBalanceTooLowError(source, total)
+		} else {
+			for i, transfer := range transfers {
+				created[i] = *transfer
+				if err := storage.transferLeg(tx, &created[i], source, locked, limits); err != nil {
+					return batchTransferError(i, err)
+				}
+			}
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (storage *PostgresAccountStorage) transferLeg(tx *sqlx.Tx, transfer *model.Transfer, source *model.Account,
+	locked map[model.AccountId]*model.Account, limits model.Limits) error {
+	if accountUsage, userUsage, err := transferUsage(tx, source); err != nil {
+		return err
+	} else if err := checkLimits(source, transfer.Amount, limits, accountUsage, userUsage); err != nil {
+		return err
+	} else if err := storage.transfer(tx, transfer, locked); err != nil {
+		return err
+	} else {
+		source.Balance = transfer.Balance
+		return nil
+	}
+}
+
+// batchTransferError leaves the errors that make the transaction retry as they are
+func batchTransferError(index int, err error) error {
+	if isRetryable(err) {
+		return err
+	} else {
+		return &errors.BatchTransferError{Index: index, Err: err}
+	}
+}
+
+// totalDebit is what the transfers take from their source accounts together with their fees
+func totalDebit(transfers []*model.Transfer) decimal.Decimal {
+	total := decimal.Zero
+	for _, transfer := range transfers {
+		total = total.Add(transfer.Amount).Add(transfer.Fee)
+	}
+	return total
+}
+
+func (storage *PostgresAccountStorage) transferWithinLimits(tx *sqlx.Tx, transfer *model.Transfer, limits model.Limits) error {
+	if locked, err := lockAccounts(tx, transfer.From, transfer.To); err != nil {
+		return err
+	} else if fromAccount, err := lockedAccount(locked, transfer.From); err != nil {
+		return err
+	} else if err := errors.CheckActive(fromAccount); err != nil {
+		return err
+	} else if accountUsage, userUsage, err := transferUsage(tx, fromAccount); err != nil {
+		return err
+	} else if err := checkLimits(fromAccount, transfer.Amount, limits, accountUsage, userUsage); err != nil {
+		return err
+	} else {
+		return storage.transfer(tx, transfer, locked)
+	}
+}
+
+func (storage *PostgresAccountStorage) GetTransfer(transferId model.TransferId) (*model.Transfer, error) {
+	if transfer, err := storage.getTransfer("t.id = $1", transferId); err == sql.ErrNoRows {
+		return nil, &errors.TransferDoesNotExistError{TransferId: transferId}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return transfer, nil
+	}
+}
+
+func (storage *PostgresAccountStorage) GetTransferByOrigin(origin string) (*model.Transfer, error) {
+	if transfer, err := storage.getTransfer("t.origin = $1", origin); err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return transfer, nil
+	}
+}
+
+// getTransfer reads the balance of the source account from its ledger entry of the transfer
+func (storage *PostgresAccountStorage) getTransfer(condition string, arg interface{}) (*model.Transfer, error) {
+	transfer := &model.Transfer{}
+	err := storage.db.Get(transfer, "SELECT t.*, e.balance, r.id AS reversed_by FROM transfers t "+
+		"JOIN ledger_entries e ON e.journal_id = t.journal_id AND e.account_id = t.from_id AND e.type = $2 "+
+		"LEFT JOIN transfers r ON r.reversal_of = t.id WHERE "+condition, arg, model.TransferOutEntry)
+	return transfer, err
+}
+
+func (storage *PostgresAccountStorage) Reverse(transferId model.TransferId, partial bool) (reversal *model.Transfer, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		var reversalId model.TransferId
+		original := &model.Transfer{}
+		// Locking the original transfer serializes the concurrent reversals of it
+		if err := tx.Get(original, "SELECT * FROM transfers WHERE id = $1 FOR UPDATE", transferId); err == sql.ErrNoRows {
+			return &errors.TransferDoesNotExistError{TransferId: transferId}
+		} else if err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if err := tx.Get(&reversalId, "SELECT id FROM transfers WHERE reversal_of = $1", transferId); err == nil {
+			return &errors.TransferAlreadyReversedError{TransferId: transferId, ReversalId: reversalId}
+		} else if err != sql.ErrNoRows {
+			return &errors.InternalServerError{Err: err}
+		} else if locked, err := lockAccounts(tx, original.To, original.From); err != nil {
+			return err
+		} else if receiver, err := lockedAccount(locked, original.To); err != nil {
+			return err
+		} else if err := errors.CheckActive(receiver); err != nil {
+			return err
+		} else if reversal, err = reversalOf(original, receiver.Available(), locked[original.From].Currency, partial); err != nil {
+			return err
+		} else {
+			return storage.transfer(tx, reversal, locked)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// reversalOf moves the credited amount back to the source account at the inverse rate of the original transfer.
+// When the receiver has spent part of it, a partial reversal returns what is left and credits the proportional part of the original amount.
+func reversalOf(original *model.Transfer, available decimal.Decimal, fromCurrency model.Currency, partial bool) (*model.Transfer, error) {
+	reversal := &model.Transfer{
+		From:         original.To,
+		To:           original.From,
+		Amount:       original.CreditAmount,
+		CreditAmount: original.Amount,
+		ReversalOf:   &original.Id,
+	}
+	if original.FxRate.Valid {
+		reversal.FxRate = decimal.NullDecimal{Decimal: decimal.NewFromInt(1).Div(original.FxRate.Decimal), Valid: true}
+	}
+	shortfall := reversal.Amount.Sub(available)
+	if !shortfall.IsPositive() {
+		return reversal, nil
+	} else if !partial || !available.IsPositive() {
+		return nil, &errors.BalanceTooLowError{AccountId: original.To, Shortfall: shortfall}
+	}
+	reversal.Amount = available
+	reversal.CreditAmount = fromCurrency.Round(original.Amount.Mul(available).Div(original.CreditAmount))
+	if !reversal.CreditAmount.IsPositive() {
+		return nil, &errors.BalanceTooLowError{AccountId: original.To, Shortfall: shortfall}
+	} else {
+		return reversal, nil
+	}
+}
+
+// transfer moves the money between the accounts, which have to be locked by the caller, and records the transfer
+func (storage *PostgresAccountStorage) transfer(tx *sqlx.Tx, transfer *model.Transfer, locked map[model.AccountId]*model.Account) error {
+	fromAccount := locked[transfer.From]
+	if debited := transfer.Amount.Add(transfer.Fee); fromAccount.Available().LessThan(debited) {
+		return errors.NewBalanceTooLowError(fromAccount, debited)
+	} else if toAccount, err := lockedAccount(locked, transfer.To); err != nil {
+		return err
+	} else if err := errors.CheckActive(toAccount); err != nil {
+		return err
+	} else if postings, err := transferPostings(tx, transfer, fromAccount.Currency, toAccount.Currency); err != nil {
+		return err
+	} else if fees, err := feePostings(tx, transfer.From, fromAccount.Currency, transfer.Fee); err != nil {
+		return err
+	} else if journal, balances, err := postJournal(tx, transfer.JournalType(), append(postings, fees...)...); err != nil {
+		return err
+	} else if err := insertTransfer(tx, transfer, journal.Id); err != nil {
+		return err
+	} else if err := insertLedgerEntry(tx, &model.LedgerEntry{
+		AccountId:    transfer.From,
+		JournalId:    &journal.Id,
+		Type:         model.TransferOutEntry,
+		Amount:       transfer.Amount.Neg(),
+		Balance:      balanceAfter(journal, balances, transfer.From),
+		Counterparty: &transfer.To,
+		FxRate:       transfer.FxRate,
+		Fee:          transfer.Fee,
+	}); err != nil {
+		return err
+	} else if err := insertLedgerEntry(tx, &model.LedgerEntry{
+		AccountId:    transfer.To,
+		JournalId:    &journal.Id,
+		Type:         model.TransferInEntry,
+		Amount:       transfer.CreditAmount,
+		Balance:      balanceAfter(journal, balances, transfer.To),
+		Counterparty: &transfer.From,
+		FxRate:       transfer.FxRate,
+	}); err != nil {
+		return err
+	} else {
+		transfer.Balance = balanceAfter(journal, balances, transfer.From)
+		return insertEvent(tx, transfer.From, model.NewMoneyTransferred(transfer, fromAccount.Currency, toAccount.Currency))
+	}
+}
+
+func insertTransfer(tx *sqlx.Tx, transfer *model.Transfer, journalId model.JournalId) error {
+	transfer.JournalId = journalId
+	if err := tx.QueryRowx("INSERT INTO transfers (journal_id, from_id, to_id, amount, credit_amount, fx_rate, fee, reversal_of, origin) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at",
+		journalId, transfer.From, transfer.To, transfer.Amount, transfer.CreditAmount, transfer.FxRate, transfer.Fee, transfer.ReversalOf, transfer.Origin,
+	).Scan(&transfer.Id, &transfer.CreatedAt); err != nil {
 		return &errors.InternalServerError{Err: err}
-	} else if rowsAffected == 0 {
-		return &errors.AccountDoesNotExistError{AccountId: accountId}
 	} else {
 		return nil
 	}
 }
 
-func (storage *PostgresAccountStorage) Transfer(from, to model.AccountId, amount decimal.Decimal) error {
-	return storage.executeInTransaction(func(tx *sqlx.Tx) error {
-		if _, err := storage.get(tx, from); err != nil {
+// transferPostings debits the source account first and credits the target account last.
+// A conversion goes through the FX accounts, so that the postings of each currency stay balanced.
+func transferPostings(tx *sqlx.Tx, transfer *model.Transfer, fromCurrency, toCurrency model.Currency) ([]model.Posting, error) {
+	debit := model.Posting{AccountId: transfer.From, Currency: fromCurrency, Amount: transfer.Amount.Neg()}
+	credit := model.Posting{AccountId: transfer.To, Currency: toCurrency, Amount: transfer.CreditAmount}
+	if fromCurrency == toCurrency {
+		return []model.Posting{debit, credit}, nil
+	} else if fromFxId, err := systemAccount(tx, model.FxAccount, fromCurrency); err != nil {
+		return nil, err
+	} else if toFxId, err := systemAccount(tx, model.FxAccount, toCurrency); err != nil {
+		return nil, err
+	} else {
+		return []model.Posting{
+			debit,
+			{AccountId: fromFxId, Currency: fromCurrency, Amount: transfer.Amount},
+			{AccountId: toFxId, Currency: toCurrency, Amount: transfer.CreditAmount.Neg()},
+			credit,
+		}, nil
+	}
+}
+
+func (storage *PostgresAccountStorage) SetStatus(accountId model.AccountId, status model.AccountStatus) (account *model.Account, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if account, err = storage.lock(tx, accountId); err != nil {
 			return err
-		} else if decreaseResult, err := tx.Exec("UPDATE accounts SET balance = balance - $2 WHERE id = $1 AND balance >= $2", from, amount); err != nil {
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if _, err := tx.Exec("UPDATE accounts SET status = $2 WHERE id = $1", accountId, status); err != nil {
 			return &errors.InternalServerError{Err: err}
-		} else if decreaseBalanceRows, err := decreaseResult.RowsAffected(); err != nil {
-			return &errors.InternalServerError{Err: err}
-		} else if decreaseBalanceRows == 0 {
-			return &errors.BalanceTooLowError{AccountId: from}
-		} else if increaseResult, err := tx.Exec("UPDATE accounts SET balance = balance + $2 WHERE id = $1", to, amount); err != nil {
+		} else {
+			account.Status = status
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresAccountStorage) SetOverdraftLimit(accountId model.AccountId, limit decimal.Decimal) (account *model.Account, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if account, err = storage.lock(tx, accountId); err != nil {
+			return err
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if _, err := tx.Exec("UPDATE accounts SET overdraft_limit = $2 WHERE id = $1", accountId, limit); err != nil {
 			return &errors.InternalServerError{Err: err}
-		} else if increaseBalanceRows, err := increaseResult.RowsAffected(); err != nil {
+		} else {
+			account.OverdraftLimit = limit
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresAccountStorage) SetTransferLimits(accountId model.AccountId, limits model.TransferLimits) (account *model.Account, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if account, err = storage.lock(tx, accountId); err != nil {
+			return err
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if _, err := tx.Exec("UPDATE accounts SET per_transaction_limit = $2, daily_limit = $3, monthly_limit = $4 WHERE id = $1",
+			accountId, limits.PerTransactionLimit, limits.DailyLimit, limits.MonthlyLimit); err != nil {
 			return &errors.InternalServerError{Err: err}
-		} else if increaseBalanceRows == 0 {
-			return &errors.AccountDoesNotExistError{AccountId: to}
 		} else {
+			account.TransferLimits = limits
 			return nil
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	return
 }
 
-func (storage *PostgresAccountStorage) executeInTransaction(f func(*sqlx.Tx) error) error {
-	if tx, err := storage.db.Beginx(); err != nil {
-		return err
-	} else if err := f(tx); err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return &errors.InternalServerError{Err: rollbackErr}
+func (storage *PostgresAccountStorage) SetInterestRate(accountId model.AccountId, rate decimal.Decimal) (account *model.Account, err error) {
+	since := model.Today(time.Now())
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if account, err = storage.lock(tx, accountId); err != nil {
+			return err
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if rate.Equal(account.InterestRate) {
+			return nil
+		} else if _, err := tx.Exec("UPDATE accounts SET interest_rate = $2, interest_rate_since = $3 WHERE id = $1",
+			accountId, rate, since); err != nil {
+			return &errors.InternalServerError{Err: err}
 		} else {
+			account.InterestRate = rate
+			account.InterestSince = &since
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Close refuses an account with active holds, and cancels the pending scheduled transfers and the standing orders
+// from or to the account, which could only fail once it is closed
+func (storage *PostgresAccountStorage) Close(accountId model.AccountId, sweepTo *model.AccountId) (account *model.Account, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		lockedIds := []model.AccountId{accountId}
+		if sweepTo != nil {
+			lockedIds = append(lockedIds, *sweepTo)
+		}
+		var activeHolds int
+		if locked, err := lockAccounts(tx, lockedIds...); err != nil {
+			return err
+		} else if account, err = lockedAccount(locked, accountId); err != nil {
 			return err
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if err := tx.Get(&activeHolds, "SELECT count(*) FROM holds WHERE (from_id = $1 OR to_id = $1) AND status = $2",
+			accountId, model.ActiveHold); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if activeHolds > 0 {
+			return &errors.ActiveHoldsError{AccountId: accountId}
+		} else if err := storage.sweep(tx, account, sweepTo, locked); err != nil {
+			return err
+		} else if _, err := tx.Exec("UPDATE scheduled_transfers SET status = $2 WHERE (from_id = $1 OR to_id = $1) AND status = $3",
+			accountId, model.CancelledTransfer, model.PendingTransfer); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if _, err := tx.Exec("UPDATE standing_orders SET status = $2 WHERE (from_id = $1 OR to_id = $1) AND status IN ($3, $4)",
+			accountId, model.CancelledStandingOrder, model.ActiveStandingOrder, model.SuspendedStandingOrder); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if _, err := tx.Exec("UPDATE accounts SET status = $2 WHERE id = $1", accountId, model.ClosedAccount); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else {
+			account.Status = model.ClosedAccount
+			account.Balance = decimal.NewFromInt(0)
+			return nil
 		}
-	} else if commitErr := tx.Commit(); commitErr != nil {
-		return &errors.InternalServerError{Err: commitErr}
-	} else {
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresAccountStorage) sweep(tx *sqlx.Tx, account *model.Account, sweepTo *model.AccountId, locked map[model.AccountId]*model.Account) error {
+	if account.Balance.IsZero() {
 		return nil
+	} else if sweepTo == nil || account.Balance.IsNegative() {
+		return &errors.NonZeroBalanceError{AccountId: account.Id}
+	} else if target, err := lockedAccount(locked, *sweepTo); err != nil {
+		return err
+	} else if target.Currency != account.Currency {
+		return &errors.CurrencyMismatchError{From: account.Id, To: target.Id, FromCurrency: account.Currency, ToCurrency: target.Currency}
+	} else {
+		return storage.transfer(tx, model.NewTransfer(account.Id, target.Id, account.Balance), locked)
 	}
 }
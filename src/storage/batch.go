@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+type BatchStorage interface {
+	// Create stores the batch with its lines, and sets the batch id of the lines
+	Create(batch *model.Batch) (*model.Batch, error)
+	Get(id model.BatchId) (*model.Batch, error)
+	// Finish stores the status of the batch and the results of its lines
+	Finish(batch *model.Batch) (*model.Batch, error)
+}
+
+type PostgresBatchStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresBatchStorage(db *sqlx.DB) BatchStorage {
+	return &PostgresBatchStorage{db}
+}
+
+func (storage *PostgresBatchStorage) Create(batch *model.Batch) (created *model.Batch, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		created = &model.Batch{}
+		if err := tx.Get(created, "INSERT INTO batches (owner_id, mode, status) VALUES ($1, $2, $3) RETURNING *",
+			batch.Owner, batch.Mode, batch.Status); err != nil {
+			return &errors.InternalServerError{Err: err}
+		}
+		created.Lines = make([]model.BatchLine, len(batch.Lines))
+		for i := range batch.Lines {
+			created.Lines[i] = batch.Lines[i]
+			created.Lines[i].BatchId = created.Id
+			if _, err := tx.NamedExec("INSERT INTO batch_lines "+
+				"(batch_id, line, reference, from_id, to_id, amount, status, transfer_id, error_type, error_message) "+
+				"VALUES (:batch_id, :line, :reference, :from_id, :to_id, :amount, :status, :transfer_id, :error_type, :error_message)",
+				&created.Lines[i]); err != nil {
+				return &errors.InternalServerError{Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresBatchStorage) Get(id model.BatchId) (*model.Batch, error) {
+	batch := &model.Batch{}
+	if err := storage.db.Get(batch, "SELECT * FROM batches WHERE id = $1", id); err == sql.ErrNoRows {
+		return nil, &errors.BatchDoesNotExistError{BatchId: id}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	}
+	batch.Lines = make([]model.BatchLine, 0)
+	if err := storage.db.Select(&batch.Lines, "SELECT * FROM batch_lines WHERE batch_id = $1 ORDER BY line", id); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return batch, nil
+	}
+}
+
+func (storage *PostgresBatchStorage) Finish(batch *model.Batch) (*model.Batch, error) {
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if result, err := tx.Exec("UPDATE batches SET status = $2 WHERE id = $1", batch.Id, batch.Status); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if updated, err := result.RowsAffected(); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if updated == 0 {
+			return &errors.BatchDoesNotExistError{BatchId: batch.Id}
+		}
+		for i := range batch.Lines {
+			if _, err := tx.NamedExec("UPDATE batch_lines SET status = :status, transfer_id = :transfer_id, "+
+				"error_type = :error_type, error_message = :error_message WHERE batch_id = :batch_id AND line = :line",
+				&batch.Lines[i]); err != nil {
+				return &errors.InternalServerError{Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storage.Get(batch.Id)
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/model"
+)
+
+// feePostings move the fee from the account to the fees account of its currency, and there are none when there is no fee
+func feePostings(tx *sqlx.Tx, accountId model.AccountId, currency model.Currency, fee decimal.Decimal) ([]model.Posting, error) {
+	if !fee.IsPositive() {
+		return nil, nil
+	} else if feesId, err := systemAccount(tx, model.FeesAccount, currency); err != nil {
+		return nil, err
+	} else {
+		return chargeFee(accountId, feesId, currency, fee), nil
+	}
+}
+
+func chargeFee(accountId, feesId model.AccountId, currency model.Currency, fee decimal.Decimal) []model.Posting {
+	return []model.Posting{
+		{AccountId: accountId, Currency: currency, Amount: fee.Neg()},
+		{AccountId: feesId, Currency: currency, Amount: fee},
+	}
+}
+
+// balanceAfter returns the balance of the account right after its last posting in the journal
+func balanceAfter(journal *model.Journal, balances []decimal.Decimal, accountId model.AccountId) decimal.Decimal {
+	for i := len(journal.Postings) - 1; i >= 0; i-- {
+		if journal.Postings[i].AccountId == accountId {
+			return balances[i]
+		}
+	}
+	return decimal.Decimal{}
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type HoldStorage interface {
+	PlaceHold(hold *model.Hold) (*model.Hold, error)
+	GetHold(id model.HoldId) (*model.Hold, error)
+	// CaptureHold releases the held money and makes the transfer, which cannot be larger than the hold, in one transaction.
+	// The transfer counts against the limits like any other.
+	CaptureHold(id model.HoldId, transfer *model.Transfer, limits model.Limits) (*model.Transfer, error)
+	ReleaseHold(id model.HoldId) (*model.Hold, error)
+	// ExpireHolds releases the active holds that expire by the given time, and returns how many there were
+	ExpireHolds(now time.Time) (int, error)
+}
+
+type PostgresHoldStorage struct {
+	db       *sqlx.DB
+	accounts *PostgresAccountStorage
+}
+
+func NewPostgresHoldStorage(db *sqlx.DB) HoldStorage {
+	return &PostgresHoldStorage{db: db, accounts: &PostgresAccountStorage{db}}
+}
+
+func (storage *PostgresHoldStorage) PlaceHold(hold *model.Hold) (placed *model.Hold, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		placed = &model.Hold{}
+		if account, err := storage.accounts.lock(tx, hold.From); err != nil {
+			return err
+		} else if err := errors.CheckActive(account); err != nil {
+			return err
+		} else if account.Available().LessThan(hold.Held()) {
+			return errors.NewBalanceTooLowError(account, hold.Held())
+		} else if err := tx.Get(placed, "INSERT INTO holds (from_id, to_id, amount, fee, convert, expires_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6) RETURNING *", hold.From, hold.To, hold.Amount, hold.Fee, hold.Convert, hold.ExpiresAt); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else {
+			return changeHeld(tx, hold.From, hold.Held())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresHoldStorage) GetHold(id model.HoldId) (*model.Hold, error) {
+	hold := &model.Hold{}
+	if err := storage.db.Get(hold, "SELECT * FROM holds WHERE id = $1", id); err == sql.ErrNoRows {
+		return nil, &errors.HoldDoesNotExistError{HoldId: id}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return hold, nil
+	}
+}
+
+func (storage *PostgresHoldStorage) CaptureHold(id model.HoldId, transfer *model.Transfer, limits model.Limits) (*model.Transfer, error) {
+	created := *transfer
+	err := executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if err := lockOwnerOf(tx, transfer.From); err != nil {
+			return err
+		} else if hold, err := lockActiveHold(tx, id); err != nil {
+			return err
+		} else if created.Amount.GreaterThan(hold.Amount) {
+			return errors.NewValidationError("amount", "The captured amount cannot be more than the held amount")
+		} else if locked, err := lockAccounts(tx, hold.From, hold.To); err != nil {
+			return err
+		} else if fromAccount, err := lockedAccount(locked, hold.From); err != nil {
+			return err
+		} else if err := releaseHeld(tx, fromAccount, hold.Held()); err != nil {
+			return err
+		} else if err := storage.accounts.transferWithinLimits(tx, &created, limits); err != nil {
+			return err
+		} else {
+			return setHoldStatus(tx, id, model.CapturedHold, &created.Id)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (storage *PostgresHoldStorage) ReleaseHold(id model.HoldId) (released *model.Hold, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if released, err = lockActiveHold(tx, id); err != nil {
+			return err
+		} else if _, err := storage.accounts.lock(tx, released.From); err != nil {
+			return err
+		} else if err := changeHeld(tx, released.From, released.Held().Neg()); err != nil {
+			return err
+		} else {
+			released.Status = model.ReleasedHold
+			return setHoldStatus(tx, id, model.ReleasedHold, nil)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ExpireHolds skips the holds that are being captured or released, which settle them anyway
+func (storage *PostgresHoldStorage) ExpireHolds(now time.Time) (expired int, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		holds := make([]model.Hold, 0)
+		if err := tx.Select(&holds, "SELECT * FROM holds WHERE status = $1 AND expires_at <= $2 ORDER BY id FOR UPDATE SKIP LOCKED",
+			model.ActiveHold, now); err != nil {
+			return &errors.InternalServerError{Err: err}
+		}
+		accountIds := make([]model.AccountId, len(holds))
+		for i := range holds {
+			accountIds[i] = holds[i].From
+		}
+		if _, err := lockAccounts(tx, accountIds...); err != nil {
+			return err
+		}
+		for i := range holds {
+			if err := changeHeld(tx, holds[i].From, holds[i].Held().Neg()); err != nil {
+				return err
+			} else if err := setHoldStatus(tx, holds[i].Id, model.ExpiredHold, nil); err != nil {
+				return err
+			}
+		}
+		expired = len(holds)
+		return nil
+	})
+	return
+}
+
+func lockActiveHold(tx *sqlx.Tx, id model.HoldId) (*model.Hold, error) {
+	hold := &model.Hold{}
+	if err := tx.Get(hold, "SELECT * FROM holds WHERE id = $1 FOR UPDATE", id); err == sql.ErrNoRows {
+		return nil, &errors.HoldDoesNotExistError{HoldId: id}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if hold.Status != model.ActiveHold {
+		return nil, &errors.HoldNotActiveError{HoldId: id, Status: hold.Status}
+	} else {
+		return hold, nil
+	}
+}
+
+func changeHeld(tx *sqlx.Tx, accountId model.AccountId, amount decimal.Decimal) error {
+	if _, err := tx.Exec("UPDATE accounts SET held = held + $2 WHERE id = $1", accountId, amount); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
+
+// releaseHeld also updates the locked account, so that a transfer in the same transaction sees the released money
+func releaseHeld(tx *sqlx.Tx, account *model.Account, amount decimal.Decimal) error {
+	if err := changeHeld(tx, account.Id, amount.Neg()); err != nil {
+		return err
+	}
+	account.Held = account.Held.Sub(amount)
+	return nil
+}
+
+func setHoldStatus(tx *sqlx.Tx, id model.HoldId, status model.HoldStatus, transferId *model.TransferId) error {
+	if _, err := tx.Exec("UPDATE holds SET status = $2, transfer_id = $3 WHERE id = $1", id, status, transferId); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
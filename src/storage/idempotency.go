@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type IdempotencyStorage interface {
+	Reserve(userId model.UserId, key string, requestHash string, expiration time.Duration) (*model.IdempotencyRecord, error)
+	Complete(userId model.UserId, key string, statusCode int, response []byte) error
+	Release(userId model.UserId, key string) error
+	DeleteExpired(expiration time.Duration) (int, error)
+}
+
+type PostgresIdempotencyStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresIdempotencyStorage(db *sqlx.DB) IdempotencyStorage {
+	return &PostgresIdempotencyStorage{db}
+}
+
+// Reserve returns nil when the key is reserved for a new request, and the stored record when the key is already taken.
+// An expired key is reserved again as if it never existed.
+func (storage *PostgresIdempotencyStorage) Reserve(userId model.UserId, key string, requestHash string, expiration time.Duration) (*model.IdempotencyRecord, error) {
+	record := &model.IdempotencyRecord{}
+	if result, err := storage.db.Exec("INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash) VALUES ($1, $2, $3) "+
+		"ON CONFLICT (user_id, idempotency_key) DO UPDATE "+
+		"SET request_hash = EXCLUDED.request_hash, status_code = NULL, response = NULL, created_at = now() "+
+		"WHERE idempotency_keys.created_at < now() - make_interval(secs => $4)",
+		userId, key, requestHash, expiration.Seconds()); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if rowsAffected, err := result.RowsAffected(); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if rowsAffected > 0 {
+		return nil, nil
+	} else if err := storage.db.Get(record, "SELECT * FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2", userId, key); err == sql.ErrNoRows {
+		// The key was released in the meantime, so it can be reserved again
+		return storage.Reserve(userId, key, requestHash, expiration)
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return record, nil
+	}
+}
+
+func (storage *PostgresIdempotencyStorage) Complete(userId model.UserId, key string, statusCode int, response []byte) error {
+	if _, err := storage.db.Exec("UPDATE idempotency_keys SET status_code = $3, response = $4 WHERE user_id = $1 AND idempotency_key = $2",
+		userId, key, statusCode, response); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
+
+func (storage *PostgresIdempotencyStorage) Release(userId model.UserId, key string) error {
+	if _, err := storage.db.Exec("DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2", userId, key); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
+
+// DeleteExpired deletes the keys older than the expiration, which Reserve would treat as if they never existed
+func (storage *PostgresIdempotencyStorage) DeleteExpired(expiration time.Duration) (int, error) {
+	if result, err := storage.db.Exec("DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)", expiration.Seconds()); err != nil {
+		return 0, &errors.InternalServerError{Err: err}
+	} else if rowsAffected, err := result.RowsAffected(); err != nil {
+		return 0, &errors.InternalServerError{Err: err}
+	} else {
+		return int(rowsAffected), nil
+	}
+}
@@ -0,0 +1,657 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InMemoryAccountStorage keeps the accounts and their journals in memory for tests and demos, so it is a JournalStorage as well.
+// The holds change the held money of the accounts, so it is the HoldStorage too, and so is the InterestStorage for the interest
+// and the OutboxStorage for the events of the changes. A single mutex serializes all the changes, so every method behaves
+// as one Postgres transaction. The scheduled transfers and the standing orders are kept apart, and closing an account
+// cancels them only once they are set.
+type InMemoryAccountStorage struct {
+	mutex      sync.RWMutex
+	accounts   []*model.Account
+	journals   []model.Journal
+	transfers  []model.Transfer
+	reversals  map[model.TransferId]model.TransferId
+	holds      []model.Hold
+	accruals   map[interestDay]model.InterestAccrual
+	events     []model.Event
+	published  int
+	publishing sync.Mutex
+	ledger     *InMemoryLedgerStorage
+	scheduled  *InMemoryScheduledTransferStorage
+	orders     *InMemoryStandingOrderStorage
+}
+
+func NewInMemoryAccountStorage(ledger *InMemoryLedgerStorage) *InMemoryAccountStorage {
+	return &InMemoryAccountStorage{
+		reversals: make(map[model.TransferId]model.TransferId),
+		accruals:  make(map[interestDay]model.InterestAccrual),
+		ledger:    ledger,
+	}
+}
+
+func (storage *InMemoryAccountStorage) Create(account *model.Account) (*model.Account, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if created, err := storage.create(account); err != nil {
+		return nil, err
+	} else if err := storage.insertEvent(created.Id, model.NewAccountCreated(created)); err != nil {
+		return nil, err
+	} else {
+		return created, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) create(account *model.Account) (*model.Account, error) {
+	if account.Name != "" {
+		for _, existing := range storage.accounts {
+			if existing.Owner == account.Owner && existing.Name == account.Name {
+				return nil, &errors.DuplicateAccountError{UserId: account.Owner, Name: account.Name}
+			}
+		}
+	}
+	created := *account
+	created.Id = model.AccountId(len(storage.accounts) + 1)
+	created.Status = model.ActiveAccount
+	created.Balance = decimal.NewFromInt(0)
+	created.Held = decimal.NewFromInt(0)
+	created.OverdraftLimit = decimal.NewFromInt(0)
+	created.InterestRate = decimal.NewFromInt(0)
+	created.AccruedInterest = decimal.NewFromInt(0)
+	stored := created
+	storage.accounts = append(storage.accounts, &stored)
+	return &created, nil
+}
+
+func (storage *InMemoryAccountStorage) Get(accountId model.AccountId) (*model.Account, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else {
+		found := *account
+		return &found, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) ListByOwner(owner model.UserId) ([]model.Account, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	accounts := make([]model.Account, 0)
+	for _, account := range storage.accounts {
+		if account.Owner == owner {
+			accounts = append(accounts, *account)
+		}
+	}
+	return accounts, nil
+}
+
+func (storage *InMemoryAccountStorage) ListOverdrawn() ([]model.Account, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	accounts := make([]model.Account, 0)
+	for _, account := range storage.accounts {
+		if account.IsOverdrawn() {
+			accounts = append(accounts, *account)
+		}
+	}
+	return accounts, nil
+}
+
+func (storage *InMemoryAccountStorage) TopUp(accountId model.AccountId, amount, fee decimal.Decimal) (*model.LedgerEntry, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(account); err != nil {
+		return nil, err
+	} else if clearing, err := storage.systemAccount(model.CashInClearingAccount, account.Currency); err != nil {
+		return nil, err
+	} else if fees, err := storage.feePostings(accountId, account.Currency, fee); err != nil {
+		return nil, err
+	} else if journal, balances, err := storage.postJournal(model.TopUpJournal, append([]model.Posting{
+		{AccountId: clearing.Id, Currency: account.Currency, Amount: amount.Neg()},
+		{AccountId: accountId, Currency: account.Currency, Amount: amount},
+	}, fees...)...); err != nil {
+		return nil, err
+	} else {
+		entry := &model.LedgerEntry{
+			AccountId: accountId,
+			JournalId: &journal.Id,
+			Type:      model.TopUpEntry,
+			Amount:    amount,
+			Balance:   balanceAfter(journal, balances, accountId),
+			Fee:       fee,
+		}
+		storage.ledger.insert(entry)
+		if err := storage.insertEvent(accountId, model.NewAccountToppedUp(entry, account.Currency)); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) Transfer(transfer *model.Transfer) (*model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := *transfer
+	if fromAccount, err := storage.get(transfer.From); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(fromAccount); err != nil {
+		return nil, err
+	} else if err := storage.transfer(fromAccount, &created); err != nil {
+		return nil, err
+	} else {
+		return &created, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) TransferWithinLimits(transfer *model.Transfer, limits model.Limits) (*model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := *transfer
+	if err := storage.transferWithinLimits(&created, limits); err != nil {
+		return nil, err
+	} else {
+		return &created, nil
+	}
+}
+
+// TransferAll undoes the transfers made so far when one of them fails
+func (storage *InMemoryAccountStorage) TransferAll(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	rollback := storage.savepoint()
+	created := make([]model.Transfer, len(transfers))
+	for i, transfer := range transfers {
+		created[i] = *transfer
+		if err := storage.transferWithinLimits(&created[i], limits); err != nil {
+			rollback()
+			return nil, &errors.BatchTransferError{Index: i, Err: err}
+		}
+	}
+	return created, nil
+}
+
+func (storage *InMemoryAccountStorage) TransferLegs(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := make([]model.Transfer, len(transfers))
+	if len(transfers) == 0 {
+		return created, nil
+	} else if source, err := storage.get(transfers[0].From); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(source); err != nil {
+		return nil, err
+	} else if total := totalDebit(transfers); source.Available().LessThan(total) {
+		return nil, errors.NewBalanceTooLowError(source, total)
+	}
+	rollback := storage.savepoint()
+	for i, transfer := range transfers {
+		created[i] = *transfer
+		if err := storage.transferWithinLimits(&created[i], limits); err != nil {
+			rollback()
+			return nil, &errors.BatchTransferError{Index: i, Err: err}
+		}
+	}
+	return created, nil
+}
+
+func (storage *InMemoryAccountStorage) transferWithinLimits(transfer *model.Transfer, limits model.Limits) error {
+	fromAccount, err := storage.get(transfer.From)
+	if err != nil {
+		return err
+	}
+	accountUsage, userUsage := storage.transferUsage(fromAccount, time.Now())
+	if err := errors.CheckActive(fromAccount); err != nil {
+		return err
+	} else if err := checkLimits(fromAccount, transfer.Amount, limits, accountUsage, userUsage); err != nil {
+		return err
+	} else {
+		return storage.transfer(fromAccount, transfer)
+	}
+}
+
+// savepoint returns the function that undoes the changes made since, like the rollback of a Postgres transaction.
+// The ledger entries made in the meantime can have been read already, which Postgres would not allow.
+func (storage *InMemoryAccountStorage) savepoint() func() {
+	accounts := make([]model.Account, len(storage.accounts))
+	for i := range storage.accounts {
+		accounts[i] = *storage.accounts[i]
+	}
+	reversals := make(map[model.TransferId]model.TransferId, len(storage.reversals))
+	for transferId, reversalId := range storage.reversals {
+		reversals[transferId] = reversalId
+	}
+	journals, transfers, entries, events := len(storage.journals), len(storage.transfers), storage.ledger.length(), len(storage.events)
+	return func() {
+		storage.accounts = storage.accounts[:len(accounts)]
+		for i := range accounts {
+			*storage.accounts[i] = accounts[i]
+		}
+		storage.journals = storage.journals[:journals]
+		storage.transfers = storage.transfers[:transfers]
+		storage.reversals = reversals
+		storage.events = storage.events[:events]
+		storage.ledger.truncate(entries)
+	}
+}
+
+func (storage *InMemoryAccountStorage) GetTransfer(transferId model.TransferId) (*model.Transfer, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	return storage.getTransfer(transferId)
+}
+
+func (storage *InMemoryAccountStorage) getTransfer(transferId model.TransferId) (*model.Transfer, error) {
+	if transferId < 1 || int(transferId) > len(storage.transfers) {
+		return nil, &errors.TransferDoesNotExistError{TransferId: transferId}
+	}
+	transfer := storage.transfers[transferId-1]
+	if reversalId, ok := storage.reversals[transferId]; ok {
+		transfer.ReversedBy = &reversalId
+	}
+	return &transfer, nil
+}
+
+func (storage *InMemoryAccountStorage) GetTransferByOrigin(origin string) (*model.Transfer, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	if transfer := storage.transferByOrigin(origin); transfer == nil {
+		return nil, nil
+	} else {
+		return storage.getTransfer(transfer.Id)
+	}
+}
+
+func (storage *InMemoryAccountStorage) transferByOrigin(origin string) *model.Transfer {
+	for i := range storage.transfers {
+		if storage.transfers[i].Origin != nil && *storage.transfers[i].Origin == origin {
+			return &storage.transfers[i]
+		}
+	}
+	return nil
+}
+
+func (storage *InMemoryAccountStorage) Reverse(transferId model.TransferId, partial bool) (*model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if transferId < 1 || int(transferId) > len(storage.transfers) {
+		return nil, &errors.TransferDoesNotExistError{TransferId: transferId}
+	} else if reversalId, ok := storage.reversals[transferId]; ok {
+		return nil, &errors.TransferAlreadyReversedError{TransferId: transferId, ReversalId: reversalId}
+	}
+	original := storage.transfers[transferId-1]
+	if receiver, err := storage.get(original.To); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(receiver); err != nil {
+		return nil, err
+	} else if sender, err := storage.get(original.From); err != nil {
+		return nil, err
+	} else if reversal, err := reversalOf(&original, receiver.Available(), sender.Currency, partial); err != nil {
+		return nil, err
+	} else if err := storage.transfer(receiver, reversal); err != nil {
+		return nil, err
+	} else {
+		return reversal, nil
+	}
+}
+
+// transferUsage sums the transfers sent in the windows that end now, leaving out the reversals, which give money back
+func (storage *InMemoryAccountStorage) transferUsage(account *model.Account, now time.Time) (*model.TransferUsage, *model.TransferUsage) {
+	accountUsage, userUsage := &model.TransferUsage{}, &model.TransferUsage{}
+	for _, transfer := range storage.transfers {
+		from := storage.accounts[transfer.From-1]
+		if transfer.ReversalOf != nil || !transfer.CreatedAt.After(now.Add(-model.MonthlyWindow)) ||
+			from.Owner != account.Owner || from.Currency != account.Currency {
+			continue
+		}
+		daily := transfer.CreatedAt.After(now.Add(-model.DailyWindow))
+		addUsage(userUsage, transfer.Amount, daily)
+		if from.Id == account.Id {
+			addUsage(accountUsage, transfer.Amount, daily)
+		}
+	}
+	return accountUsage, userUsage
+}
+
+func addUsage(usage *model.TransferUsage, amount decimal.Decimal, daily bool) {
+	usage.Monthly = usage.Monthly.Add(amount)
+	if daily {
+		usage.Daily = usage.Daily.Add(amount)
+	}
+}
+
+// transfer moves the money and records the transfer, which gets its id and journal
+func (storage *InMemoryAccountStorage) transfer(fromAccount *model.Account, transfer *model.Transfer) error {
+	if transfer.Origin != nil && storage.transferByOrigin(*transfer.Origin) != nil {
+		return &errors.InternalServerError{Err: fmt.Errorf("The transfer of %s has already been made", *transfer.Origin)}
+	} else if debited := transfer.Amount.Add(transfer.Fee); fromAccount.Available().LessThan(debited) {
+		return errors.NewBalanceTooLowError(fromAccount, debited)
+	} else if toAccount, err := storage.get(transfer.To); err != nil {
+		return err
+	} else if err := errors.CheckActive(toAccount); err != nil {
+		return err
+	} else if postings, err := storage.transferPostings(transfer, fromAccount.Currency, toAccount.Currency); err != nil {
+		return err
+	} else if fees, err := storage.feePostings(transfer.From, fromAccount.Currency, transfer.Fee); err != nil {
+		return err
+	} else if journal, balances, err := storage.postJournal(transfer.JournalType(), append(postings, fees...)...); err != nil {
+		return err
+	} else {
+		transfer.Id = model.TransferId(len(storage.transfers) + 1)
+		transfer.JournalId = journal.Id
+		transfer.Balance = balanceAfter(journal, balances, transfer.From)
+		transfer.CreatedAt = journal.CreatedAt
+		storage.transfers = append(storage.transfers, *transfer)
+		if transfer.ReversalOf != nil {
+			storage.reversals[*transfer.ReversalOf] = transfer.Id
+		}
+		storage.ledger.insert(&model.LedgerEntry{
+			AccountId:    transfer.From,
+			JournalId:    &journal.Id,
+			Type:         model.TransferOutEntry,
+			Amount:       transfer.Amount.Neg(),
+			Balance:      transfer.Balance,
+			Counterparty: &transfer.To,
+			FxRate:       transfer.FxRate,
+			Fee:          transfer.Fee,
+		}, &model.LedgerEntry{
+			AccountId:    transfer.To,
+			JournalId:    &journal.Id,
+			Type:         model.TransferInEntry,
+			Amount:       transfer.CreditAmount,
+			Balance:      balanceAfter(journal, balances, transfer.To),
+			Counterparty: &transfer.From,
+			FxRate:       transfer.FxRate,
+		})
+		return storage.insertEvent(transfer.From, model.NewMoneyTransferred(transfer, fromAccount.Currency, toAccount.Currency))
+	}
+}
+
+func (storage *InMemoryAccountStorage) transferPostings(transfer *model.Transfer, fromCurrency, toCurrency model.Currency) ([]model.Posting, error) {
+	debit := model.Posting{AccountId: transfer.From, Currency: fromCurrency, Amount: transfer.Amount.Neg()}
+	credit := model.Posting{AccountId: transfer.To, Currency: toCurrency, Amount: transfer.CreditAmount}
+	if fromCurrency == toCurrency {
+		return []model.Posting{debit, credit}, nil
+	} else if fromFx, err := storage.systemAccount(model.FxAccount, fromCurrency); err != nil {
+		return nil, err
+	} else if toFx, err := storage.systemAccount(model.FxAccount, toCurrency); err != nil {
+		return nil, err
+	} else {
+		return []model.Posting{
+			debit,
+			{AccountId: fromFx.Id, Currency: fromCurrency, Amount: transfer.Amount},
+			{AccountId: toFx.Id, Currency: toCurrency, Amount: transfer.CreditAmount.Neg()},
+			credit,
+		}, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) feePostings(accountId model.AccountId, currency model.Currency, fee decimal.Decimal) ([]model.Posting, error) {
+	if !fee.IsPositive() {
+		return nil, nil
+	} else if fees, err := storage.systemAccount(model.FeesAccount, currency); err != nil {
+		return nil, err
+	} else {
+		return chargeFee(accountId, fees.Id, currency, fee), nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) SetStatus(accountId model.AccountId, status model.AccountStatus) (*model.Account, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else {
+		account.Status = status
+		updated := *account
+		return &updated, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) SetOverdraftLimit(accountId model.AccountId, limit decimal.Decimal) (*model.Account, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else {
+		account.OverdraftLimit = limit
+		updated := *account
+		return &updated, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) SetTransferLimits(accountId model.AccountId, limits model.TransferLimits) (*model.Account, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else {
+		account.TransferLimits = limits
+		updated := *account
+		return &updated, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) SetInterestRate(accountId model.AccountId, rate decimal.Decimal) (*model.Account, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else {
+		if !rate.Equal(account.InterestRate) {
+			since := model.Today(storage.ledger.now())
+			account.InterestRate = rate
+			account.InterestSince = &since
+		}
+		updated := *account
+		return &updated, nil
+	}
+}
+
+// SetSchedules makes closing an account cancel its scheduled transfers and standing orders
+func (storage *InMemoryAccountStorage) SetSchedules(scheduled *InMemoryScheduledTransferStorage, orders *InMemoryStandingOrderStorage) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.scheduled = scheduled
+	storage.orders = orders
+}
+
+// Close locks the schedules before the accounts, like their ExecuteDue does when it runs a transfer
+func (storage *InMemoryAccountStorage) Close(accountId model.AccountId, sweepTo *model.AccountId) (*model.Account, error) {
+	if storage.scheduled != nil {
+		storage.scheduled.mutex.Lock()
+		defer storage.scheduled.mutex.Unlock()
+	}
+	if storage.orders != nil {
+		storage.orders.mutex.Lock()
+		defer storage.orders.mutex.Unlock()
+	}
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else if storage.hasActiveHolds(accountId) {
+		return nil, &errors.ActiveHoldsError{AccountId: accountId}
+	} else if err := storage.sweep(account, sweepTo); err != nil {
+		return nil, err
+	} else {
+		if storage.scheduled != nil {
+			storage.scheduled.cancelOf(accountId)
+		}
+		if storage.orders != nil {
+			storage.orders.cancelOf(accountId)
+		}
+		account.Status = model.ClosedAccount
+		closed := *account
+		return &closed, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) hasActiveHolds(accountId model.AccountId) bool {
+	for _, hold := range storage.holds {
+		if hold.Status == model.ActiveHold && (hold.From == accountId || hold.To == accountId) {
+			return true
+		}
+	}
+	return false
+}
+
+func (storage *InMemoryAccountStorage) sweep(account *model.Account, sweepTo *model.AccountId) error {
+	if account.Balance.IsZero() {
+		return nil
+	} else if sweepTo == nil || account.Balance.IsNegative() {
+		return &errors.NonZeroBalanceError{AccountId: account.Id}
+	} else if target, err := storage.get(*sweepTo); err != nil {
+		return err
+	} else if target.Currency != account.Currency {
+		return &errors.CurrencyMismatchError{From: account.Id, To: target.Id, FromCurrency: account.Currency, ToCurrency: target.Currency}
+	} else {
+		return storage.transfer(account, model.NewTransfer(account.Id, target.Id, account.Balance))
+	}
+}
+
+func (storage *InMemoryAccountStorage) Post(journal *model.Journal) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if posted, _, err := storage.postJournal(journal.Type, journal.Postings...); err != nil {
+		return err
+	} else {
+		*journal = *posted
+		return nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) TrialBalance() (*model.TrialBalance, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	totals := make(map[model.Currency]*model.CurrencyTotal)
+	journalBalances := make(map[model.AccountId]decimal.Decimal)
+	for _, journal := range storage.journals {
+		for _, posting := range journal.Postings {
+			total, ok := totals[posting.Currency]
+			if !ok {
+				total = &model.CurrencyTotal{Currency: posting.Currency, Debits: decimal.Zero, Credits: decimal.Zero}
+				totals[posting.Currency] = total
+			}
+			if posting.Amount.IsNegative() {
+				total.Debits = total.Debits.Sub(posting.Amount)
+			} else {
+				total.Credits = total.Credits.Add(posting.Amount)
+			}
+			journalBalances[posting.AccountId] = journalBalances[posting.AccountId].Add(posting.Amount)
+		}
+	}
+
+	trialBalance := &model.TrialBalance{Totals: make([]model.CurrencyTotal, 0, len(totals)), Mismatches: make([]model.BalanceMismatch, 0)}
+	for _, total := range totals {
+		trialBalance.Totals = append(trialBalance.Totals, *total)
+	}
+	sort.Slice(trialBalance.Totals, func(i, j int) bool {
+		return trialBalance.Totals[i].Currency < trialBalance.Totals[j].Currency
+	})
+	for _, account := range storage.accounts {
+		if journalBalance := journalBalances[account.Id]; !account.Balance.Equal(journalBalance) {
+			trialBalance.Mismatches = append(trialBalance.Mismatches, model.BalanceMismatch{AccountId: account.Id, Balance: account.Balance, JournalBalance: journalBalance})
+		}
+	}
+	return trialBalance, nil
+}
+
+// postJournal checks every posting before changing any balance, because there is no transaction to roll back
+func (storage *InMemoryAccountStorage) postJournal(journalType model.JournalType, postings ...model.Posting) (*model.Journal, []decimal.Decimal, error) {
+	journal := model.Journal{Id: model.JournalId(len(storage.journals) + 1), Type: journalType, CreatedAt: time.Now()}
+	if !(&model.Journal{Postings: postings}).IsBalanced() {
+		return nil, nil, &errors.UnbalancedJournalError{Type: journalType}
+	}
+
+	balances := make([]decimal.Decimal, len(postings))
+	projected := make(map[model.AccountId]decimal.Decimal)
+	debited := (&model.Journal{Postings: postings}).Debited()
+	for i, posting := range postings {
+		account, err := storage.get(posting.AccountId)
+		if err != nil {
+			return nil, nil, err
+		} else if account.Currency != posting.Currency {
+			return nil, nil, &errors.InternalServerError{Err: fmt.Errorf("the posting in %s does not match the account %d in %s", posting.Currency, account.Id, account.Currency)}
+		}
+		balance, ok := projected[account.Id]
+		if !ok {
+			balance = account.Balance
+		}
+		balances[i] = balance.Add(posting.Amount)
+		projected[account.Id] = balances[i]
+		if shortfall := account.Held.Sub(account.OverdraftLimit).Sub(balances[i]); shortfall.IsPositive() && debited[account.Id] && account.Type != model.SystemAccount {
+			return nil, nil, &errors.BalanceTooLowError{AccountId: account.Id, Shortfall: shortfall}
+		}
+	}
+
+	for accountId, balance := range projected {
+		storage.accounts[accountId-1].Balance = balance
+	}
+	for _, posting := range postings {
+		posting.JournalId = journal.Id
+		journal.Postings = append(journal.Postings, posting)
+	}
+	storage.journals = append(storage.journals, journal)
+	return &journal, balances, nil
+}
+
+func (storage *InMemoryAccountStorage) systemAccount(kind model.SystemAccountKind, currency model.Currency) (*model.Account, error) {
+	name := model.SystemAccountName(kind, currency)
+	for _, account := range storage.accounts {
+		if account.Owner == model.SystemUser && account.Name == name {
+			return account, nil
+		}
+	}
+	return storage.create(model.NewSystemAccount(kind, currency))
+}
+
+// get returns the stored account itself, so the caller has to hold the mutex while using it
+func (storage *InMemoryAccountStorage) get(accountId model.AccountId) (*model.Account, error) {
+	if accountId < 1 || int(accountId) > len(storage.accounts) {
+		return nil, &errors.AccountDoesNotExistError{AccountId: accountId}
+	} else {
+		return storage.accounts[accountId-1], nil
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"sync"
+	"time"
+)
+
+type InMemoryBatchStorage struct {
+	mutex   sync.Mutex
+	batches []model.Batch
+}
+
+func NewInMemoryBatchStorage() BatchStorage {
+	return &InMemoryBatchStorage{}
+}
+
+func (storage *InMemoryBatchStorage) Create(batch *model.Batch) (*model.Batch, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := *batch
+	created.Id = model.BatchId(len(storage.batches) + 1)
+	created.CreatedAt = time.Now()
+	created.Lines = copyBatchLines(batch.Lines)
+	for i := range created.Lines {
+		created.Lines[i].BatchId = created.Id
+	}
+	storage.batches = append(storage.batches, created)
+	return copyBatch(&created), nil
+}
+
+func (storage *InMemoryBatchStorage) Get(id model.BatchId) (*model.Batch, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if batch, err := storage.get(id); err != nil {
+		return nil, err
+	} else {
+		return copyBatch(batch), nil
+	}
+}
+
+func (storage *InMemoryBatchStorage) Finish(batch *model.Batch) (*model.Batch, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if stored, err := storage.get(batch.Id); err != nil {
+		return nil, err
+	} else {
+		stored.Status = batch.Status
+		for _, line := range batch.Lines {
+			if line.Line >= 1 && line.Line <= len(stored.Lines) {
+				result := &stored.Lines[line.Line-1]
+				result.Status = line.Status
+				result.TransferId = line.TransferId
+				result.ErrorType = line.ErrorType
+				result.ErrorMessage = line.ErrorMessage
+			}
+		}
+		return copyBatch(stored), nil
+	}
+}
+
+func (storage *InMemoryBatchStorage) get(id model.BatchId) (*model.Batch, error) {
+	if id < 1 || int(id) > len(storage.batches) {
+		return nil, &errors.BatchDoesNotExistError{BatchId: id}
+	} else {
+		return &storage.batches[id-1], nil
+	}
+}
+
+// copyBatch keeps the stored lines from being changed through the batch that is returned
+func copyBatch(batch *model.Batch) *model.Batch {
+	copied := *batch
+	copied.Lines = copyBatchLines(batch.Lines)
+	return &copied
+}
+
+func copyBatchLines(lines []model.BatchLine) []model.BatchLine {
+	return append(make([]model.BatchLine, 0, len(lines)), lines...)
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+func (storage *InMemoryAccountStorage) PlaceHold(hold *model.Hold) (*model.Hold, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if account, err := storage.get(hold.From); err != nil {
+		return nil, err
+	} else if err := errors.CheckActive(account); err != nil {
+		return nil, err
+	} else if account.Available().LessThan(hold.Held()) {
+		return nil, errors.NewBalanceTooLowError(account, hold.Held())
+	} else {
+		placed := *hold
+		placed.Id = model.HoldId(len(storage.holds) + 1)
+		placed.Status = model.ActiveHold
+		placed.TransferId = nil
+		placed.CreatedAt = time.Now()
+		account.Held = account.Held.Add(hold.Held())
+		storage.holds = append(storage.holds, placed)
+		return &placed, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) GetHold(id model.HoldId) (*model.Hold, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	if hold, err := storage.getHold(id); err != nil {
+		return nil, err
+	} else {
+		found := *hold
+		return &found, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) CaptureHold(id model.HoldId, transfer *model.Transfer, limits model.Limits) (*model.Transfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := *transfer
+	if hold, err := storage.activeHold(id); err != nil {
+		return nil, err
+	} else if created.Amount.GreaterThan(hold.Amount) {
+		return nil, errors.NewValidationError("amount", "The captured amount cannot be more than the held amount")
+	} else if fromAccount, err := storage.get(hold.From); err != nil {
+		return nil, err
+	} else {
+		fromAccount.Held = fromAccount.Held.Sub(hold.Held())
+		if err := storage.transferWithinLimits(&created, limits); err != nil {
+			fromAccount.Held = fromAccount.Held.Add(hold.Held())
+			return nil, err
+		}
+		hold.Status = model.CapturedHold
+		hold.TransferId = &created.Id
+		return &created, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) ReleaseHold(id model.HoldId) (*model.Hold, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if hold, err := storage.activeHold(id); err != nil {
+		return nil, err
+	} else if err := storage.releaseHold(hold, model.ReleasedHold); err != nil {
+		return nil, err
+	} else {
+		released := *hold
+		return &released, nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) ExpireHolds(now time.Time) (int, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	expired := 0
+	for i := range storage.holds {
+		if hold := &storage.holds[i]; hold.Status == model.ActiveHold && !hold.ExpiresAt.After(now) {
+			if err := storage.releaseHold(hold, model.ExpiredHold); err != nil {
+				return expired, err
+			}
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (storage *InMemoryAccountStorage) releaseHold(hold *model.Hold, status model.HoldStatus) error {
+	if account, err := storage.get(hold.From); err != nil {
+		return err
+	} else {
+		account.Held = account.Held.Sub(hold.Held())
+		hold.Status = status
+		return nil
+	}
+}
+
+func (storage *InMemoryAccountStorage) activeHold(id model.HoldId) (*model.Hold, error) {
+	if hold, err := storage.getHold(id); err != nil {
+		return nil, err
+	} else if hold.Status != model.ActiveHold {
+		return nil, &errors.HoldNotActiveError{HoldId: id, Status: hold.Status}
+	} else {
+		return hold, nil
+	}
+}
+
+// getHold returns the stored hold itself, so the caller has to hold the mutex while using it
+func (storage *InMemoryAccountStorage) getHold(id model.HoldId) (*model.Hold, error) {
+	if id < 1 || int(id) > len(storage.holds) {
+		return nil, &errors.HoldDoesNotExistError{HoldId: id}
+	} else {
+		return &storage.holds[id-1], nil
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"golang_bank_demo/src/model"
+	"sync"
+	"time"
+)
+
+type idempotencyKey struct {
+	userId model.UserId
+	key    string
+}
+
+type InMemoryIdempotencyStorage struct {
+	mutex   sync.Mutex
+	records map[idempotencyKey]model.IdempotencyRecord
+}
+
+func NewInMemoryIdempotencyStorage() IdempotencyStorage {
+	return &InMemoryIdempotencyStorage{records: make(map[idempotencyKey]model.IdempotencyRecord)}
+}
+
+func (storage *InMemoryIdempotencyStorage) Reserve(userId model.UserId, key string, requestHash string, expiration time.Duration) (*model.IdempotencyRecord, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	now := time.Now()
+	if record, ok := storage.records[idempotencyKey{userId, key}]; ok && !record.CreatedAt.Before(now.Add(-expiration)) {
+		return &record, nil
+	} else {
+		storage.records[idempotencyKey{userId, key}] = model.IdempotencyRecord{UserId: userId, Key: key, RequestHash: requestHash, CreatedAt: now}
+		return nil, nil
+	}
+}
+
+func (storage *InMemoryIdempotencyStorage) Complete(userId model.UserId, key string, statusCode int, response []byte) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if record, ok := storage.records[idempotencyKey{userId, key}]; ok {
+		record.StatusCode = &statusCode
+		record.Response = response
+		storage.records[idempotencyKey{userId, key}] = record
+	}
+	return nil
+}
+
+func (storage *InMemoryIdempotencyStorage) Release(userId model.UserId, key string) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	delete(storage.records, idempotencyKey{userId, key})
+	return nil
+}
+
+func (storage *InMemoryIdempotencyStorage) DeleteExpired(expiration time.Duration) (int, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	deleted := 0
+	expired := time.Now().Add(-expiration)
+	for key, record := range storage.records {
+		if record.CreatedAt.Before(expired) {
+			delete(storage.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
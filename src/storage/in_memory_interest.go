@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type interestDay struct {
+	accountId model.AccountId
+	date      string
+}
+
+func (storage *InMemoryAccountStorage) ListInterestBearing() ([]model.Account, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	accounts := make([]model.Account, 0)
+	for _, account := range storage.accounts {
+		if (account.InterestRate.IsPositive() || account.AccruedInterest.IsPositive()) &&
+			account.Status != model.ClosedAccount && account.Type != model.SystemAccount {
+			accounts = append(accounts, *account)
+		}
+	}
+	return accounts, nil
+}
+
+func (storage *InMemoryAccountStorage) LastAccruedDay(accountId model.AccountId) (*time.Time, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	var last *time.Time
+	for key, accrual := range storage.accruals {
+		if key.accountId == accountId && (last == nil || accrual.Date.After(*last)) {
+			day := accrual.Date
+			last = &day
+		}
+	}
+	return last, nil
+}
+
+func (storage *InMemoryAccountStorage) AccrueInterest(accountId model.AccountId, day time.Time, dayCount model.DayCount) (*model.InterestAccrual, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	key := interestDay{accountId: accountId, date: day.Format("2006-01-02")}
+	if account, err := storage.get(accountId); err != nil {
+		return nil, err
+	} else if accrual, ok := storage.accruals[key]; ok {
+		return &accrual, nil
+	} else if account.Status == model.ClosedAccount {
+		return nil, &errors.AccountClosedError{AccountId: accountId}
+	} else {
+		accrual := model.NewInterestAccrual(account, storage.endOfDayBalance(accountId, day), day, dayCount)
+		if err := storage.capitalizeInterest(account, accrual); err != nil {
+			return nil, err
+		}
+		account.AccruedInterest = accrual.Accrued
+		storage.accruals[key] = *accrual
+		return accrual, nil
+	}
+}
+
+// endOfDayBalance adds the interest capitalized for the earlier days after the end of the day, like the Postgres storage
+func (storage *InMemoryAccountStorage) endOfDayBalance(accountId model.AccountId, day time.Time) decimal.Decimal {
+	capitalized := make(map[model.JournalId]bool)
+	for key, accrual := range storage.accruals {
+		if key.accountId == accountId && accrual.Date.Before(day) && accrual.JournalId != nil {
+			capitalized[*accrual.JournalId] = true
+		}
+	}
+	return storage.ledger.endOfDayBalance(accountId, day.AddDate(0, 0, 1), capitalized)
+}
+
+func (storage *InMemoryAccountStorage) capitalizeInterest(account *model.Account, accrual *model.InterestAccrual) error {
+	if !accrual.Capitalized.IsPositive() {
+		return nil
+	} else if interest, err := storage.systemAccount(model.InterestAccount, account.Currency); err != nil {
+		return err
+	} else if journal, balances, err := storage.postJournal(model.InterestJournal,
+		model.Posting{AccountId: interest.Id, Currency: account.Currency, Amount: accrual.Capitalized.Neg()},
+		model.Posting{AccountId: account.Id, Currency: account.Currency, Amount: accrual.Capitalized},
+	); err != nil {
+		return err
+	} else {
+		accrual.JournalId = &journal.Id
+		storage.ledger.insert(&model.LedgerEntry{
+			AccountId: account.Id,
+			JournalId: &journal.Id,
+			Type:      model.InterestEntry,
+			Amount:    accrual.Capitalized,
+			Balance:   balanceAfter(journal, balances, account.Id),
+			Fee:       decimal.Zero,
+		})
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+type InMemoryLedgerStorage struct {
+	mutex   sync.RWMutex
+	entries []model.LedgerEntry
+	now     func() time.Time
+}
+
+func NewInMemoryLedgerStorage() *InMemoryLedgerStorage {
+	return &InMemoryLedgerStorage{now: time.Now}
+}
+
+func (storage *InMemoryLedgerStorage) List(accountId model.AccountId, filter *model.LedgerFilter) ([]model.LedgerEntry, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	entries := make([]model.LedgerEntry, 0)
+	for i := len(storage.entries) - 1; i >= 0 && (filter.Limit <= 0 || len(entries) < filter.Limit); i-- {
+		if entry := storage.entries[i]; entry.AccountId == accountId && matchesLedgerFilter(&entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (storage *InMemoryLedgerStorage) GetTopUp(topUpId model.LedgerEntryId) (*model.LedgerEntry, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	// The entries are appended in the order of their ids
+	i := sort.Search(len(storage.entries), func(i int) bool { return storage.entries[i].Id >= topUpId })
+	if i == len(storage.entries) || storage.entries[i].Id != topUpId || storage.entries[i].Type != model.TopUpEntry {
+		return nil, &errors.TopUpDoesNotExistError{TopUpId: topUpId}
+	} else {
+		entry := storage.entries[i]
+		return &entry, nil
+	}
+}
+
+// Statement does not hold the mutex while the writer writes, which is safe because the entries are only ever appended
+func (storage *InMemoryLedgerStorage) Statement(statement *model.Statement, writer model.StatementWriter) error {
+	storage.mutex.RLock()
+	entries := storage.entries
+	storage.mutex.RUnlock()
+
+	statement.OpeningBalance = decimal.Zero
+	statement.ClosingBalance = decimal.Zero
+	for i := range entries {
+		if entries[i].AccountId != statement.Account.Id {
+			continue
+		} else if entries[i].CreatedAt.Before(statement.From) {
+			statement.OpeningBalance = entries[i].Balance
+		}
+		if entries[i].CreatedAt.Before(statement.To) {
+			statement.ClosingBalance = entries[i].Balance
+		}
+	}
+	if err := writer.Begin(statement); err != nil {
+		return err
+	}
+	for i := range entries {
+		if entry := entries[i]; entry.AccountId == statement.Account.Id && !entry.CreatedAt.Before(statement.From) && entry.CreatedAt.Before(statement.To) {
+			if err := writer.Entry(&entry); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.End()
+}
+
+func matchesLedgerFilter(entry *model.LedgerEntry, filter *model.LedgerFilter) bool {
+	if filter.Before != nil && entry.Id >= *filter.Before {
+		return false
+	} else if filter.From != nil && entry.CreatedAt.Before(*filter.From) {
+		return false
+	} else if filter.To != nil && !entry.CreatedAt.Before(*filter.To) {
+		return false
+	} else if len(filter.Types) == 0 {
+		return true
+	}
+	for _, entryType := range filter.Types {
+		if entry.Type == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+// SetClock makes the entries get their creation time from the clock instead of the system time, which the tests
+// use to make the entries of past days
+func (storage *InMemoryLedgerStorage) SetClock(now func() time.Time) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.now = now
+}
+
+// insert assigns the id and the creation time, just like the ledger_entries table does
+func (storage *InMemoryLedgerStorage) insert(entries ...*model.LedgerEntry) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	for _, entry := range entries {
+		entry.Id = model.LedgerEntryId(len(storage.entries) + 1)
+		entry.CreatedAt = storage.now()
+		storage.entries = append(storage.entries, *entry)
+	}
+}
+
+// endOfDayBalance is the balance of the last entry of the account made before the end, plus the entries of the given
+// journals made after it
+func (storage *InMemoryLedgerStorage) endOfDayBalance(accountId model.AccountId, end time.Time, journals map[model.JournalId]bool) decimal.Decimal {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	balance, late := decimal.Zero, decimal.Zero
+	for _, entry := range storage.entries {
+		if entry.AccountId != accountId {
+			continue
+		} else if entry.CreatedAt.Before(end) {
+			balance = entry.Balance
+		} else if entry.Type == model.InterestEntry && entry.JournalId != nil && journals[*entry.JournalId] {
+			late = late.Add(entry.Amount)
+		}
+	}
+	return balance.Add(late)
+}
+
+func (storage *InMemoryLedgerStorage) length() int {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	return len(storage.entries)
+}
+
+// truncate copies the entries it keeps, because the statements being written still read the old ones
+func (storage *InMemoryLedgerStorage) truncate(length int) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.entries = append([]model.LedgerEntry(nil), storage.entries[:length]...)
+}
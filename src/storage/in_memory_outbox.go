@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+// Publish publishes outside of the lock of the accounts, so that a slow publisher does not hold up their changes.
+// The events are published strictly in order, so the ones before the first pending event have all been published.
+func (storage *InMemoryAccountStorage) Publish(limit int, publish func(event *model.Event) error) (int, error) {
+	storage.publishing.Lock()
+	defer storage.publishing.Unlock()
+
+	storage.mutex.RLock()
+	end := storage.published + limit
+	if end > len(storage.events) {
+		end = len(storage.events)
+	}
+	pending := append(make([]model.Event, 0, end-storage.published), storage.events[storage.published:end]...)
+	storage.mutex.RUnlock()
+
+	published := 0
+	var err error
+	for i := range pending {
+		if err = publish(&pending[i]); err != nil {
+			break
+		}
+		published++
+	}
+
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+	now := time.Now()
+	for i := storage.published; i < storage.published+published; i++ {
+		storage.events[i].PublishedAt = &now
+	}
+	storage.published += published
+	return published, err
+}
+
+func (storage *InMemoryAccountStorage) insertEvent(accountId model.AccountId, data model.EventData) error {
+	if event, err := model.NewEvent(accountId, data); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		event.Id = model.EventId(len(storage.events) + 1)
+		event.CreatedAt = time.Now()
+		storage.events = append(storage.events, *event)
+		return nil
+	}
+}
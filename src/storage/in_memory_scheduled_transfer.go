@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+type InMemoryScheduledTransferStorage struct {
+	mutex     sync.Mutex
+	transfers []model.ScheduledTransfer
+}
+
+func NewInMemoryScheduledTransferStorage() *InMemoryScheduledTransferStorage {
+	return &InMemoryScheduledTransferStorage{}
+}
+
+func (storage *InMemoryScheduledTransferStorage) Create(transfer *model.ScheduledTransfer) (*model.ScheduledTransfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := model.ScheduledTransfer{
+		Id:        model.ScheduledTransferId(len(storage.transfers) + 1),
+		Owner:     transfer.Owner,
+		From:      transfer.From,
+		To:        transfer.To,
+		Amount:    transfer.Amount,
+		Convert:   transfer.Convert,
+		ExecuteAt: transfer.ExecuteAt,
+		Status:    model.PendingTransfer,
+		CreatedAt: time.Now(),
+	}
+	storage.transfers = append(storage.transfers, created)
+	return &created, nil
+}
+
+func (storage *InMemoryScheduledTransferStorage) Get(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if transfer, err := storage.get(id); err != nil {
+		return nil, err
+	} else {
+		found := *transfer
+		return &found, nil
+	}
+}
+
+func (storage *InMemoryScheduledTransferStorage) ListByOwner(owner model.UserId) ([]model.ScheduledTransfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	transfers := make([]model.ScheduledTransfer, 0)
+	for _, transfer := range storage.transfers {
+		if transfer.Owner == owner {
+			transfers = append(transfers, transfer)
+		}
+	}
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].ExecuteAt.Before(transfers[j].ExecuteAt)
+	})
+	return transfers, nil
+}
+
+func (storage *InMemoryScheduledTransferStorage) Cancel(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if transfer, err := storage.get(id); err != nil {
+		return nil, err
+	} else if transfer.Status != model.PendingTransfer {
+		return nil, &errors.ScheduledTransferNotPendingError{ScheduledTransferId: id, Status: transfer.Status}
+	} else {
+		transfer.Status = model.CancelledTransfer
+		cancelled := *transfer
+		return &cancelled, nil
+	}
+}
+
+// ExecuteDue holds the mutex while the transfer is executed, so a due transfer cannot be cancelled or executed twice
+func (storage *InMemoryScheduledTransferStorage) ExecuteDue(now time.Time, execute func(transfer *model.ScheduledTransfer) error) (bool, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	var due *model.ScheduledTransfer
+	for i := range storage.transfers {
+		transfer := &storage.transfers[i]
+		if transfer.Status == model.PendingTransfer && !transfer.ExecuteAt.After(now) && (due == nil || transfer.ExecuteAt.Before(due.ExecuteAt)) {
+			due = transfer
+		}
+	}
+	if due == nil {
+		return false, nil
+	}
+	executed := *due
+	if err := execute(&executed); err != nil {
+		return true, err
+	}
+	*due = executed
+	return true, nil
+}
+
+// cancelOf cancels the pending transfers from or to the account, and the caller holds the mutex
+func (storage *InMemoryScheduledTransferStorage) cancelOf(accountId model.AccountId) {
+	for i := range storage.transfers {
+		if transfer := &storage.transfers[i]; transfer.Status == model.PendingTransfer && (transfer.From == accountId || transfer.To == accountId) {
+			transfer.Status = model.CancelledTransfer
+		}
+	}
+}
+
+func (storage *InMemoryScheduledTransferStorage) get(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	if id < 1 || int(id) > len(storage.transfers) {
+		return nil, &errors.ScheduledTransferDoesNotExistError{ScheduledTransferId: id}
+	} else {
+		return &storage.transfers[id-1], nil
+	}
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"sync"
+	"time"
+)
+
+type InMemoryStandingOrderStorage struct {
+	mutex  sync.Mutex
+	orders []model.StandingOrder
+}
+
+func NewInMemoryStandingOrderStorage() *InMemoryStandingOrderStorage {
+	return &InMemoryStandingOrderStorage{}
+}
+
+func (storage *InMemoryStandingOrderStorage) Create(order *model.StandingOrder) (*model.StandingOrder, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	created := model.StandingOrder{
+		Recurrence: order.Recurrence,
+		Id:         model.StandingOrderId(len(storage.orders) + 1),
+		Owner:      order.Owner,
+		From:       order.From,
+		To:         order.To,
+		Amount:     order.Amount,
+		Convert:    order.Convert,
+		EndDate:    order.EndDate,
+		NextRunOn:  order.NextRunOn,
+		DueAt:      order.DueAt,
+		Status:     order.Status,
+		CreatedAt:  time.Now(),
+	}
+	storage.orders = append(storage.orders, created)
+	return &created, nil
+}
+
+func (storage *InMemoryStandingOrderStorage) Get(id model.StandingOrderId) (*model.StandingOrder, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if order, err := storage.get(id); err != nil {
+		return nil, err
+	} else {
+		found := *order
+		return &found, nil
+	}
+}
+
+func (storage *InMemoryStandingOrderStorage) ListByOwner(owner model.UserId) ([]model.StandingOrder, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	orders := make([]model.StandingOrder, 0)
+	for _, order := range storage.orders {
+		if order.Owner == owner {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (storage *InMemoryStandingOrderStorage) Update(order *model.StandingOrder) (*model.StandingOrder, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if existing, err := storage.get(order.Id); err != nil {
+		return nil, err
+	} else if existing.IsEnded() {
+		return nil, &errors.StandingOrderEndedError{StandingOrderId: order.Id, Status: existing.Status}
+	} else {
+		existing.Recurrence = order.Recurrence
+		existing.From = order.From
+		existing.To = order.To
+		existing.Amount = order.Amount
+		existing.Convert = order.Convert
+		existing.EndDate = order.EndDate
+		existing.NextRunOn = order.NextRunOn
+		existing.DueAt = order.DueAt
+		existing.Status = order.Status
+		existing.Failures = 0
+		existing.ErrorType = nil
+		existing.ErrorMessage = nil
+		updated := *existing
+		return &updated, nil
+	}
+}
+
+func (storage *InMemoryStandingOrderStorage) Cancel(id model.StandingOrderId) (*model.StandingOrder, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if order, err := storage.get(id); err != nil {
+		return nil, err
+	} else if order.IsEnded() {
+		return nil, &errors.StandingOrderEndedError{StandingOrderId: id, Status: order.Status}
+	} else {
+		order.Status = model.CancelledStandingOrder
+		cancelled := *order
+		return &cancelled, nil
+	}
+}
+
+// ExecuteDue holds the mutex while the order runs, so it cannot be changed or run twice in the meantime
+func (storage *InMemoryStandingOrderStorage) ExecuteDue(now time.Time, execute func(order *model.StandingOrder) error) (bool, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	var due *model.StandingOrder
+	for i := range storage.orders {
+		order := &storage.orders[i]
+		if order.Status == model.ActiveStandingOrder && !order.DueAt.After(now) && (due == nil || order.DueAt.Before(due.DueAt)) {
+			due = order
+		}
+	}
+	if due == nil {
+		return false, nil
+	}
+	executed := *due
+	if err := execute(&executed); err != nil {
+		return true, err
+	}
+	*due = executed
+	return true, nil
+}
+
+// cancelOf cancels the orders from or to the account that have not ended, and the caller holds the mutex
+func (storage *InMemoryStandingOrderStorage) cancelOf(accountId model.AccountId) {
+	for i := range storage.orders {
+		if order := &storage.orders[i]; !order.IsEnded() && (order.From == accountId || order.To == accountId) {
+			order.Status = model.CancelledStandingOrder
+		}
+	}
+}
+
+func (storage *InMemoryStandingOrderStorage) get(id model.StandingOrderId) (*model.StandingOrder, error) {
+	if id < 1 || int(id) > len(storage.orders) {
+		return nil, &errors.StandingOrderDoesNotExistError{StandingOrderId: id}
+	} else {
+		return &storage.orders[id-1], nil
+	}
+}
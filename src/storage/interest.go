@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type InterestStorage interface {
+	// ListInterestBearing returns the open accounts that earn interest or still have accrued interest to capitalize
+	ListInterestBearing() ([]model.Account, error)
+	// LastAccruedDay returns the last day accrued on the account, or nil when none has been
+	LastAccruedDay(accountId model.AccountId) (*time.Time, error)
+	// AccrueInterest accrues the day on the balance of the account at the end of it, and capitalizes the accrued interest
+	// on the last day of the month. Every day is accrued only once, so accruing it again returns the accrual made the first time.
+	// The days have to be accrued in order, as every accrual adds to the interest accrued by the day before.
+	AccrueInterest(accountId model.AccountId, day time.Time, dayCount model.DayCount) (*model.InterestAccrual, error)
+}
+
+type PostgresInterestStorage struct {
+	db       *sqlx.DB
+	accounts *PostgresAccountStorage
+}
+
+func NewPostgresInterestStorage(db *sqlx.DB) InterestStorage {
+	return &PostgresInterestStorage{db: db, accounts: &PostgresAccountStorage{db}}
+}
+
+func (storage *PostgresInterestStorage) ListInterestBearing() ([]model.Account, error) {
+	accounts := make([]model.Account, 0)
+	if err := storage.db.Select(&accounts, "SELECT * FROM accounts WHERE (interest_rate > 0 OR accrued_interest > 0) "+
+		"AND status <> $1 AND type <> $2 ORDER BY id", model.ClosedAccount, model.SystemAccount); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return accounts, nil
+	}
+}
+
+func (storage *PostgresInterestStorage) LastAccruedDay(accountId model.AccountId) (*time.Time, error) {
+	var last sql.NullTime
+	if err := storage.db.Get(&last, "SELECT MAX(date) FROM interest_accruals WHERE account_id = $1", accountId); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if !last.Valid {
+		return nil, nil
+	} else {
+		day := model.Today(last.Time)
+		return &day, nil
+	}
+}
+
+func (storage *PostgresInterestStorage) AccrueInterest(accountId model.AccountId, day time.Time, dayCount model.DayCount) (accrual *model.InterestAccrual, err error) {
+	err = executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		accrual = &model.InterestAccrual{}
+		if account, err := storage.accounts.lock(tx, accountId); err != nil {
+			return err
+		} else if err := tx.Get(accrual, "SELECT * FROM interest_accruals WHERE account_id = $1 AND date = $2", accountId, day); err == nil {
+			return nil
+		} else if err != sql.ErrNoRows {
+			return &errors.InternalServerError{Err: err}
+		} else if account.Status == model.ClosedAccount {
+			return &errors.AccountClosedError{AccountId: accountId}
+		} else if balance, err := endOfDayBalance(tx, accountId, day); err != nil {
+			return err
+		} else {
+			accrual = model.NewInterestAccrual(account, balance, day, dayCount)
+			return storage.accrue(tx, account, accrual)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (storage *PostgresInterestStorage) accrue(tx *sqlx.Tx, account *model.Account, accrual *model.InterestAccrual) error {
+	if err := capitalizeInterest(tx, account, accrual); err != nil {
+		return err
+	} else if _, err := tx.Exec("UPDATE accounts SET accrued_interest = $2 WHERE id = $1", account.Id, accrual.Accrued); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else if _, err := tx.NamedExec("INSERT INTO interest_accruals (account_id, date, balance, rate, amount, accrued, capitalized, journal_id) "+
+		"VALUES (:account_id, :date, :balance, :rate, :amount, :accrued, :capitalized, :journal_id)", accrual); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
+
+// endOfDayBalance is the balance of the last ledger entry made before the next day. The interest capitalized
+// for an earlier day belongs to that day too, even when it was only booked after this one, as when the days are caught up.
+func endOfDayBalance(tx *sqlx.Tx, accountId model.AccountId, day time.Time) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	if err := tx.Get(&balance, "SELECT COALESCE((SELECT balance FROM ledger_entries WHERE account_id = $1 AND created_at < $2 "+
+		"ORDER BY created_at DESC, id DESC LIMIT 1), 0) + COALESCE((SELECT SUM(e.amount) FROM ledger_entries e "+
+		"JOIN interest_accruals a ON a.journal_id = e.journal_id AND a.account_id = e.account_id "+
+		"WHERE e.account_id = $1 AND e.type = $4 AND e.created_at >= $2 AND a.date < $3), 0)",
+		accountId, day.AddDate(0, 0, 1), day, model.InterestEntry); err != nil {
+		return decimal.Zero, &errors.InternalServerError{Err: err}
+	} else {
+		return balance, nil
+	}
+}
+
+// capitalizeInterest pays the capitalized interest from the interest account of the currency, which holds the interest expense
+func capitalizeInterest(tx *sqlx.Tx, account *model.Account, accrual *model.InterestAccrual) error {
+	if !accrual.Capitalized.IsPositive() {
+		return nil
+	} else if interestId, err := systemAccount(tx, model.InterestAccount, account.Currency); err != nil {
+		return err
+	} else if journal, balances, err := postJournal(tx, model.InterestJournal,
+		model.Posting{AccountId: interestId, Currency: account.Currency, Amount: accrual.Capitalized.Neg()},
+		model.Posting{AccountId: account.Id, Currency: account.Currency, Amount: accrual.Capitalized},
+	); err != nil {
+		return err
+	} else {
+		accrual.JournalId = &journal.Id
+		return insertLedgerEntry(tx, &model.LedgerEntry{
+			AccountId: account.Id,
+			JournalId: &journal.Id,
+			Type:      model.InterestEntry,
+			Amount:    accrual.Capitalized,
+			Balance:   balanceAfter(journal, balances, account.Id),
+			Fee:       decimal.Zero,
+		})
+	}
+}
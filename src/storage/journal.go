@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+type JournalStorage interface {
+	Post(journal *model.Journal) error
+	TrialBalance() (*model.TrialBalance, error)
+}
+
+type PostgresJournalStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresJournalStorage(db *sqlx.DB) JournalStorage {
+	return &PostgresJournalStorage{db}
+}
+
+// Post records a journal that does not belong to a top-up or a transfer, so the account statuses are not checked
+func (storage *PostgresJournalStorage) Post(journal *model.Journal) error {
+	return executeInTransaction(storage.db, func(tx *sqlx.Tx) error {
+		if posted, _, err := postJournal(tx, journal.Type, journal.Postings...); err != nil {
+			return err
+		} else {
+			*journal = *posted
+			return nil
+		}
+	})
+}
+
+func (storage *PostgresJournalStorage) TrialBalance() (*model.TrialBalance, error) {
+	trialBalance := &model.TrialBalance{Totals: make([]model.CurrencyTotal, 0), Mismatches: make([]model.BalanceMismatch, 0)}
+	// Both queries have to see the same snapshot, otherwise a concurrent transfer would show up as a mismatch
+	tx, err := storage.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	}
+	defer tx.Rollback()
+
+	if err := tx.Select(&trialBalance.Totals, "SELECT currency, "+
+		"COALESCE(SUM(-amount) FILTER (WHERE amount < 0), 0) AS debits, "+
+		"COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0) AS credits "+
+		"FROM postings GROUP BY currency ORDER BY currency"); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if err := tx.Select(&trialBalance.Mismatches, "SELECT a.id AS account_id, a.balance, COALESCE(SUM(p.amount), 0) AS journal_balance "+
+		"FROM accounts a LEFT JOIN postings p ON p.account_id = a.id "+
+		"GROUP BY a.id HAVING a.balance <> COALESCE(SUM(p.amount), 0) ORDER BY a.id"); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return trialBalance, nil
+	}
+}
+
+// postJournal records a balanced journal and adds each posting to the cached balance of its account.
+// It returns the balance of the account right after each posting.
+func postJournal(tx *sqlx.Tx, journalType model.JournalType, postings ...model.Posting) (*model.Journal, []decimal.Decimal, error) {
+	journal := &model.Journal{Type: journalType, Postings: postings}
+	accountIds := make([]model.AccountId, len(postings))
+	for i := range postings {
+		accountIds[i] = postings[i].AccountId
+	}
+	if !journal.IsBalanced() {
+		return nil, nil, &errors.UnbalancedJournalError{Type: journalType}
+	} else if locked, err := lockAccounts(tx, accountIds...); err != nil {
+		return nil, nil, err
+	} else if err := checkPostings(locked, postings); err != nil {
+		return nil, nil, err
+	} else if err := tx.Get(journal, "INSERT INTO journals (type) VALUES ($1) RETURNING *", journalType); err != nil {
+		return nil, nil, &errors.InternalServerError{Err: err}
+	}
+
+	balances := make([]decimal.Decimal, len(postings))
+	debited := journal.Debited()
+	for i := range journal.Postings {
+		journal.Postings[i].JournalId = journal.Id
+		if balance, err := applyPosting(tx, &journal.Postings[i], debited[journal.Postings[i].AccountId]); err != nil {
+			return nil, nil, err
+		} else {
+			balances[i] = balance
+		}
+	}
+	return journal, balances, nil
+}
+
+func checkPostings(locked map[model.AccountId]*model.Account, postings []model.Posting) error {
+	for _, posting := range postings {
+		if account, err := lockedAccount(locked, posting.AccountId); err != nil {
+			return err
+		} else if account.Currency != posting.Currency {
+			return &errors.InternalServerError{Err: fmt.Errorf("the posting in %s does not match the account %d in %s", posting.Currency, account.Id, account.Currency)}
+		}
+	}
+	return nil
+}
+
+// applyPosting lets the customer accounts that the journal debits go below zero only down to their overdraft limit,
+// and keeps their held money. The system accounts have no limit.
+func applyPosting(tx *sqlx.Tx, posting *model.Posting, debited bool) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+	if _, err := tx.NamedExec("INSERT INTO postings (journal_id, account_id, currency, amount) "+
+		"VALUES (:journal_id, :account_id, :currency, :amount)", posting); err != nil {
+		return balance, &errors.InternalServerError{Err: err}
+	} else if err := tx.Get(&balance, "UPDATE accounts SET balance = balance + $2 "+
+		"WHERE id = $1 AND (NOT $4 OR type = $3 OR balance - held + overdraft_limit + $2 >= 0) RETURNING balance",
+		posting.AccountId, posting.Amount, model.SystemAccount, debited); err == sql.ErrNoRows {
+		return balance, balanceTooLow(tx, posting)
+	} else if err != nil {
+		return balance, &errors.InternalServerError{Err: err}
+	} else {
+		return balance, nil
+	}
+}
+
+func balanceTooLow(tx *sqlx.Tx, posting *model.Posting) error {
+	var shortfall decimal.Decimal
+	if err := tx.Get(&shortfall, "SELECT held - overdraft_limit - balance - $2 FROM accounts WHERE id = $1",
+		posting.AccountId, posting.Amount); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return &errors.BalanceTooLowError{AccountId: posting.AccountId, Shortfall: shortfall}
+	}
+}
+
+// systemAccount returns the id of the system account, which is created on first use
+func systemAccount(tx *sqlx.Tx, kind model.SystemAccountKind, currency model.Currency) (model.AccountId, error) {
+	var accountId model.AccountId
+	name := model.SystemAccountName(kind, currency)
+	if err := tx.Get(&accountId, "SELECT id FROM accounts WHERE owner_id = $1 AND name = $2", model.SystemUser, name); err == nil {
+		return accountId, nil
+	} else if err != sql.ErrNoRows {
+		return accountId, &errors.InternalServerError{Err: err}
+	} else if err := tx.Get(&accountId, "INSERT INTO accounts (owner_id, name, type, currency) VALUES ($1, $2, $3, $4) "+
+		"ON CONFLICT DO NOTHING RETURNING id", model.SystemUser, name, model.SystemAccount, currency); err == sql.ErrNoRows {
+		// A concurrent transaction has just created the same account
+		return systemAccount(tx, kind, currency)
+	} else if err != nil {
+		return accountId, &errors.InternalServerError{Err: err}
+	} else {
+		return accountId, nil
+	}
+}
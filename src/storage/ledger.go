@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"strings"
+)
+
+type LedgerStorage interface {
+	List(accountId model.AccountId, filter *model.LedgerFilter) ([]model.LedgerEntry, error)
+	// GetTopUp finds a top-up by the id of its entry, which is the id of its receipt
+	GetTopUp(topUpId model.LedgerEntryId) (*model.LedgerEntry, error)
+	// Statement sets the opening and the closing balance of the statement, and passes it with the entries of its period to the writer
+	Statement(statement *model.Statement, writer model.StatementWriter) error
+}
+
+type PostgresLedgerStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLedgerStorage(db *sqlx.DB) LedgerStorage {
+	return &PostgresLedgerStorage{db}
+}
+
+func (storage *PostgresLedgerStorage) GetTopUp(topUpId model.LedgerEntryId) (*model.LedgerEntry, error) {
+	entry := &model.LedgerEntry{}
+	if err := storage.db.Get(entry, "SELECT * FROM ledger_entries WHERE id = $1 AND type = $2", topUpId, model.TopUpEntry); err == sql.ErrNoRows {
+		return nil, &errors.TopUpDoesNotExistError{TopUpId: topUpId}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return entry, nil
+	}
+}
+
+func (storage *PostgresLedgerStorage) List(accountId model.AccountId, filter *model.LedgerFilter) ([]model.LedgerEntry, error) {
+	conditions := []string{"account_id = $1"}
+	args := []interface{}{accountId}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Before != nil {
+		addCondition("id < $%d", *filter.Before)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, entryType := range filter.Types {
+			types[i] = string(entryType)
+		}
+		addCondition("type = ANY($%d)", pq.Array(types))
+	}
+	query := "SELECT * FROM ledger_entries WHERE " + strings.Join(conditions, " AND ") + " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	entries := make([]model.LedgerEntry, 0)
+	if err := storage.db.Select(&entries, query, args...); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return entries, nil
+	}
+}
+
+// Statement reads the opening balance and the entries from the same snapshot, so that they always add up.
+// Every entry keeps the balance after it, so the opening balance is the one of the last entry before the period,
+// however far in the past the period is.
+func (storage *PostgresLedgerStorage) Statement(statement *model.Statement, writer model.StatementWriter) error {
+	tx, err := storage.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return &errors.InternalServerError{Err: err}
+	}
+	defer tx.Rollback()
+
+	balanceBefore := "SELECT COALESCE((SELECT balance FROM ledger_entries WHERE account_id = $1 AND created_at < $2 ORDER BY id DESC LIMIT 1), 0)"
+	if err := tx.Get(&statement.OpeningBalance, balanceBefore, statement.Account.Id, statement.From); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else if err := tx.Get(&statement.ClosingBalance, balanceBefore, statement.Account.Id, statement.To); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else if err := writer.Begin(statement); err != nil {
+		return err
+	}
+	rows, err := tx.Queryx("SELECT * FROM ledger_entries WHERE account_id = $1 AND created_at >= $2 AND created_at < $3 ORDER BY id",
+		statement.Account.Id, statement.From, statement.To)
+	if err != nil {
+		return &errors.InternalServerError{Err: err}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry model.LedgerEntry
+		if err := rows.StructScan(&entry); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if err := writer.Entry(&entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &errors.InternalServerError{Err: err}
+	}
+	return writer.End()
+}
+
+// insertLedgerEntry sets the id and the creation time of the entry
+func insertLedgerEntry(tx *sqlx.Tx, entry *model.LedgerEntry) error {
+	if err := tx.QueryRowx("INSERT INTO ledger_entries (account_id, journal_id, type, amount, balance, counterparty_id, fx_rate, fee) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at",
+		entry.AccountId, entry.JournalId, entry.Type, entry.Amount, entry.Balance, entry.Counterparty, entry.FxRate, entry.Fee,
+	).Scan(&entry.Id, &entry.CreatedAt); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
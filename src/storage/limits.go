@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+// checkLimits checks the limits of the account, with its own overrides, before the limits of its owner
+func checkLimits(account *model.Account, amount decimal.Decimal, limits model.Limits, accountUsage, userUsage *model.TransferUsage) error {
+	defaults := limits.Of(account.Currency)
+	if err := checkScope(model.AccountLimitScope, int64(account.Id), defaults.Account.Override(account.TransferLimits), amount, accountUsage); err != nil {
+		return err
+	} else {
+		return checkScope(model.UserLimitScope, int64(account.Owner), defaults.User, amount, userUsage)
+	}
+}
+
+func checkScope(scope model.LimitScope, id int64, limits model.TransferLimits, amount decimal.Decimal, usage *model.TransferUsage) error {
+	if limit := limits.PerTransactionLimit; limit.Valid && amount.GreaterThan(limit.Decimal) {
+		return &errors.LimitExceededError{Scope: scope, Id: id, Period: model.PerTransactionPeriod, Limit: limit.Decimal, Remaining: limit.Decimal}
+	} else if err := checkWindow(scope, id, model.DailyPeriod, limits.DailyLimit, amount, usage.Daily); err != nil {
+		return err
+	} else {
+		return checkWindow(scope, id, model.MonthlyPeriod, limits.MonthlyLimit, amount, usage.Monthly)
+	}
+}
+
+func checkWindow(scope model.LimitScope, id int64, period model.LimitPeriod, limit decimal.NullDecimal, amount, used decimal.Decimal) error {
+	if !limit.Valid || !used.Add(amount).GreaterThan(limit.Decimal) {
+		return nil
+	} else {
+		remaining := decimal.Max(limit.Decimal.Sub(used), decimal.Zero)
+		return &errors.LimitExceededError{Scope: scope, Id: id, Period: period, Limit: limit.Decimal, Remaining: remaining}
+	}
+}
+
+// lockOwnerOf serializes the limited transfers of the owner of the account, even when they are sent from different accounts.
+// The advisory lock is taken before any account is locked, and it is released at the end of the transaction.
+func lockOwnerOf(tx *sqlx.Tx, accountId model.AccountId) error {
+	var owner model.UserId
+	if err := tx.Get(&owner, "SELECT owner_id FROM accounts WHERE id = $1", accountId); err == sql.ErrNoRows {
+		return &errors.AccountDoesNotExistError{AccountId: accountId}
+	} else if err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", owner); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
+
+// transferUsage sums the transfers sent in the windows that end now, leaving out the reversals, which give money back
+func transferUsage(tx *sqlx.Tx, account *model.Account) (*model.TransferUsage, *model.TransferUsage, error) {
+	accountUsage, userUsage := &model.TransferUsage{}, &model.TransferUsage{}
+	windows := "COALESCE(SUM(t.amount) FILTER (WHERE t.created_at > now() - $2 * interval '1 second'), 0) AS daily, " +
+		"COALESCE(SUM(t.amount), 0) AS monthly FROM transfers t "
+	since := "t.reversal_of IS NULL AND t.created_at > now() - $3 * interval '1 second'"
+	if err := tx.Get(accountUsage, "SELECT "+windows+"WHERE t.from_id = $1 AND "+since,
+		account.Id, model.DailyWindow.Seconds(), model.MonthlyWindow.Seconds()); err != nil {
+		return nil, nil, &errors.InternalServerError{Err: err}
+	} else if err := tx.Get(userUsage, "SELECT "+windows+"JOIN accounts a ON a.id = t.from_id WHERE a.owner_id = $1 AND a.currency = $4 AND "+since,
+		account.Owner, model.DailyWindow.Seconds(), model.MonthlyWindow.Seconds(), account.Currency); err != nil {
+		return nil, nil, &errors.InternalServerError{Err: err}
+	} else {
+		return accountUsage, userUsage, nil
+	}
+}
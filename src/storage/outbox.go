@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+)
+
+type OutboxStorage interface {
+	// Publish hands the pending events to the publisher in the order they were written, and marks the ones it took as published.
+	// The first event that the publisher fails to take holds back the ones after it, so that no account sees its events out of order.
+	// An event is published again when it cannot be marked, so it can be published more than once.
+	Publish(limit int, publish func(event *model.Event) error) (int, error)
+}
+
+type PostgresOutboxStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresOutboxStorage(db *sqlx.DB) OutboxStorage {
+	return &PostgresOutboxStorage{db}
+}
+
+// Publish lets a single relay publish at a time, as another one could get ahead with the later events of an account.
+// The events are ordered by id, which is the order they were written in for each account, because every change of
+// an account locks it until the change is committed.
+func (storage *PostgresOutboxStorage) Publish(limit int, publish func(event *model.Event) error) (int, error) {
+	var published []int64
+	var publishErr error
+	err := executeOnce(storage.db, func(tx *sqlx.Tx) error {
+		var acquired bool
+		events := make([]model.Event, 0)
+		if err := tx.Get(&acquired, "SELECT pg_try_advisory_xact_lock(hashtext('outbox'), 0)"); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else if !acquired {
+			return nil
+		} else if err := tx.Select(&events, "SELECT * FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT $1", limit); err != nil {
+			return &errors.InternalServerError{Err: err}
+		}
+		for i := range events {
+			if publishErr = publish(&events[i]); publishErr != nil {
+				break
+			}
+			published = append(published, int64(events[i].Id))
+		}
+		if _, err := tx.Exec("UPDATE outbox SET published_at = now() WHERE id = ANY($1)", pq.Array(published)); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else {
+			return nil
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(published), publishErr
+}
+
+// insertEvent writes the event in the transaction of the change it tells about
+func insertEvent(tx *sqlx.Tx, accountId model.AccountId, data model.EventData) error {
+	if event, err := model.NewEvent(accountId, data); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else if _, err := tx.Exec("INSERT INTO outbox (account_id, type, version, data) VALUES ($1, $2, $3, $4)",
+		event.AccountId, event.Type, event.Version, string(event.Data)); err != nil {
+		return &errors.InternalServerError{Err: err}
+	} else {
+		return nil
+	}
+}
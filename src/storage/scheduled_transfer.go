@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type ScheduledTransferStorage interface {
+	Create(transfer *model.ScheduledTransfer) (*model.ScheduledTransfer, error)
+	Get(id model.ScheduledTransferId) (*model.ScheduledTransfer, error)
+	ListByOwner(owner model.UserId) ([]model.ScheduledTransfer, error)
+	Cancel(id model.ScheduledTransferId) (*model.ScheduledTransfer, error)
+	// ExecuteDue passes the next due pending transfer to execute, and stores the outcome that execute sets on it.
+	// It returns false when no transfer is due, and it keeps the transfer pending when execute returns an error.
+	ExecuteDue(now time.Time, execute func(transfer *model.ScheduledTransfer) error) (bool, error)
+}
+
+type PostgresScheduledTransferStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresScheduledTransferStorage(db *sqlx.DB) ScheduledTransferStorage {
+	return &PostgresScheduledTransferStorage{db}
+}
+
+func (storage *PostgresScheduledTransferStorage) Create(transfer *model.ScheduledTransfer) (*model.ScheduledTransfer, error) {
+	created := &model.ScheduledTransfer{}
+	if err := storage.db.Get(created, "INSERT INTO scheduled_transfers (owner_id, from_id, to_id, amount, convert, execute_at) "+
+		"VALUES ($1, $2, $3, $4, $5, $6) RETURNING *",
+		transfer.Owner, transfer.From, transfer.To, transfer.Amount, transfer.Convert, transfer.ExecuteAt); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return created, nil
+	}
+}
+
+func (storage *PostgresScheduledTransferStorage) Get(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	transfer := &model.ScheduledTransfer{}
+	if err := storage.db.Get(transfer, "SELECT * FROM scheduled_transfers WHERE id = $1", id); err == sql.ErrNoRows {
+		return nil, &errors.ScheduledTransferDoesNotExistError{ScheduledTransferId: id}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return transfer, nil
+	}
+}
+
+func (storage *PostgresScheduledTransferStorage) ListByOwner(owner model.UserId) ([]model.ScheduledTransfer, error) {
+	transfers := make([]model.ScheduledTransfer, 0)
+	if err := storage.db.Select(&transfers, "SELECT * FROM scheduled_transfers WHERE owner_id = $1 ORDER BY execute_at, id", owner); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return transfers, nil
+	}
+}
+
+// Cancel waits for the scheduler when it is executing the transfer, and then refuses to cancel it
+func (storage *PostgresScheduledTransferStorage) Cancel(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	transfer := &model.ScheduledTransfer{}
+	if err := storage.db.Get(transfer, "UPDATE scheduled_transfers SET status = $2 WHERE id = $1 AND status = $3 RETURNING *",
+		id, model.CancelledTransfer, model.PendingTransfer); err == nil {
+		return transfer, nil
+	} else if err != sql.ErrNoRows {
+		return nil, &errors.InternalServerError{Err: err}
+	} else if transfer, err := storage.Get(id); err != nil {
+		return nil, err
+	} else {
+		return nil, &errors.ScheduledTransferNotPendingError{ScheduledTransferId: id, Status: transfer.Status}
+	}
+}
+
+// ExecuteDue keeps the transfer locked while it is executed, and skips the transfers locked by other replicas.
+// The money is moved in a transaction of its own, which is not retried here. When the outcome cannot be stored
+// after that, the transfer stays pending, and its next execution finds the money moved by its origin.
+func (storage *PostgresScheduledTransferStorage) ExecuteDue(now time.Time, execute func(transfer *model.ScheduledTransfer) error) (found bool, err error) {
+	err = executeOnce(storage.db, func(tx *sqlx.Tx) error {
+		transfer := &model.ScheduledTransfer{}
+		if err := tx.Get(transfer, "SELECT * FROM scheduled_transfers WHERE status = $1 AND execute_at <= $2 "+
+			"ORDER BY execute_at, id LIMIT 1 FOR UPDATE SKIP LOCKED", model.PendingTransfer, now); err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return &errors.InternalServerError{Err: err}
+		}
+		found = true
+		if err := execute(transfer); err != nil {
+			return err
+		} else if _, err := tx.NamedExec("UPDATE scheduled_transfers "+
+			"SET status = :status, transfer_id = :transfer_id, error_type = :error_type, error_message = :error_message, "+
+			"execute_at = :execute_at, attempts = :attempts "+
+			"WHERE id = :id", transfer); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else {
+			return nil
+		}
+	})
+	return
+}
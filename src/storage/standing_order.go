@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type StandingOrderStorage interface {
+	Create(order *model.StandingOrder) (*model.StandingOrder, error)
+	Get(id model.StandingOrderId) (*model.StandingOrder, error)
+	ListByOwner(owner model.UserId) ([]model.StandingOrder, error)
+	// Update replaces the terms and the schedule of an order that has not ended yet
+	Update(order *model.StandingOrder) (*model.StandingOrder, error)
+	Cancel(id model.StandingOrderId) (*model.StandingOrder, error)
+	// ExecuteDue passes the next due active order to execute, and stores the changes that execute makes to it.
+	// It returns false when no order is due, and it leaves the order as it was when execute returns an error.
+	ExecuteDue(now time.Time, execute func(order *model.StandingOrder) error) (bool, error)
+}
+
+type PostgresStandingOrderStorage struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStandingOrderStorage(db *sqlx.DB) StandingOrderStorage {
+	return &PostgresStandingOrderStorage{db}
+}
+
+func (storage *PostgresStandingOrderStorage) Create(order *model.StandingOrder) (*model.StandingOrder, error) {
+	created := &model.StandingOrder{}
+	if err := storage.db.Get(created, "INSERT INTO standing_orders "+
+		"(owner_id, from_id, to_id, amount, convert, frequency, day, end_date, next_run_on, due_at, status) "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *",
+		order.Owner, order.From, order.To, order.Amount, order.Convert, order.Frequency, order.Day,
+		order.EndDate, order.NextRunOn, order.DueAt, order.Status); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return created, nil
+	}
+}
+
+func (storage *PostgresStandingOrderStorage) Get(id model.StandingOrderId) (*model.StandingOrder, error) {
+	order := &model.StandingOrder{}
+	if err := storage.db.Get(order, "SELECT * FROM standing_orders WHERE id = $1", id); err == sql.ErrNoRows {
+		return nil, &errors.StandingOrderDoesNotExistError{StandingOrderId: id}
+	} else if err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return order, nil
+	}
+}
+
+func (storage *PostgresStandingOrderStorage) ListByOwner(owner model.UserId) ([]model.StandingOrder, error) {
+	orders := make([]model.StandingOrder, 0)
+	if err := storage.db.Select(&orders, "SELECT * FROM standing_orders WHERE owner_id = $1 ORDER BY id", owner); err != nil {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return orders, nil
+	}
+}
+
+// Update waits for the executor when it is running the order, and resets the failures of a suspended order
+func (storage *PostgresStandingOrderStorage) Update(order *model.StandingOrder) (*model.StandingOrder, error) {
+	updated := &model.StandingOrder{}
+	if err := storage.db.Get(updated, "UPDATE standing_orders SET from_id = $2, to_id = $3, amount = $4, convert = $5, "+
+		"frequency = $6, day = $7, end_date = $8, next_run_on = $9, due_at = $10, status = $11, "+
+		"failures = 0, error_type = NULL, error_message = NULL "+
+		"WHERE id = $1 AND status IN ($12, $13) RETURNING *",
+		order.Id, order.From, order.To, order.Amount, order.Convert, order.Frequency, order.Day, order.EndDate,
+		order.NextRunOn, order.DueAt, order.Status, model.ActiveStandingOrder, model.SuspendedStandingOrder); err == nil {
+		return updated, nil
+	} else if err != sql.ErrNoRows {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return nil, storage.notUpdatable(order.Id)
+	}
+}
+
+func (storage *PostgresStandingOrderStorage) Cancel(id model.StandingOrderId) (*model.StandingOrder, error) {
+	order := &model.StandingOrder{}
+	if err := storage.db.Get(order, "UPDATE standing_orders SET status = $2 WHERE id = $1 AND status IN ($3, $4) RETURNING *",
+		id, model.CancelledStandingOrder, model.ActiveStandingOrder, model.SuspendedStandingOrder); err == nil {
+		return order, nil
+	} else if err != sql.ErrNoRows {
+		return nil, &errors.InternalServerError{Err: err}
+	} else {
+		return nil, storage.notUpdatable(id)
+	}
+}
+
+// ExecuteDue keeps the order locked while it runs, and skips the orders locked by other replicas.
+// The transfer of a run commits before the order is updated, and this transaction is not retried. A run whose update
+// is lost is made again with the same origin, which gets the transfer made the first time instead of a second one.
+func (storage *PostgresStandingOrderStorage) ExecuteDue(now time.Time, execute func(order *model.StandingOrder) error) (found bool, err error) {
+	err = executeOnce(storage.db, func(tx *sqlx.Tx) error {
+		order := &model.StandingOrder{}
+		if err := tx.Get(order, "SELECT * FROM standing_orders WHERE status = $1 AND due_at <= $2 "+
+			"ORDER BY due_at, id LIMIT 1 FOR UPDATE SKIP LOCKED", model.ActiveStandingOrder, now); err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return &errors.InternalServerError{Err: err}
+		}
+		found = true
+		if err := execute(order); err != nil {
+			return err
+		} else if _, err := tx.NamedExec("UPDATE standing_orders "+
+			"SET next_run_on = :next_run_on, due_at = :due_at, status = :status, failures = :failures, "+
+			"last_transfer_id = :last_transfer_id, error_type = :error_type, error_message = :error_message "+
+			"WHERE id = :id", order); err != nil {
+			return &errors.InternalServerError{Err: err}
+		} else {
+			return nil
+		}
+	})
+	return
+}
+
+func (storage *PostgresStandingOrderStorage) notUpdatable(id model.StandingOrderId) error {
+	if order, err := storage.Get(id); err != nil {
+		return err
+	} else {
+		return &errors.StandingOrderEndedError{StandingOrderId: id, Status: order.Status}
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"golang_bank_demo/src/errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	serializationFailureErrorCode = pq.ErrorCode("40001")
+	deadlockDetectedErrorCode     = pq.ErrorCode("40P01")
+)
+
+const (
+	maxTransactionAttempts = 5
+	transactionRetryDelay  = 10 * time.Millisecond
+)
+
+// executeInTransaction retries the transaction with a growing delay when Postgres aborts it because of a deadlock or a serialization failure
+func executeInTransaction(db *sqlx.DB, f func(*sqlx.Tx) error) error {
+	delay := transactionRetryDelay
+	for attempt := 1; ; attempt++ {
+		if err := executeOnce(db, f); err != nil && attempt < maxTransactionAttempts && isRetryable(err) {
+			time.Sleep(delay + time.Duration(rand.Int63n(int64(delay))))
+			delay *= 2
+		} else {
+			return err
+		}
+	}
+}
+
+func executeOnce(db *sqlx.DB, f func(*sqlx.Tx) error) error {
+	if tx, err := db.Beginx(); err != nil {
+		return err
+	} else if err := f(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return &errors.InternalServerError{Err: rollbackErr}
+		} else {
+			return err
+		}
+	} else if commitErr := tx.Commit(); commitErr != nil {
+		return &errors.InternalServerError{Err: commitErr}
+	} else {
+		return nil
+	}
+}
+
+func isRetryable(err error) bool {
+	if internalErr, ok := err.(*errors.InternalServerError); ok {
+		err = internalErr.Err
+	}
+	pgErr, ok := err.(*pq.Error)
+	return ok && (pgErr.Code == serializationFailureErrorCode || pgErr.Code == deadlockDetectedErrorCode)
+}
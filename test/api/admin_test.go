@@ -0,0 +1,199 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/api"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	test_service "golang_bank_demo/test/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type AdminApiSuite struct {
+	suite.Suite
+	service *test_service.StubAdminService
+	api     *mux.Router
+}
+
+func TestAdminApiSuite(t *testing.T) {
+	suite.Run(t, new(AdminApiSuite))
+}
+
+func (suite *AdminApiSuite) SetupTest() {
+	suite.service = new(test_service.StubAdminService)
+	authApi := api.NewAuthenticatedApi(service.NewStubAuthenticationService())
+	suite.api = api.NewAdminApi(suite.service, authApi).Router()
+}
+
+func (suite *AdminApiSuite) TestShouldGetTrialBalance() {
+	trialBalance := &model.TrialBalance{
+		Totals:     []model.CurrencyTotal{{Currency: "EUR", Debits: decimal.NewFromInt(130), Credits: decimal.NewFromInt(130)}},
+		Mismatches: []model.BalanceMismatch{},
+	}
+	suite.service.On("TrialBalance", model.UserId(1)).Return(trialBalance, nil)
+	req, _ := http.NewRequest("GET", "/admin/trial-balance", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"balanced\":true,\"totals\":[{\"currency\":\"EUR\",\"debits\":\"130\",\"credits\":\"130\"}],\"mismatches\":[]}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AdminApiSuite) TestShouldShowBalanceMismatches() {
+	trialBalance := &model.TrialBalance{
+		Totals:     []model.CurrencyTotal{{Currency: "EUR", Debits: decimal.NewFromInt(10), Credits: decimal.NewFromInt(10)}},
+		Mismatches: []model.BalanceMismatch{{AccountId: 3, Balance: decimal.NewFromInt(15), JournalBalance: decimal.NewFromInt(10)}},
+	}
+	suite.service.On("TrialBalance", model.UserId(1)).Return(trialBalance, nil)
+	req, _ := http.NewRequest("GET", "/admin/trial-balance", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"balanced\":false,\"totals\":[{\"currency\":\"EUR\",\"debits\":\"10\",\"credits\":\"10\"}],"+
+		"\"mismatches\":[{\"account_id\":3,\"balance\":\"15\",\"journal_balance\":\"10\"}]}\n", resp.Body.String())
+}
+
+func (suite *AdminApiSuite) TestShouldNotGetTrialBalanceWhenNotAdmin() {
+	suite.service.On("TrialBalance", model.UserId(2)).Return(nil, &errors.AdminAccessRequiredError{UserId: 2})
+	req, _ := http.NewRequest("GET", "/admin/trial-balance", nil)
+	req.Header.Set("Authorization", "Bearer token_user_2")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusForbidden, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The user 2 is not an administrator\"}\n", resp.Body.String())
+}
+
+func (suite *AdminApiSuite) TestShouldReverseTransfer() {
+	originalId := model.TransferId(3)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	reversal := &model.Transfer{Id: 4, From: 2, To: 1, Amount: decimal.NewFromInt(5), CreditAmount: decimal.NewFromInt(5), Balance: decimal.Zero, ReversalOf: &originalId, CreatedAt: createdAt}
+	suite.service.On("Reverse", &dto.ReverseTransferRequest{Id: originalId, Partial: true}, model.UserId(1)).Return(reversal, nil)
+	req, _ := http.NewRequest("POST", "/transfers/3/reverse", strings.NewReader("{\"partial\":true}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":4,\"status\":\"completed\",\"from\":2,\"to\":1,\"amount\":\"5\",\"credit_amount\":\"5\",\"fee\":\"0\",\"balance\":\"0\",\"reversal_of\":3,\"created_at\":\"2023-05-01T10:00:00Z\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AdminApiSuite) TestShouldNotReverseTransferTwice() {
+	suite.service.On("Reverse", &dto.ReverseTransferRequest{Id: 3}, model.UserId(1)).Return(nil, &errors.TransferAlreadyReversedError{TransferId: 3, ReversalId: 4})
+	req, _ := http.NewRequest("POST", "/transfers/3/reverse", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The transfer 3 is already reversed by the transfer 4\"}\n", resp.Body.String())
+}
+
+func (suite *AdminApiSuite) TestShouldNotReverseTransferThatDoesNotExist() {
+	suite.service.On("Reverse", &dto.ReverseTransferRequest{Id: 9}, model.UserId(1)).Return(nil, &errors.TransferDoesNotExistError{TransferId: 9})
+	req, _ := http.NewRequest("POST", "/transfers/9/reverse", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+}
+
+func (suite *AdminApiSuite) TestShouldSetOverdraftLimit() {
+	limit := decimal.NewFromInt(500)
+	account := &model.Account{Id: 3, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(-20), OverdraftLimit: limit}
+	suite.service.On("SetOverdraftLimit", &dto.OverdraftLimitRequest{AccountId: 3, OverdraftLimit: limit}, model.UserId(1)).Return(account, nil)
+	req, _ := http.NewRequest("PUT", "/admin/accounts/3/overdraft-limit", strings.NewReader("{\"overdraft_limit\":500}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"-20\","+
+		"\"available_balance\":\"480\",\"overdraft_limit\":\"500\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AdminApiSuite) TestShouldSetInterestRate() {
+	rate := decimal.RequireFromString("0.025")
+	account := &model.Account{Id: 3, Type: model.SavingsAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20), InterestRate: rate}
+	suite.service.On("SetInterestRate", &dto.InterestRateRequest{AccountId: 3, InterestRate: rate}, model.UserId(1)).Return(account, nil)
+	req, _ := http.NewRequest("PUT", "/admin/accounts/3/interest-rate", strings.NewReader("{\"interest_rate\":\"0.025\"}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"type\":\"savings\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\","+
+		"\"available_balance\":\"20\",\"overdraft_limit\":\"0\",\"interest_rate\":\"0.025\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AdminApiSuite) TestShouldSetTransferLimits() {
+	daily := decimal.NewFromInt(300)
+	account := &model.Account{Id: 3, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20),
+		TransferLimits: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(daily)}}
+	request := &dto.TransferLimitsRequest{AccountId: 3, TransferLimits: dto.TransferLimits{Daily: &daily}}
+	suite.service.On("SetTransferLimits", request, model.UserId(1)).Return(account, nil)
+	req, _ := http.NewRequest("PUT", "/admin/accounts/3/limits", strings.NewReader("{\"daily\":300}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\","+
+		"\"available_balance\":\"20\",\"overdraft_limit\":\"0\",\"limits\":{\"daily\":\"300\"}}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AdminApiSuite) TestShouldListOverdrawnAccounts() {
+	accounts := []model.Account{{Id: 3, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(-20), OverdraftLimit: decimal.NewFromInt(50)}}
+	suite.service.On("OverdrawnAccounts", model.UserId(1)).Return(accounts, nil)
+	req, _ := http.NewRequest("GET", "/admin/accounts/overdrawn", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "[{\"id\":3,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"-20\","+
+		"\"available_balance\":\"30\",\"overdraft_limit\":\"50\"}]\n", resp.Body.String())
+}
+
+func (suite *AdminApiSuite) TestShouldUnfreezeAccount() {
+	account := &model.Account{Id: 3, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)}
+	suite.service.On("Unfreeze", model.AccountId(3), model.UserId(1)).Return(account, nil)
+	req, _ := http.NewRequest("POST", "/admin/accounts/3/unfreeze", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\","+
+		"\"available_balance\":\"20\",\"overdraft_limit\":\"0\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
@@ -3,25 +3,30 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang_bank_demo/src/api"
 	"golang_bank_demo/src/dto"
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/service"
+	test_errors "golang_bank_demo/test/errors"
 	test_service "golang_bank_demo/test/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type AccountApiSuite struct {
 	suite.Suite
-	service *test_service.StubAccountService
-	api     *mux.Router
+	service     *test_service.StubAccountService
+	idempotency *test_service.StubIdempotencyService
+	api         *mux.Router
 }
 
 func TestAccountServiceSuite(t *testing.T) {
@@ -30,14 +35,16 @@ func TestAccountServiceSuite(t *testing.T) {
 
 func (suite *AccountApiSuite) SetupTest() {
 	suite.service = new(test_service.StubAccountService)
+	suite.idempotency = new(test_service.StubIdempotencyService)
 	authApi := api.NewAuthenticatedApi(service.NewStubAuthenticationService())
-	suite.api = api.NewAccountApi(suite.service, authApi).Router()
+	idempotentApi := api.NewIdempotentApi(suite.idempotency)
+	suite.api = api.NewAccountApi(suite.service, authApi, idempotentApi).Router()
 }
 
 func (suite *AccountApiSuite) TestShouldGetAccount() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(20)}
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)}
 	suite.service.On("Get", accountId).Return(account, nil)
 	req, _ := http.NewRequest("GET", "/accounts/1", nil)
 	req.Header.Set("Authorization", "Bearer token_user_1")
@@ -46,14 +53,14 @@ func (suite *AccountApiSuite) TestShouldGetAccount() {
 	suite.api.ServeHTTP(resp, req)
 
 	assert.Equal(suite.T(), resp.Code, http.StatusOK)
-	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"balance\":\"20\"}\n")
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\",\"available_balance\":\"20\",\"overdraft_limit\":\"0\"}\n")
 	suite.service.AssertExpectations(suite.T())
 }
 
 func (suite *AccountApiSuite) TestShouldNotGetAccountWhenNoToken() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(20)}
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)}
 	suite.service.On("Get", accountId).Return(account, nil)
 	req, _ := http.NewRequest("GET", "/accounts/1", nil)
 	resp := httptest.NewRecorder()
@@ -68,7 +75,7 @@ func (suite *AccountApiSuite) TestShouldNotGetAccountWhenNoToken() {
 func (suite *AccountApiSuite) TestShouldNotGetAccountWhenUnknownUser() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(20)}
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)}
 	suite.service.On("Get", accountId).Return(account, nil)
 	req, _ := http.NewRequest("GET", "/accounts/1", nil)
 	req.Header.Set("Authorization", "Bearer unknown_user")
@@ -99,8 +106,8 @@ func (suite *AccountApiSuite) TestShouldNotGetAccountWhenAccountAccessForbidden(
 func (suite *AccountApiSuite) TestShouldCreateAccount() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(20)}
-	suite.service.On("Create", userId).Return(account, nil)
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)}
+	suite.service.On("Create", &dto.CreateAccountRequest{}, userId).Return(account, nil)
 	req, _ := http.NewRequest("POST", "/accounts", nil)
 	req.Header.Set("Authorization", "Bearer token_user_1")
 	resp := httptest.NewRecorder()
@@ -108,21 +115,59 @@ func (suite *AccountApiSuite) TestShouldCreateAccount() {
 	suite.api.ServeHTTP(resp, req)
 
 	assert.Equal(suite.T(), resp.Code, http.StatusCreated)
-	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"balance\":\"20\"}\n")
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\",\"available_balance\":\"20\",\"overdraft_limit\":\"0\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldCreateNamedAccount() {
+	userId := model.UserId(1)
+	request := &dto.CreateAccountRequest{Name: "Holidays", Type: model.SavingsAccount}
+	account := &model.Account{Id: 2, Owner: userId, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(0)}
+	suite.service.On("Create", request, userId).Return(account, nil)
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/accounts", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusCreated)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":2,\"name\":\"Holidays\",\"type\":\"savings\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"0\",\"available_balance\":\"0\",\"overdraft_limit\":\"0\"}\n")
 	suite.service.AssertExpectations(suite.T())
 }
 
 func (suite *AccountApiSuite) TestShouldNotCreateWhenDuplicateAccount() {
 	userId := model.UserId(1)
-	suite.service.On("Create", userId).Return(nil, &errors.DuplicateAccountError{UserId: userId})
-	req, _ := http.NewRequest("POST", "/accounts", nil)
+	request := &dto.CreateAccountRequest{Name: "Holidays"}
+	suite.service.On("Create", request, userId).Return(nil, &errors.DuplicateAccountError{UserId: userId, Name: "Holidays"})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/accounts", bytes.NewReader(body))
 	req.Header.Set("Authorization", "Bearer token_user_1")
 	resp := httptest.NewRecorder()
 
 	suite.api.ServeHTTP(resp, req)
 
 	assert.Equal(suite.T(), resp.Code, http.StatusConflict)
-	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The user 1 already has an account\"}\n")
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The user 1 already has an account named 'Holidays'\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldListAccounts() {
+	userId := model.UserId(1)
+	accounts := []model.Account{
+		{Id: 1, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(20)},
+		{Id: 2, Owner: userId, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(5)},
+	}
+	suite.service.On("List", userId).Return(accounts, nil)
+	req, _ := http.NewRequest("GET", "/accounts", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Body.String(), "[{\"id\":1,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"20\",\"available_balance\":\"20\",\"overdraft_limit\":\"0\"},"+
+		"{\"id\":2,\"name\":\"Holidays\",\"type\":\"savings\",\"currency\":\"EUR\",\"status\":\"active\",\"balance\":\"5\",\"available_balance\":\"5\",\"overdraft_limit\":\"0\"}]\n")
 	suite.service.AssertExpectations(suite.T())
 }
 
@@ -130,7 +175,10 @@ func (suite *AccountApiSuite) TestShouldTopUp() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
 	request := &dto.TopUpRequest{Id: accountId, Amount: decimal.NewFromInt(100)}
-	suite.service.On("TopUp", request, userId).Return(nil)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	entry := &model.LedgerEntry{Id: 7, AccountId: accountId, Type: model.TopUpEntry, Amount: decimal.NewFromInt(100), Fee: decimal.NewFromInt(2),
+		Balance: decimal.NewFromInt(148), CreatedAt: createdAt}
+	suite.service.On("TopUp", request, userId).Return(entry, nil)
 	body, _ := json.Marshal(request)
 	req, _ := http.NewRequest("POST", "/top-up", bytes.NewReader(body))
 
@@ -140,7 +188,7 @@ func (suite *AccountApiSuite) TestShouldTopUp() {
 	suite.api.ServeHTTP(resp, req)
 
 	assert.Equal(suite.T(), resp.Code, http.StatusOK)
-	assert.Equal(suite.T(), resp.Body.String(), "\"{}\"\n")
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":7,\"status\":\"completed\",\"account_id\":1,\"amount\":\"100\",\"fee\":\"2\",\"balance\":\"148\",\"created_at\":\"2023-05-01T10:00:00Z\"}\n")
 	suite.service.AssertExpectations(suite.T())
 }
 
@@ -148,7 +196,7 @@ func (suite *AccountApiSuite) TestShouldNotTopUpWhenValidationError() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
 	request := &dto.TopUpRequest{Id: accountId, Amount: decimal.NewFromInt(100)}
-	suite.service.On("TopUp", request, userId).Return(&errors.ValidationError{Field: "id", Message: "The id has to be positive"})
+	suite.service.On("TopUp", request, userId).Return(nil, &errors.ValidationError{Field: "id", Message: "The id has to be positive"})
 	body, _ := json.Marshal(request)
 	req, _ := http.NewRequest("POST", "/top-up", bytes.NewReader(body))
 
@@ -167,7 +215,9 @@ func (suite *AccountApiSuite) TestShouldTransfer() {
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(1)
 	request := &dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(100)}
-	suite.service.On("Transfer", request, userId).Return(nil)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	transfer := &model.Transfer{Id: 5, From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(100), CreditAmount: decimal.NewFromInt(100), Balance: decimal.NewFromInt(20), CreatedAt: createdAt}
+	suite.service.On("Transfer", request, userId).Return(transfer, nil)
 	body, _ := json.Marshal(request)
 	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
 
@@ -177,16 +227,186 @@ func (suite *AccountApiSuite) TestShouldTransfer() {
 	suite.api.ServeHTTP(resp, req)
 
 	assert.Equal(suite.T(), resp.Code, http.StatusOK)
-	assert.Equal(suite.T(), resp.Body.String(), "\"{}\"\n")
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":5,\"status\":\"completed\",\"from\":1,\"to\":1,\"amount\":\"100\",\"credit_amount\":\"100\",\"fee\":\"0\",\"balance\":\"20\",\"created_at\":\"2023-05-01T10:00:00Z\"}\n")
 	suite.service.AssertExpectations(suite.T())
 }
 
+func (suite *AccountApiSuite) TestShouldTransferLegs() {
+	request := &dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{{To: 2, Amount: decimal.NewFromInt(30)}, {To: 3, Amount: decimal.NewFromInt(20)}}}
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	transfers := []model.Transfer{
+		{Id: 5, From: 1, To: 2, Amount: decimal.NewFromInt(30), CreditAmount: decimal.NewFromInt(30), Balance: decimal.NewFromInt(70), CreatedAt: createdAt},
+		{Id: 6, From: 1, To: 3, Amount: decimal.NewFromInt(20), CreditAmount: decimal.NewFromInt(20), Balance: decimal.NewFromInt(50), CreatedAt: createdAt},
+	}
+	suite.service.On("TransferLegs", request, model.UserId(1)).Return(transfers, nil)
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfers/batch", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "[{\"id\":5,\"status\":\"completed\",\"from\":1,\"to\":2,\"amount\":\"30\",\"credit_amount\":\"30\",\"fee\":\"0\",\"balance\":\"70\",\"created_at\":\"2023-05-01T10:00:00Z\"},"+
+		"{\"id\":6,\"status\":\"completed\",\"from\":1,\"to\":3,\"amount\":\"20\",\"credit_amount\":\"20\",\"fee\":\"0\",\"balance\":\"50\",\"created_at\":\"2023-05-01T10:00:00Z\"}]\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldTellWhichLegFailed() {
+	request := &dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{{To: 2, Amount: decimal.NewFromInt(30)}, {To: 9, Amount: decimal.NewFromInt(20)}}}
+	suite.service.On("TransferLegs", request, model.UserId(1)).Return(nil, &errors.BatchTransferError{Index: 1, Err: &errors.AccountDoesNotExistError{AccountId: 9}})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfers/batch", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The transfer 2 of the batch failed: The account 9 does not exist\",\"leg\":2,"+
+		"\"error_type\":\"AccountDoesNotExistError\",\"error_message\":\"The account 9 does not exist\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldTellWhichLegFailedWithUnhandledError() {
+	request := &dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{{To: 2, Amount: decimal.NewFromInt(30)}}}
+	suite.service.On("TransferLegs", request, model.UserId(1)).Return(nil, &errors.BatchTransferError{Index: 0, Err: test_errors.TimeoutError{}})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfers/batch", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The transfer 1 of the batch failed: timeout\",\"leg\":1,"+
+		"\"error_type\":\"TimeoutError\",\"error_message\":\"timeout\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldGetTransferReceipt() {
+	userId := model.UserId(1)
+	reversalId := model.TransferId(6)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	transfer := &model.Transfer{
+		Id:           5,
+		From:         1,
+		To:           2,
+		Amount:       decimal.NewFromInt(100),
+		CreditAmount: decimal.RequireFromString("113"),
+		FxRate:       decimal.NullDecimal{Decimal: decimal.RequireFromString("1.13"), Valid: true},
+		Balance:      decimal.NewFromInt(20),
+		ReversedBy:   &reversalId,
+		CreatedAt:    createdAt,
+	}
+	suite.service.On("GetTransfer", model.TransferId(5), userId).Return(transfer, nil)
+	req, _ := http.NewRequest("GET", "/transfers/5", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":5,\"status\":\"reversed\",\"from\":1,\"to\":2,\"amount\":\"100\",\"credit_amount\":\"113\","+
+		"\"fx_rate\":\"1.13\",\"fee\":\"0\",\"balance\":\"20\",\"reversed_by\":6,\"created_at\":\"2023-05-01T10:00:00Z\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldNotGetTransferThatDoesNotExist() {
+	suite.service.On("GetTransfer", model.TransferId(9), model.UserId(1)).Return(nil, &errors.TransferDoesNotExistError{TransferId: 9})
+	req, _ := http.NewRequest("GET", "/transfers/9", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The transfer 9 does not exist\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldGetTopUpReceipt() {
+	entry := &model.LedgerEntry{Id: 4, AccountId: 1, Type: model.TopUpEntry, Amount: decimal.NewFromInt(100), Fee: decimal.NewFromInt(1),
+		Balance: decimal.NewFromInt(99), CreatedAt: time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)}
+	suite.service.On("GetTopUp", model.LedgerEntryId(4), model.UserId(1)).Return(entry, nil)
+	req, _ := http.NewRequest("GET", "/top-ups/4", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":4,\"status\":\"completed\",\"account_id\":1,\"amount\":\"100\",\"fee\":\"1\",\"balance\":\"99\","+
+		"\"created_at\":\"2023-05-01T10:00:00Z\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldNotGetTopUpThatDoesNotExist() {
+	suite.service.On("GetTopUp", model.LedgerEntryId(9), model.UserId(1)).Return(nil, &errors.TopUpDoesNotExistError{TopUpId: 9})
+	req, _ := http.NewRequest("GET", "/top-ups/9", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The top-up 9 does not exist\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldScheduleTransfer() {
+	userId := model.UserId(1)
+	executeAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100), ExecuteAt: &executeAt}
+	scheduled := &model.ScheduledTransfer{Id: 3, Owner: userId, From: 1, To: 2, Amount: decimal.NewFromInt(100), ExecuteAt: executeAt, Status: model.PendingTransfer, CreatedAt: createdAt}
+	suite.service.On("Schedule", request, userId).Return(scheduled, nil)
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"status\":\"pending\",\"from\":1,\"to\":2,\"amount\":\"100\",\"convert\":false,"+
+		"\"execute_at\":\"2030-01-01T09:00:00Z\",\"created_at\":\"2023-05-01T10:00:00Z\"}\n", resp.Body.String())
+	suite.service.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything)
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldListScheduledTransfers() {
+	userId := model.UserId(1)
+	transferId := model.TransferId(7)
+	executeAt := time.Date(2023, 5, 2, 9, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	transfers := []model.ScheduledTransfer{
+		{Id: 3, Owner: userId, From: 1, To: 2, Amount: decimal.NewFromInt(100), ExecuteAt: executeAt, Status: model.ExecutedTransfer, TransferId: &transferId, CreatedAt: createdAt},
+	}
+	suite.service.On("ScheduledTransfers", userId).Return(transfers, nil)
+	req, _ := http.NewRequest("GET", "/scheduled-transfers", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "[{\"id\":3,\"status\":\"executed\",\"from\":1,\"to\":2,\"amount\":\"100\",\"convert\":false,"+
+		"\"execute_at\":\"2023-05-02T09:00:00Z\",\"transfer_id\":7,\"created_at\":\"2023-05-01T10:00:00Z\"}]\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldNotCancelScheduledTransferThatIsNotPending() {
+	suite.service.On("CancelScheduled", model.ScheduledTransferId(3), model.UserId(1)).
+		Return(nil, &errors.ScheduledTransferNotPendingError{ScheduledTransferId: 3, Status: model.ExecutedTransfer})
+	req, _ := http.NewRequest("POST", "/scheduled-transfers/3/cancel", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The scheduled transfer 3 is already executed\"}\n", resp.Body.String())
+}
 func (suite *AccountApiSuite) TestShouldTransferWhenBalanceTooLow() {
 	userId := model.UserId(1)
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(1)
 	request := &dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(100)}
-	suite.service.On("Transfer", request, userId).Return(&errors.BalanceTooLowError{AccountId: fromAccountId})
+	suite.service.On("Transfer", request, userId).Return(nil, &errors.BalanceTooLowError{AccountId: fromAccountId})
 	body, _ := json.Marshal(request)
 	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
 
@@ -199,3 +419,343 @@ func (suite *AccountApiSuite) TestShouldTransferWhenBalanceTooLow() {
 	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The account 1 does not have enough money\"}\n")
 	suite.service.AssertExpectations(suite.T())
 }
+
+func (suite *AccountApiSuite) TestShouldNotTransferOverTheLimit() {
+	userId := model.UserId(1)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)}
+	suite.service.On("Transfer", request, userId).Return(nil, &errors.LimitExceededError{
+		Scope: model.AccountLimitScope, Id: 1, Period: model.DailyPeriod, Limit: decimal.NewFromInt(500), Remaining: decimal.NewFromInt(40),
+	})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The transfer exceeds the daily limit of 500 of the account 1, 40 is left\","+
+		"\"scope\":\"account\",\"period\":\"daily\",\"limit\":\"500\",\"remaining\":\"40\"}\n", resp.Body.String())
+}
+
+func (suite *AccountApiSuite) TestShouldGetTransactions() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	counterparty := model.AccountId(2)
+	next := model.LedgerEntryId(7)
+	createdAt := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	request := &dto.TransactionsRequest{AccountId: accountId, Limit: 1, Types: []model.LedgerEntryType{model.TransferOutEntry}}
+	page := &model.LedgerPage{
+		Entries: []model.LedgerEntry{{
+			Id:           7,
+			AccountId:    accountId,
+			Type:         model.TransferOutEntry,
+			Amount:       decimal.NewFromInt(-5),
+			Balance:      decimal.NewFromInt(15),
+			Counterparty: &counterparty,
+			Fee:          decimal.RequireFromString("0.1"),
+			CreatedAt:    createdAt,
+		}},
+		Next: &next,
+	}
+	suite.service.On("Transactions", request, userId).Return(page, nil)
+	req, _ := http.NewRequest("GET", "/accounts/1/transactions?limit=1&type=transfer_out", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"transactions\":[{\"id\":7,\"type\":\"transfer_out\",\"amount\":\"-5\",\"balance\":\"15\","+
+		"\"counterparty\":2,\"fee\":\"0.1\",\"created_at\":\"2021-11-01T10:00:00Z\"}],\"next_cursor\":\"Nw\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldGetTransactionsPageByCursor() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	cursor := model.LedgerEntryId(7)
+	from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	request := &dto.TransactionsRequest{AccountId: accountId, Cursor: &cursor, From: &from, To: &to, Limit: dto.DefaultTransactionsLimit}
+	suite.service.On("Transactions", request, userId).Return(&model.LedgerPage{Entries: []model.LedgerEntry{}}, nil)
+	req, _ := http.NewRequest("GET", "/accounts/1/transactions?cursor=Nw&from=2021-11-01&to=2021-11-30", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"transactions\":[]}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldGetStatementAsCsv() {
+	userId := model.UserId(1)
+	from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	request := &dto.StatementRequest{AccountId: 1, From: &from, To: &to, Format: model.CsvStatement}
+	suite.service.On("Statement", request, userId, mock.Anything).Run(func(args mock.Arguments) {
+		writer := args.Get(2).(model.StatementWriter)
+		_ = writer.Begin(&model.Statement{Account: &model.Account{Id: 1, Currency: "EUR"}, From: from, To: to,
+			OpeningBalance: decimal.NewFromInt(10), ClosingBalance: decimal.NewFromInt(10)})
+		_ = writer.End()
+	}).Return(nil)
+	req, _ := http.NewRequest("GET", "/accounts/1/statement?from=2021-11-01&to=2021-11-30&format=csv", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Header().Get("Content-Type"), "text/csv; charset=utf-8")
+	assert.Equal(suite.T(), resp.Body.String(), "date,type,counterparty,amount,fee,balance\n"+
+		"2021-11-01T00:00:00Z,opening_balance,,,,10.00\n"+
+		"2021-12-01T00:00:00Z,closing_balance,,,,10.00\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotGetStatementWhenAccountAccessForbidden() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	suite.service.On("Statement", mock.Anything, userId, mock.Anything).Return(&errors.ForbiddenAccountAccessError{AccountId: accountId, UserId: userId})
+	req, _ := http.NewRequest("GET", "/accounts/1/statement?from=2021-11-01&to=2021-11-30&format=txt", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusForbidden)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The user 1 cannot access the account 1\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotGetStatementWhenDateIsInvalid() {
+	req, _ := http.NewRequest("GET", "/accounts/1/statement?from=yesterday&to=2021-11-30", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusBadRequest)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"Invalid field 'from': The date must be in the format YYYY-MM-DD or RFC 3339\"}\n")
+	suite.service.AssertNotCalled(suite.T(), "Statement", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AccountApiSuite) TestShouldNotGetTransactionsWhenCursorIsInvalid() {
+	req, _ := http.NewRequest("GET", "/accounts/1/transactions?cursor=???", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusBadRequest)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"Invalid field 'cursor': The cursor is not valid\"}\n")
+	suite.service.AssertNotCalled(suite.T(), "Transactions", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountApiSuite) TestShouldTransferOnceWithIdempotencyKey() {
+	userId := model.UserId(1)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)}
+	suite.service.On("Transfer", request, userId).Return(&model.Transfer{Id: 5, From: 1, To: 2, Amount: decimal.NewFromInt(100), CreditAmount: decimal.NewFromInt(100)}, nil)
+	suite.idempotency.On("Begin", userId, "key-1", mock.Anything).Return(nil, nil)
+	suite.idempotency.On("Complete", userId, "key-1", http.StatusOK, mock.Anything).Return(nil)
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	req.Header.Set("Idempotency-Key", "key-1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Contains(suite.T(), resp.Body.String(), "\"id\":5")
+	suite.service.AssertExpectations(suite.T())
+	suite.idempotency.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldReplayStoredResponseForIdempotencyKey() {
+	userId := model.UserId(1)
+	statusCode := http.StatusBadRequest
+	storedResponse := []byte("{\"message\":\"The account 1 does not have enough money\"}\n")
+	record := &model.IdempotencyRecord{UserId: userId, Key: "key-1", StatusCode: &statusCode, Response: storedResponse}
+	suite.idempotency.On("Begin", userId, "key-1", mock.Anything).Return(record, nil)
+	body, _ := json.Marshal(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)})
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	req.Header.Set("Idempotency-Key", "key-1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusBadRequest)
+	assert.Equal(suite.T(), resp.Body.Bytes(), storedResponse)
+	assert.Equal(suite.T(), resp.Header().Get("Idempotent-Replayed"), "true")
+	suite.service.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountApiSuite) TestShouldHashTheSameRequestsEqually() {
+	userId := model.UserId(1)
+	var hashes []string
+	suite.idempotency.On("Begin", userId, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		hashes = append(hashes, args.String(2))
+	}).Return(nil, &errors.IdempotencyKeyInProgressError{Key: "key-1"})
+	for _, body := range []string{`{"from":1,"to":2,"amount":"1"}`, `{"from":1,"to":2,"amount":"1"}`, `{"from":1,"to":2,"amount":"2"}`} {
+		req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer token_user_1")
+		req.Header.Set("Idempotency-Key", "key-1")
+		suite.api.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Len(suite.T(), hashes, 3)
+	assert.Equal(suite.T(), hashes[0], hashes[1])
+	assert.NotEqual(suite.T(), hashes[0], hashes[2])
+}
+
+func (suite *AccountApiSuite) TestShouldNotTransferWhenIdempotencyKeyIsReused() {
+	userId := model.UserId(1)
+	suite.idempotency.On("Begin", userId, "key-1", mock.Anything).Return(nil, &errors.IdempotencyKeyReuseError{Key: "key-1"})
+	body, _ := json.Marshal(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)})
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	req.Header.Set("Idempotency-Key", "key-1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusUnprocessableEntity)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The idempotency key 'key-1' was already used for a different request\"}\n")
+	suite.service.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountApiSuite) TestShouldReleaseIdempotencyKeyWhenTopUpFails() {
+	userId := model.UserId(1)
+	request := &dto.TopUpRequest{Id: 1, Amount: decimal.NewFromInt(100)}
+	suite.service.On("TopUp", request, userId).Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")})
+	suite.idempotency.On("Begin", userId, "key-1", mock.Anything).Return(nil, nil)
+	suite.idempotency.On("Release", userId, "key-1").Return(nil)
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/top-up", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	req.Header.Set("Idempotency-Key", "key-1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusInternalServerError)
+	suite.idempotency.AssertExpectations(suite.T())
+	suite.idempotency.AssertNotCalled(suite.T(), "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AccountApiSuite) TestShouldNotTransferBetweenDifferentCurrencies() {
+	userId := model.UserId(1)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)}
+	suite.service.On("Transfer", request, userId).Return(nil, &errors.CurrencyMismatchError{From: 1, To: 2, FromCurrency: "EUR", ToCurrency: "USD"})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusBadRequest)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The account 1 in EUR cannot transfer money to the account 2 in USD without a conversion\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldFreezeAccount() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.FrozenAccount, Balance: decimal.NewFromInt(20)}
+	suite.service.On("Freeze", accountId, userId).Return(account, nil)
+	req, _ := http.NewRequest("POST", "/accounts/1/freeze", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"frozen\",\"balance\":\"20\",\"available_balance\":\"20\",\"overdraft_limit\":\"0\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldCloseAccountWithSweep() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	sweepTo := model.AccountId(2)
+	request := &dto.CloseAccountRequest{Id: accountId, SweepTo: &sweepTo}
+	account := &model.Account{Id: accountId, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Status: model.ClosedAccount, Balance: decimal.NewFromInt(0)}
+	suite.service.On("Close", request, userId).Return(account, nil)
+	req, _ := http.NewRequest("POST", "/accounts/1/close", bytes.NewReader([]byte(`{"sweep_to":2}`)))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusOK)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"id\":1,\"type\":\"checking\",\"currency\":\"EUR\",\"status\":\"closed\",\"balance\":\"0\",\"available_balance\":\"0\",\"overdraft_limit\":\"0\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotCloseAccountWithMoney() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	suite.service.On("Close", &dto.CloseAccountRequest{Id: accountId}, userId).Return(nil, &errors.NonZeroBalanceError{AccountId: accountId})
+	req, _ := http.NewRequest("POST", "/accounts/1/close", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusConflict)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The account 1 cannot be closed with a non-zero balance\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotTransferFromFrozenAccount() {
+	userId := model.UserId(1)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100)}
+	suite.service.On("Transfer", request, userId).Return(nil, &errors.AccountFrozenError{AccountId: 1})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusConflict)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The account 1 is frozen\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotTopUpClosedAccount() {
+	userId := model.UserId(1)
+	request := &dto.TopUpRequest{Id: 1, Amount: decimal.NewFromInt(100)}
+	suite.service.On("TopUp", request, userId).Return(nil, &errors.AccountClosedError{AccountId: 1})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/top-up", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusConflict)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The account 1 is closed\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *AccountApiSuite) TestShouldNotTransferWhenExchangeRateIsUnavailable() {
+	userId := model.UserId(1)
+	request := &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(100), Convert: true}
+	suite.service.On("Transfer", request, userId).Return(nil, &errors.FxRateUnavailableError{From: "EUR", To: "JPY"})
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/transfer", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), resp.Code, http.StatusBadRequest)
+	assert.Equal(suite.T(), resp.Body.String(), "{\"message\":\"The exchange rate from EUR to JPY is not available\"}\n")
+	suite.service.AssertExpectations(suite.T())
+}
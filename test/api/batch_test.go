@@ -0,0 +1,168 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/api"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	test_service "golang_bank_demo/test/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const pain001 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr><MsgId>rent-2023-06</MsgId><NbOfTxs>2</NbOfTxs><CtrlSum>110.50</CtrlSum></GrpHdr>
+    <PmtInf>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>rent</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">100.50</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>7</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>parking</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="EUR">10</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+type BatchApiSuite struct {
+	suite.Suite
+	service *test_service.StubBatchService
+	api     *mux.Router
+}
+
+func TestBatchApiSuite(t *testing.T) {
+	suite.Run(t, new(BatchApiSuite))
+}
+
+func (suite *BatchApiSuite) SetupTest() {
+	suite.service = new(test_service.StubBatchService)
+	authApi := api.NewAuthenticatedApi(service.NewStubAuthenticationService())
+	idempotentApi := api.NewIdempotentApi(new(test_service.StubIdempotencyService))
+	suite.api = api.NewBatchApi(suite.service, authApi, idempotentApi).Router()
+}
+
+func (suite *BatchApiSuite) post(url string, contentType string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	req.Header.Set("Content-Type", contentType)
+	resp := httptest.NewRecorder()
+	suite.api.ServeHTTP(resp, req)
+	return resp
+}
+
+func (suite *BatchApiSuite) TestShouldCreateBatchFromCsv() {
+	transferId := model.TransferId(9)
+	batch := &model.Batch{
+		Id:     3,
+		Owner:  1,
+		Mode:   model.BestEffortBatch,
+		Status: model.CompletedBatch,
+		Lines: []model.BatchLine{
+			{BatchId: 3, Line: 1, Reference: "rent", From: 1, To: 7, Amount: decimal.NewFromInt(60), Status: model.CompletedBatchLine, TransferId: &transferId},
+		},
+		CreatedAt: time.Date(2023, 5, 15, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Create", mock.MatchedBy(func(request *dto.BatchRequest) bool {
+		line := request.Lines[0]
+		return request.Mode == model.BestEffortBatch && len(request.Lines) == 1 && line.Line == 1 && line.Reference == "rent" &&
+			line.Transfer.From == 1 && line.Transfer.To == 7 && line.Transfer.Amount.String() == "60" && line.Err == nil
+	}), model.UserId(1)).Return(batch, nil)
+
+	resp := suite.post("/batches?mode=best_effort", "text/csv", "to,from,amount,reference\n7,1,60,rent\n")
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"mode\":\"best_effort\",\"status\":\"completed\",\"lines\":[{\"line\":1,\"reference\":\"rent\",\"from\":1,\"to\":7,"+
+		"\"amount\":\"60\",\"status\":\"completed\",\"transfer_id\":9}],\"created_at\":\"2023-05-15T10:00:00Z\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *BatchApiSuite) TestShouldReadTheInstructionsOfPain001() {
+	suite.service.On("Create", mock.MatchedBy(func(request *dto.BatchRequest) bool {
+		first, second := request.Lines[0], request.Lines[1]
+		return request.Mode == model.AllOrNothingBatch && len(request.Lines) == 2 &&
+			first.Reference == "rent" && first.Currency == "EUR" && first.Transfer.From == 1 && first.Transfer.To == 7 &&
+			first.Transfer.Amount.String() == "100.5" && first.Err == nil && second.Line == 2 &&
+			errors.NewValidationError("to", "The account has to be given by its id, IBANs are not supported").Error() == second.Err.Error()
+	}), model.UserId(1)).Return(&model.Batch{Id: 4}, nil)
+
+	resp := suite.post("/batches", "application/xml; charset=utf-8", pain001)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *BatchApiSuite) TestShouldNotCreateBatchWhenControlSumDoesNotMatch() {
+	resp := suite.post("/batches", "application/xml", strings.Replace(pain001, "110.50", "110", 1))
+
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"Invalid field 'file': The amounts of the file add up to 110.5 and not 110\"}\n", resp.Body.String())
+	suite.service.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+func (suite *BatchApiSuite) TestShouldNotCreateBatchFromCsvWithoutAmountColumn() {
+	resp := suite.post("/batches", "text/csv", "from,to\n1,7\n")
+
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"Invalid field 'file': The header has to contain the column amount\"}\n", resp.Body.String())
+}
+
+func (suite *BatchApiSuite) TestShouldNotCreateBatchFromJson() {
+	resp := suite.post("/batches", "application/json", "{}")
+
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"Invalid field 'content_type': The file has to be a pain.001 XML or a CSV file\"}\n", resp.Body.String())
+}
+
+func (suite *BatchApiSuite) TestShouldGetBatchWithTheErrorsOfItsLines() {
+	errorType, errorMessage := "BalanceTooLowError", "The account 1 does not have enough money, 10 more is needed"
+	batch := &model.Batch{
+		Id:     3,
+		Owner:  1,
+		Mode:   model.AllOrNothingBatch,
+		Status: model.FailedBatch,
+		Lines: []model.BatchLine{
+			{BatchId: 3, Line: 1, From: 1, To: 7, Amount: decimal.NewFromInt(60), Status: model.SkippedBatchLine},
+			{BatchId: 3, Line: 2, From: 1, To: 8, Amount: decimal.NewFromInt(50), Status: model.FailedBatchLine, ErrorType: &errorType, ErrorMessage: &errorMessage},
+		},
+		CreatedAt: time.Date(2023, 5, 15, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Get", model.BatchId(3), model.UserId(1)).Return(batch, nil)
+	req, _ := http.NewRequest("GET", "/batches/3", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"mode\":\"all_or_nothing\",\"status\":\"failed\",\"lines\":["+
+		"{\"line\":1,\"from\":1,\"to\":7,\"amount\":\"60\",\"status\":\"skipped\"},"+
+		"{\"line\":2,\"from\":1,\"to\":8,\"amount\":\"50\",\"status\":\"failed\",\"error_type\":\"BalanceTooLowError\","+
+		"\"error_message\":\"The account 1 does not have enough money, 10 more is needed\"}],\"created_at\":\"2023-05-15T10:00:00Z\"}\n", resp.Body.String())
+}
+
+func (suite *BatchApiSuite) TestShouldNotGetBatchThatDoesNotExist() {
+	suite.service.On("Get", model.BatchId(3), model.UserId(1)).Return(nil, &errors.BatchDoesNotExistError{BatchId: 3})
+	req, _ := http.NewRequest("GET", "/batches/3", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The batch 3 does not exist\"}\n", resp.Body.String())
+}
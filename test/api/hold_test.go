@@ -0,0 +1,121 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/api"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	test_service "golang_bank_demo/test/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type HoldApiSuite struct {
+	suite.Suite
+	service *test_service.StubHoldService
+	api     *mux.Router
+}
+
+func TestHoldApiSuite(t *testing.T) {
+	suite.Run(t, new(HoldApiSuite))
+}
+
+func (suite *HoldApiSuite) SetupTest() {
+	suite.service = new(test_service.StubHoldService)
+	authApi := api.NewAuthenticatedApi(service.NewStubAuthenticationService())
+	idempotentApi := api.NewIdempotentApi(new(test_service.StubIdempotencyService))
+	suite.api = api.NewHoldApi(suite.service, authApi, idempotentApi).Router()
+}
+
+func (suite *HoldApiSuite) TestShouldPlaceHold() {
+	request := &dto.HoldRequest{From: 1, To: 7, Amount: decimal.NewFromInt(80)}
+	hold := &model.Hold{
+		Id:        3,
+		From:      1,
+		To:        7,
+		Amount:    decimal.NewFromInt(80),
+		Fee:       decimal.RequireFromString("0.8"),
+		Status:    model.ActiveHold,
+		ExpiresAt: time.Date(2023, 5, 22, 10, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2023, 5, 15, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Place", request, model.UserId(1)).Return(hold, nil)
+	req, _ := http.NewRequest("POST", "/holds", strings.NewReader("{\"from\":1,\"to\":7,\"amount\":80}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"status\":\"active\",\"from\":1,\"to\":7,\"amount\":\"80\",\"fee\":\"0.8\",\"convert\":false,"+
+		"\"expires_at\":\"2023-05-22T10:00:00Z\",\"created_at\":\"2023-05-15T10:00:00Z\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *HoldApiSuite) TestShouldCapturePartOfHold() {
+	amount := decimal.NewFromInt(30)
+	transfer := &model.Transfer{
+		Id:           5,
+		From:         1,
+		To:           7,
+		Amount:       amount,
+		CreditAmount: amount,
+		Balance:      decimal.NewFromInt(70),
+		CreatedAt:    time.Date(2023, 5, 16, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Capture", &dto.CaptureHoldRequest{Id: 3, Amount: &amount}, model.UserId(1)).Return(transfer, nil)
+	req, _ := http.NewRequest("POST", "/holds/3/capture", strings.NewReader("{\"amount\":30}"))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":5,\"status\":\"completed\",\"from\":1,\"to\":7,\"amount\":\"30\",\"credit_amount\":\"30\","+
+		"\"fee\":\"0\",\"balance\":\"70\",\"created_at\":\"2023-05-16T10:00:00Z\"}\n", resp.Body.String())
+}
+
+func (suite *HoldApiSuite) TestShouldCaptureWholeHoldWithoutBody() {
+	suite.service.On("Capture", &dto.CaptureHoldRequest{Id: 3}, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil)
+	req, _ := http.NewRequest("POST", "/holds/3/capture", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *HoldApiSuite) TestShouldNotReleaseHoldThatIsNotActive() {
+	suite.service.On("Release", model.HoldId(3), model.UserId(1)).
+		Return(nil, &errors.HoldNotActiveError{HoldId: 3, Status: model.ExpiredHold})
+	req, _ := http.NewRequest("POST", "/holds/3/release", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The hold 3 is already expired\"}\n", resp.Body.String())
+}
+
+func (suite *HoldApiSuite) TestShouldNotGetHoldThatDoesNotExist() {
+	suite.service.On("Get", model.HoldId(3), model.UserId(1)).Return(nil, &errors.HoldDoesNotExistError{HoldId: 3})
+	req, _ := http.NewRequest("GET", "/holds/3", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The hold 3 does not exist\"}\n", resp.Body.String())
+}
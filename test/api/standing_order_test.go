@@ -0,0 +1,149 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/api"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	test_service "golang_bank_demo/test/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type StandingOrderApiSuite struct {
+	suite.Suite
+	service *test_service.StubStandingOrderService
+	api     *mux.Router
+}
+
+func TestStandingOrderApiSuite(t *testing.T) {
+	suite.Run(t, new(StandingOrderApiSuite))
+}
+
+func (suite *StandingOrderApiSuite) SetupTest() {
+	suite.service = new(test_service.StubStandingOrderService)
+	authApi := api.NewAuthenticatedApi(service.NewStubAuthenticationService())
+	idempotentApi := api.NewIdempotentApi(new(test_service.StubIdempotencyService))
+	suite.api = api.NewStandingOrderApi(suite.service, authApi, idempotentApi).Router()
+}
+
+func (suite *StandingOrderApiSuite) TestShouldCreateStandingOrder() {
+	endDate := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	request := &dto.StandingOrderRequest{
+		From: 1, To: 7, Amount: decimal.NewFromInt(100), Frequency: model.MonthlyFrequency, Day: 1, EndDate: dto.NewDate(endDate),
+	}
+	order := &model.StandingOrder{
+		Recurrence: model.Recurrence{Frequency: model.MonthlyFrequency, Day: 1},
+		Id:         3,
+		Owner:      1,
+		From:       1,
+		To:         7,
+		Amount:     decimal.NewFromInt(100),
+		EndDate:    &endDate,
+		NextRunOn:  time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		Status:     model.ActiveStandingOrder,
+		CreatedAt:  time.Date(2023, 5, 15, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Create", request, model.UserId(1)).Return(order, nil)
+	body := "{\"from\":1,\"to\":7,\"amount\":100,\"frequency\":\"monthly\",\"day\":1,\"end_date\":\"2023-12-31\"}"
+	req, _ := http.NewRequest("POST", "/standing-orders", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"status\":\"active\",\"from\":1,\"to\":7,\"amount\":\"100\",\"convert\":false,\"frequency\":\"monthly\","+
+		"\"day\":1,\"end_date\":\"2023-12-31\",\"next_run_on\":\"2023-06-01\",\"failures\":0,\"created_at\":\"2023-05-15T10:00:00Z\"}\n", resp.Body.String())
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderApiSuite) TestShouldNotCreateStandingOrderWithInvalidDate() {
+	body := "{\"from\":1,\"to\":7,\"amount\":100,\"frequency\":\"daily\",\"start_date\":\"01.06.2023\"}"
+	req, _ := http.NewRequest("POST", "/standing-orders", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The request is not a valid json\"}\n", resp.Body.String())
+}
+
+func (suite *StandingOrderApiSuite) TestShouldShowSuspendedStandingOrder() {
+	errorType, errorMessage := "BalanceTooLowError", "The account 1 does not have enough money"
+	order := &model.StandingOrder{
+		Recurrence:   model.Recurrence{Frequency: model.DailyFrequency},
+		Id:           3,
+		Owner:        1,
+		From:         1,
+		To:           7,
+		Amount:       decimal.NewFromInt(100),
+		NextRunOn:    time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		Status:       model.SuspendedStandingOrder,
+		Failures:     4,
+		ErrorType:    &errorType,
+		ErrorMessage: &errorMessage,
+		CreatedAt:    time.Date(2023, 5, 15, 10, 0, 0, 0, time.UTC),
+	}
+	suite.service.On("Get", model.StandingOrderId(3), model.UserId(1)).Return(order, nil)
+	req, _ := http.NewRequest("GET", "/standing-orders/3", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	assert.Equal(suite.T(), "{\"id\":3,\"status\":\"suspended\",\"from\":1,\"to\":7,\"amount\":\"100\",\"convert\":false,\"frequency\":\"daily\","+
+		"\"next_run_on\":\"2023-06-01\",\"failures\":4,\"error_type\":\"BalanceTooLowError\","+
+		"\"error_message\":\"The account 1 does not have enough money\",\"created_at\":\"2023-05-15T10:00:00Z\"}\n", resp.Body.String())
+}
+
+func (suite *StandingOrderApiSuite) TestShouldUpdateStandingOrder() {
+	request := &dto.StandingOrderRequest{Id: 3, From: 1, To: 7, Amount: decimal.NewFromInt(50), Frequency: model.WeeklyFrequency, Day: 5}
+	order := &model.StandingOrder{Id: 3, Status: model.ActiveStandingOrder}
+	suite.service.On("Update", request, model.UserId(1)).Return(order, nil)
+	body := "{\"from\":1,\"to\":7,\"amount\":50,\"frequency\":\"weekly\",\"day\":5}"
+	req, _ := http.NewRequest("PUT", "/standing-orders/3", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.Code)
+	suite.service.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderApiSuite) TestShouldNotCancelStandingOrderTwice() {
+	suite.service.On("Cancel", model.StandingOrderId(3), model.UserId(1)).
+		Return(nil, &errors.StandingOrderEndedError{StandingOrderId: 3, Status: model.CancelledStandingOrder})
+	req, _ := http.NewRequest("DELETE", "/standing-orders/3", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusConflict, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The standing order 3 is already cancelled\"}\n", resp.Body.String())
+}
+
+func (suite *StandingOrderApiSuite) TestShouldNotGetStandingOrderThatDoesNotExist() {
+	suite.service.On("Get", model.StandingOrderId(9), model.UserId(1)).
+		Return(nil, &errors.StandingOrderDoesNotExistError{StandingOrderId: 9})
+	req, _ := http.NewRequest("GET", "/standing-orders/9", nil)
+	req.Header.Set("Authorization", "Bearer token_user_1")
+	resp := httptest.NewRecorder()
+
+	suite.api.ServeHTTP(resp, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.Code)
+	assert.Equal(suite.T(), "{\"message\":\"The standing order 9 does not exist\"}\n", resp.Body.String())
+}
@@ -0,0 +1,8 @@
+package errors
+
+// TimeoutError is not a pointer, like the errors that some drivers return
+type TimeoutError struct{}
+
+func (TimeoutError) Error() string {
+	return "timeout"
+}
@@ -0,0 +1,32 @@
+package errors
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"testing"
+)
+
+type TypeNameSuite struct {
+	suite.Suite
+}
+
+func TestTypeNameSuite(t *testing.T) {
+	suite.Run(t, new(TypeNameSuite))
+}
+
+func (suite *TypeNameSuite) TestShouldLeaveOutThePointer() {
+	assert.Equal(suite.T(), "BalanceTooLowError", errors.TypeName(&errors.BalanceTooLowError{AccountId: 1}))
+}
+
+func (suite *TypeNameSuite) TestShouldNameErrorThatIsNotAPointer() {
+	assert.Equal(suite.T(), "TimeoutError", errors.TypeName(TimeoutError{}))
+}
+
+func (suite *TypeNameSuite) TestShouldDescribeErrorOfUnnamedType() {
+	assert.Equal(suite.T(), "struct { error }", errors.TypeName(struct{ error }{TimeoutError{}}))
+}
+
+func (suite *TypeNameSuite) TestShouldNotNameNoError() {
+	assert.Equal(suite.T(), "", errors.TypeName(nil))
+}
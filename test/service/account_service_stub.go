@@ -10,8 +10,8 @@ type StubAccountService struct {
 	mock.Mock
 }
 
-func (service *StubAccountService) Create(user model.UserId) (*model.Account, error) {
-	args := service.Called(user)
+func (service *StubAccountService) Create(request *dto.CreateAccountRequest, user model.UserId) (*model.Account, error) {
+	args := service.Called(request, user)
 	if account, ok := args.Get(0).(*model.Account); ok {
 		return account, args.Error(1)
 	} else {
@@ -28,12 +28,133 @@ func (service *StubAccountService) Get(accountId model.AccountId, user model.Use
 	}
 }
 
-func (service *StubAccountService) TopUp(request *dto.TopUpRequest, user model.UserId) error {
+func (service *StubAccountService) List(user model.UserId) ([]model.Account, error) {
+	args := service.Called(user)
+	if accounts, ok := args.Get(0).([]model.Account); ok {
+		return accounts, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) TopUp(request *dto.TopUpRequest, user model.UserId) (*model.LedgerEntry, error) {
 	args := service.Called(request, user)
-	return args.Error(0)
+	if entry, ok := args.Get(0).(*model.LedgerEntry); ok {
+		return entry, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) Transfer(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error) {
+	args := service.Called(request, user)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) Quote(request *dto.TransferRequest, user model.UserId) (*model.Transfer, error) {
+	args := service.Called(request, user)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) TransferAll(requests []*dto.TransferRequest, user model.UserId) ([]model.Transfer, error) {
+	args := service.Called(requests, user)
+	if transfers, ok := args.Get(0).([]model.Transfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) TransferLegs(request *dto.TransferLegsRequest, user model.UserId) ([]model.Transfer, error) {
+	args := service.Called(request, user)
+	if transfers, ok := args.Get(0).([]model.Transfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) GetTopUp(topUpId model.LedgerEntryId, user model.UserId) (*model.LedgerEntry, error) {
+	args := service.Called(topUpId, user)
+	if entry, ok := args.Get(0).(*model.LedgerEntry); ok {
+		return entry, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) GetTransfer(transferId model.TransferId, user model.UserId) (*model.Transfer, error) {
+	args := service.Called(transferId, user)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) Schedule(request *dto.TransferRequest, user model.UserId) (*model.ScheduledTransfer, error) {
+	args := service.Called(request, user)
+	if transfer, ok := args.Get(0).(*model.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) ScheduledTransfers(user model.UserId) ([]model.ScheduledTransfer, error) {
+	args := service.Called(user)
+	if transfers, ok := args.Get(0).([]model.ScheduledTransfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) CancelScheduled(id model.ScheduledTransferId, user model.UserId) (*model.ScheduledTransfer, error) {
+	args := service.Called(id, user)
+	if transfer, ok := args.Get(0).(*model.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
 }
 
-func (service *StubAccountService) Transfer(request *dto.TransferRequest, user model.UserId) error {
+func (service *StubAccountService) Transactions(request *dto.TransactionsRequest, user model.UserId) (*model.LedgerPage, error) {
 	args := service.Called(request, user)
+	if page, ok := args.Get(0).(*model.LedgerPage); ok {
+		return page, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) Statement(request *dto.StatementRequest, user model.UserId, writer model.StatementWriter) error {
+	args := service.Called(request, user, writer)
 	return args.Error(0)
 }
+
+func (service *StubAccountService) Freeze(accountId model.AccountId, user model.UserId) (*model.Account, error) {
+	args := service.Called(accountId, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAccountService) Close(request *dto.CloseAccountRequest, user model.UserId) (*model.Account, error) {
+	args := service.Called(request, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
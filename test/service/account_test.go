@@ -3,19 +3,26 @@ package service
 import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"golang_bank_demo/src/dto"
 	"golang_bank_demo/src/errors"
 	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/statement"
 	"golang_bank_demo/test/storage"
+	"io"
 	"testing"
+	"time"
 )
 
 type AccountServiceSuite struct {
 	suite.Suite
-	storage *storage.StubAccountStorage
-	service service.AccountService
+	storage   *storage.StubAccountStorage
+	ledger    *storage.StubLedgerStorage
+	scheduled *storage.StubScheduledTransferStorage
+	limits    model.Limits
+	service   service.AccountService
 }
 
 func TestAccountServiceSuite(t *testing.T) {
@@ -24,25 +31,74 @@ func TestAccountServiceSuite(t *testing.T) {
 
 func (suite *AccountServiceSuite) SetupTest() {
 	suite.storage = new(storage.StubAccountStorage)
-	suite.service = service.NewAccountService(suite.storage)
+	suite.ledger = new(storage.StubLedgerStorage)
+	suite.scheduled = new(storage.StubScheduledTransferStorage)
+	suite.limits = model.Limits{"EUR": {
+		Account: model.TransferLimits{PerTransactionLimit: decimal.NewNullDecimal(decimal.NewFromInt(1000))},
+		User:    model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(5000))},
+	}}
+	suite.service = service.NewAccountService(suite.storage, suite.ledger, suite.scheduled, service.NewStaticFxRateProvider(map[string]decimal.Decimal{
+		"EUR/USD": decimal.RequireFromString("1.13"),
+		"VND/EUR": decimal.RequireFromString("0.000038"),
+	}), service.NewStaticFeeProvider(model.FeeSchedule{
+		{Operation: model.TransferOperation, AccountType: model.SavingsAccount, Flat: decimal.NewFromInt(1)},
+		{Operation: model.TopUpOperation, AccountType: model.SavingsAccount, Rate: decimal.RequireFromString("0.01")},
+	}), suite.limits)
 }
 
 func (suite *AccountServiceSuite) TestShouldCreateAnAccount() {
 	userId := model.UserId(1)
-	account := &model.Account{Id: 1, Owner: userId, Balance: decimal.NewFromInt(20)}
-	suite.storage.On("Create", userId).Return(account, nil)
+	account := &model.Account{Id: 1, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Balance: decimal.NewFromInt(20)}
+	suite.storage.On("Create", &model.Account{Owner: userId, Type: model.CheckingAccount, Currency: "EUR"}).Return(account, nil)
 
-	createdAccount, err := suite.service.Create(userId)
+	createdAccount, err := suite.service.Create(&dto.CreateAccountRequest{}, userId)
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), createdAccount, account)
 	suite.storage.AssertExpectations(suite.T())
 }
 
+func (suite *AccountServiceSuite) TestShouldCreateANamedSavingsAccount() {
+	userId := model.UserId(1)
+	account := &model.Account{Id: 1, Owner: userId, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR", Balance: decimal.NewFromInt(0)}
+	suite.storage.On("Create", &model.Account{Owner: userId, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"}).Return(account, nil)
+
+	createdAccount, err := suite.service.Create(&dto.CreateAccountRequest{Name: "Holidays", Type: model.SavingsAccount}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), createdAccount, account)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotCreateAnAccountOfUnknownType() {
+	userId := model.UserId(1)
+
+	createdAccount, err := suite.service.Create(&dto.CreateAccountRequest{Type: "credit"}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "type", Message: "The type has to be one of 'checking', 'savings' or 'sub_account'"})
+	assert.Nil(suite.T(), createdAccount)
+	suite.storage.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldListAccountsOfUser() {
+	userId := model.UserId(1)
+	accounts := []model.Account{
+		{Id: 1, Owner: userId, Type: model.CheckingAccount, Currency: "EUR", Balance: decimal.NewFromInt(20)},
+		{Id: 2, Owner: userId, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR", Balance: decimal.NewFromInt(5)},
+	}
+	suite.storage.On("ListByOwner", userId).Return(accounts, nil)
+
+	foundAccounts, err := suite.service.List(userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), accounts, foundAccounts)
+	suite.storage.AssertExpectations(suite.T())
+}
+
 func (suite *AccountServiceSuite) TestShouldGetAnAccount() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(20)}
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(20)}
 	suite.storage.On("Get", accountId).Return(account, nil)
 
 	foundAccount, err := suite.service.Get(accountId, userId)
@@ -55,7 +111,7 @@ func (suite *AccountServiceSuite) TestShouldGetAnAccount() {
 func (suite *AccountServiceSuite) TestShouldNotGetAnAccountWhenDifferentUser() {
 	accountId := model.AccountId(1)
 	anotherUserId := model.UserId(2)
-	account := &model.Account{Id: accountId, Owner: model.UserId(1), Balance: decimal.NewFromInt(20)}
+	account := &model.Account{Id: accountId, Owner: model.UserId(1), Currency: "EUR", Balance: decimal.NewFromInt(20)}
 	suite.storage.On("Get", accountId).Return(account, nil)
 
 	foundAccount, err := suite.service.Get(accountId, anotherUserId)
@@ -69,13 +125,15 @@ func (suite *AccountServiceSuite) TestShouldTopUpAnAccount() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", accountId).Return(account, nil)
-	suite.storage.On("TopUp", accountId, amount).Return(nil)
+	entry := &model.LedgerEntry{Id: 4, AccountId: accountId, Type: model.TopUpEntry, Amount: amount, Balance: decimal.NewFromInt(30)}
+	suite.storage.On("TopUp", accountId, amount, decimal.Zero).Return(entry, nil)
 
-	err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
+	receipt, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
 
 	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entry, receipt)
 	suite.storage.AssertExpectations(suite.T())
 }
 
@@ -84,42 +142,42 @@ func (suite *AccountServiceSuite) TestShouldNotTopUpAnAccountWhenDifferentUser()
 	anotherUserId := model.UserId(2)
 	accountId := model.AccountId(1)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", accountId).Return(account, nil)
-	suite.storage.On("TopUp", accountId, amount).Return(nil)
+	suite.storage.On("TopUp", accountId, amount, decimal.Zero).Return(nil, nil)
 
-	err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, anotherUserId)
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, anotherUserId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: accountId, UserId: anotherUserId})
-	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldTopUpAnAccountWhenTheBalanceIsNotPositive() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(1)
 	amount := decimal.NewFromInt(-20)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", accountId).Return(account, nil)
-	suite.storage.On("TopUp", accountId, amount).Return(nil)
+	suite.storage.On("TopUp", accountId, amount, decimal.Zero).Return(nil, nil)
 
-	err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount has to be positive"})
-	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldTopUpAnAccountWhenTheIdIsNotPositive() {
 	userId := model.UserId(1)
 	accountId := model.AccountId(-1)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: accountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", accountId).Return(account, nil)
-	suite.storage.On("TopUp", accountId, amount).Return(nil)
+	suite.storage.On("TopUp", accountId, amount, decimal.Zero).Return(nil, nil)
 
-	err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "id", Message: "The id has to be positive"})
-	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldTransfer() {
@@ -127,30 +185,84 @@ func (suite *AccountServiceSuite) TestShouldTransfer() {
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(2)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "EUR", Balance: decimal.NewFromInt(0)}, nil)
+	created := &model.Transfer{Id: 7, From: fromAccountId, To: toAccountId, Amount: amount, CreditAmount: amount}
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(created, nil)
+
+	transfer, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), created, transfer)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldChargeTheFeeOfTheSourceAccountType() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	amount := decimal.NewFromInt(20)
+	suite.storage.On("Get", fromAccountId).Return(&model.Account{Id: fromAccountId, Owner: userId, Type: model.SavingsAccount, Currency: "EUR"}, nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Type: model.CheckingAccount, Currency: "EUR"}, nil)
+	suite.storage.On("TransferWithinLimits", mock.MatchedBy(func(transfer *model.Transfer) bool {
+		return transfer.Amount.Equal(amount) && transfer.Fee.Equal(decimal.NewFromInt(1))
+	}), suite.limits).Return(&model.Transfer{Id: 7}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+
+	assert.NoError(suite.T(), err)
+	suite.storage.AssertExpectations(suite.T())
+}
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+func (suite *AccountServiceSuite) TestShouldQuoteTheFeeOfTheSourceAccountType() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Type: model.SavingsAccount, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Type: model.CheckingAccount, Currency: "EUR"}, nil)
+
+	transfer, err := suite.service.Quote(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(20)}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", transfer.Fee.String())
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits")
+}
+
+func (suite *AccountServiceSuite) TestShouldChargeTheFeeOfTopUp() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	amount := decimal.NewFromInt(250)
+	suite.storage.On("Get", accountId).Return(&model.Account{Id: accountId, Owner: userId, Type: model.SavingsAccount, Currency: "EUR"}, nil)
+	suite.storage.On("TopUp", accountId, amount, mock.MatchedBy(func(fee decimal.Decimal) bool {
+		return fee.Equal(decimal.RequireFromString("2.5"))
+	})).Return(&model.LedgerEntry{Id: 4}, nil)
+
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
 
 	assert.NoError(suite.T(), err)
 	suite.storage.AssertExpectations(suite.T())
 }
 
+func (suite *AccountServiceSuite) TestShouldNotTransferToTheSameAccount() {
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: 1, To: 1, Amount: decimal.NewFromInt(20)}, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("to", "The money has to go to another account"))
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
 func (suite *AccountServiceSuite) TestShouldNotTransferWhenAccountOfDifferentUser() {
 	userId := model.UserId(1)
 	anotherUserId := model.UserId(2)
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(2)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(nil)
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(nil, nil)
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, anotherUserId)
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, anotherUserId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: fromAccountId, UserId: anotherUserId})
-	suite.storage.AssertNotCalled(suite.T(), "Transfer", fromAccountId, toAccountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldNotTransferWhenFromIdIsNotPositive() {
@@ -158,14 +270,14 @@ func (suite *AccountServiceSuite) TestShouldNotTransferWhenFromIdIsNotPositive()
 	fromAccountId := model.AccountId(-1)
 	toAccountId := model.AccountId(2)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(nil)
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(nil, nil)
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "from", Message: "The id has to be positive"})
-	suite.storage.AssertNotCalled(suite.T(), "Transfer", fromAccountId, toAccountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldNotTransferWhenToIdIsNotPositive() {
@@ -173,14 +285,14 @@ func (suite *AccountServiceSuite) TestShouldNotTransferWhenToIdIsNotPositive() {
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(-2)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(nil)
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(nil, nil)
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "to", Message: "The id has to be positive"})
-	suite.storage.AssertNotCalled(suite.T(), "Transfer", fromAccountId, toAccountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldNotTransferWhenAmountIsNotPositive() {
@@ -188,14 +300,14 @@ func (suite *AccountServiceSuite) TestShouldNotTransferWhenAmountIsNotPositive()
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(2)
 	amount := decimal.NewFromInt(-20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(nil)
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(nil, nil)
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount has to be positive"})
-	suite.storage.AssertNotCalled(suite.T(), "Transfer", fromAccountId, toAccountId, amount)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
 }
 
 func (suite *AccountServiceSuite) TestShouldNotTransferWhenStorageErrors() {
@@ -203,12 +315,506 @@ func (suite *AccountServiceSuite) TestShouldNotTransferWhenStorageErrors() {
 	fromAccountId := model.AccountId(1)
 	toAccountId := model.AccountId(2)
 	amount := decimal.NewFromInt(20)
-	account := &model.Account{Id: fromAccountId, Owner: userId, Balance: decimal.NewFromInt(10)}
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(10)}
 	suite.storage.On("Get", fromAccountId).Return(account, nil)
-	suite.storage.On("Transfer", fromAccountId, toAccountId, amount).Return(&errors.BalanceTooLowError{AccountId: fromAccountId})
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "EUR", Balance: decimal.NewFromInt(0)}, nil)
+	suite.storage.On("TransferWithinLimits", model.NewTransfer(fromAccountId, toAccountId, amount), suite.limits).Return(nil, &errors.BalanceTooLowError{AccountId: fromAccountId})
 
-	err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
 
 	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: fromAccountId})
 	suite.storage.AssertExpectations(suite.T())
 }
+
+func (suite *AccountServiceSuite) TestShouldTransferAll() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Owner: 3, Currency: "EUR"}, nil)
+	transfers := []*model.Transfer{model.NewTransfer(1, 2, decimal.NewFromInt(20)), model.NewTransfer(1, 3, decimal.NewFromInt(30))}
+	created := []model.Transfer{{Id: 7}, {Id: 8}}
+	suite.storage.On("TransferAll", transfers, suite.limits).Return(created, nil)
+
+	result, err := suite.service.TransferAll([]*dto.TransferRequest{
+		{From: 1, To: 2, Amount: decimal.NewFromInt(20)},
+		{From: 1, To: 3, Amount: decimal.NewFromInt(30)},
+	}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), created, result)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferAnyWhenOneIsInvalid() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Currency: "EUR"}, nil)
+
+	_, err := suite.service.TransferAll([]*dto.TransferRequest{
+		{From: 1, To: 2, Amount: decimal.NewFromInt(20)},
+		{From: 1, To: 2, Amount: decimal.NewFromInt(-5)},
+	}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 1})
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount has to be positive"})
+	suite.storage.AssertNotCalled(suite.T(), "TransferAll", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldTransferLegs() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Currency: "EUR"}, nil)
+	suite.storage.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Owner: 3, Currency: "EUR"}, nil)
+	transfers := []*model.Transfer{model.NewTransfer(1, 2, decimal.NewFromInt(20)), model.NewTransfer(1, 3, decimal.NewFromInt(30))}
+	created := []model.Transfer{{Id: 7}, {Id: 8}}
+	suite.storage.On("TransferLegs", transfers, suite.limits).Return(created, nil)
+
+	result, err := suite.service.TransferLegs(&dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{
+		{To: 2, Amount: decimal.NewFromInt(20)},
+		{To: 3, Amount: decimal.NewFromInt(30)},
+	}}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), created, result)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferLegsFromAccountOfDifferentUser() {
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: 1, Currency: "EUR"}, nil)
+
+	_, err := suite.service.TransferLegs(&dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{{To: 2, Amount: decimal.NewFromInt(20)}}}, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+	suite.storage.AssertNotCalled(suite.T(), "TransferLegs", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferLegsWhenOneLegIsInvalid() {
+	_, err := suite.service.TransferLegs(&dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{
+		{To: 2, Amount: decimal.NewFromInt(20)},
+		{To: 0, Amount: decimal.NewFromInt(30)},
+	}}, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 1})
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "to", Message: "The id has to be positive"})
+	suite.storage.AssertNotCalled(suite.T(), "Get", mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferLegBackToTheSourceAccount() {
+	_, err := suite.service.TransferLegs(&dto.TransferLegsRequest{From: 1, Legs: []dto.TransferLeg{{To: 1, Amount: decimal.NewFromInt(20)}}}, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 0})
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("to", "The money has to go to another account"))
+}
+
+func (suite *AccountServiceSuite) TestShouldGetTransactionsWithNextCursor() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(30)}
+	entries := []model.LedgerEntry{
+		{Id: 3, AccountId: accountId, Type: model.TopUpEntry, Amount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(30)},
+		{Id: 2, AccountId: accountId, Type: model.TopUpEntry, Amount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(20)},
+		{Id: 1, AccountId: accountId, Type: model.TopUpEntry, Amount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(10)},
+	}
+	suite.storage.On("Get", accountId).Return(account, nil)
+	suite.ledger.On("List", accountId, &model.LedgerFilter{Limit: 3}).Return(entries, nil)
+
+	page, err := suite.service.Transactions(&dto.TransactionsRequest{AccountId: accountId, Limit: 2}, userId)
+
+	assert.NoError(suite.T(), err)
+	nextCursor := model.LedgerEntryId(2)
+	assert.Equal(suite.T(), &model.LedgerPage{Entries: entries[:2], Next: &nextCursor}, page)
+	suite.storage.AssertExpectations(suite.T())
+	suite.ledger.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldGetLastPageOfTransactions() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	cursor := model.LedgerEntryId(2)
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(30)}
+	entries := []model.LedgerEntry{
+		{Id: 1, AccountId: accountId, Type: model.TopUpEntry, Amount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(10)},
+	}
+	suite.storage.On("Get", accountId).Return(account, nil)
+	suite.ledger.On("List", accountId, &model.LedgerFilter{Before: &cursor, Limit: 3}).Return(entries, nil)
+
+	page, err := suite.service.Transactions(&dto.TransactionsRequest{AccountId: accountId, Cursor: &cursor, Limit: 2}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), &model.LedgerPage{Entries: entries}, page)
+	suite.ledger.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotGetTransactionsWhenDifferentUser() {
+	accountId := model.AccountId(1)
+	anotherUserId := model.UserId(2)
+	account := &model.Account{Id: accountId, Owner: model.UserId(1), Currency: "EUR", Balance: decimal.NewFromInt(20)}
+	suite.storage.On("Get", accountId).Return(account, nil)
+
+	page, err := suite.service.Transactions(&dto.TransactionsRequest{AccountId: accountId, Limit: 2}, anotherUserId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: accountId, UserId: anotherUserId})
+	assert.Nil(suite.T(), page)
+	suite.ledger.AssertNotCalled(suite.T(), "List", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotGetTransactionsWhenUnknownType() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+
+	page, err := suite.service.Transactions(&dto.TransactionsRequest{AccountId: accountId, Limit: 2, Types: []model.LedgerEntryType{"refund"}}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "type", Message: "Unknown transaction type 'refund'"})
+	assert.Nil(suite.T(), page)
+	suite.storage.AssertNotCalled(suite.T(), "Get", accountId)
+}
+
+func (suite *AccountServiceSuite) TestShouldWriteStatement() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(20)}
+	from, to := date(2023, 1, 1), date(2023, 2, 1)
+	writer := statement.NewWriter(model.CsvStatement, io.Discard)
+	suite.storage.On("Get", accountId).Return(account, nil)
+	suite.ledger.On("Statement", mock.MatchedBy(func(statement *model.Statement) bool {
+		return statement.Account == account && statement.From.Equal(from) && statement.To.Equal(to) && !statement.CreatedAt.IsZero()
+	}), writer).Return(nil)
+
+	err := suite.service.Statement(&dto.StatementRequest{AccountId: accountId, From: &from, To: &to, Format: model.CsvStatement}, userId, writer)
+
+	assert.NoError(suite.T(), err)
+	suite.ledger.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotWriteStatementWhenDifferentUser() {
+	accountId := model.AccountId(1)
+	anotherUserId := model.UserId(2)
+	account := &model.Account{Id: accountId, Owner: model.UserId(1), Currency: "EUR", Balance: decimal.NewFromInt(20)}
+	from, to := date(2023, 1, 1), date(2023, 2, 1)
+	suite.storage.On("Get", accountId).Return(account, nil)
+
+	err := suite.service.Statement(&dto.StatementRequest{AccountId: accountId, From: &from, To: &to, Format: model.CsvStatement}, anotherUserId, nil)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: accountId, UserId: anotherUserId})
+	suite.ledger.AssertNotCalled(suite.T(), "Statement", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotWriteStatementWhenPeriodIsEmpty() {
+	accountId := model.AccountId(1)
+	from := date(2023, 1, 1)
+
+	err := suite.service.Statement(&dto.StatementRequest{AccountId: accountId, From: &from, To: &from, Format: model.CsvStatement}, model.UserId(1), nil)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "to", Message: "The end of the period has to be after the start"})
+	suite.storage.AssertNotCalled(suite.T(), "Get", accountId)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotWriteStatementInUnknownFormat() {
+	accountId := model.AccountId(1)
+	from, to := date(2023, 1, 1), date(2023, 2, 1)
+
+	err := suite.service.Statement(&dto.StatementRequest{AccountId: accountId, From: &from, To: &to, Format: "pdf"}, model.UserId(1), nil)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "format", Message: "The format has to be csv, json, txt or camt053"})
+	suite.storage.AssertNotCalled(suite.T(), "Get", accountId)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTopUpWhenAmountIsTooPreciseForCurrency() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	amount := decimal.RequireFromString("20.5")
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "JPY", Balance: decimal.NewFromInt(10)}
+	suite.storage.On("Get", accountId).Return(account, nil)
+
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: amount}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount can have at most 0 decimal places in JPY"})
+	suite.storage.AssertNotCalled(suite.T(), "TopUp", accountId, amount, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferWhenAmountIsTooPreciseForCurrency() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	amount := decimal.RequireFromString("20.001")
+	account := &model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(100)}
+	suite.storage.On("Get", fromAccountId).Return(account, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount can have at most 2 decimal places in EUR"})
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferBetweenDifferentCurrencies() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	amount := decimal.NewFromInt(20)
+	suite.storage.On("Get", fromAccountId).Return(&model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(100)}, nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "USD", Balance: decimal.NewFromInt(0)}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: amount}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.CurrencyMismatchError{From: fromAccountId, To: toAccountId, FromCurrency: "EUR", ToCurrency: "USD"})
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldCreateAnAccountInChosenCurrency() {
+	userId := model.UserId(1)
+	account := &model.Account{Id: 1, Owner: userId, Type: model.CheckingAccount, Currency: "USD", Balance: decimal.NewFromInt(0)}
+	suite.storage.On("Create", &model.Account{Owner: userId, Type: model.CheckingAccount, Currency: "USD"}).Return(account, nil)
+
+	createdAccount, err := suite.service.Create(&dto.CreateAccountRequest{Currency: "USD"}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), createdAccount, account)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotCreateAnAccountInUnknownCurrency() {
+	userId := model.UserId(1)
+
+	createdAccount, err := suite.service.Create(&dto.CreateAccountRequest{Currency: "usd"}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "currency", Message: "The currency has to be a supported ISO 4217 code"})
+	assert.Nil(suite.T(), createdAccount)
+	suite.storage.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldTransferWithConversion() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	suite.storage.On("Get", fromAccountId).Return(&model.Account{Id: fromAccountId, Owner: userId, Currency: "USD", Balance: decimal.NewFromInt(100)}, nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "EUR", Balance: decimal.NewFromInt(0)}, nil)
+	rate := decimal.NewFromInt(1).Div(decimal.RequireFromString("1.13"))
+	suite.storage.On("TransferWithinLimits", &model.Transfer{
+		From:         fromAccountId,
+		To:           toAccountId,
+		Amount:       decimal.NewFromInt(50),
+		CreditAmount: decimal.RequireFromString("44.25"),
+		FxRate:       decimal.NullDecimal{Decimal: rate, Valid: true},
+		Fee:          decimal.Zero,
+	}, suite.limits).Return(&model.Transfer{Id: 1}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(50), Convert: true}, userId)
+
+	assert.NoError(suite.T(), err)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferWithConversionWhenRateIsUnavailable() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	suite.storage.On("Get", fromAccountId).Return(&model.Account{Id: fromAccountId, Owner: userId, Currency: "EUR", Balance: decimal.NewFromInt(100)}, nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "JPY", Balance: decimal.NewFromInt(0)}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(50), Convert: true}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.FxRateUnavailableError{From: "EUR", To: "JPY"})
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferWithConversionWhenConvertedAmountRoundsToZero() {
+	userId := model.UserId(1)
+	fromAccountId := model.AccountId(1)
+	toAccountId := model.AccountId(2)
+	suite.storage.On("Get", fromAccountId).Return(&model.Account{Id: fromAccountId, Owner: userId, Currency: "VND", Balance: decimal.NewFromInt(100000)}, nil)
+	suite.storage.On("Get", toAccountId).Return(&model.Account{Id: toAccountId, Owner: 2, Currency: "EUR", Balance: decimal.NewFromInt(0)}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: fromAccountId, To: toAccountId, Amount: decimal.NewFromInt(100), Convert: true}, userId)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "amount", Message: "The amount is too small to be converted to EUR"})
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldFreezeAnAccount() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.ActiveAccount}
+	frozenAccount := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.FrozenAccount}
+	suite.storage.On("Get", accountId).Return(account, nil)
+	suite.storage.On("SetStatus", accountId, model.FrozenAccount).Return(frozenAccount, nil)
+
+	result, err := suite.service.Freeze(accountId, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), frozenAccount, result)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldCloseAnAccountWithSweep() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	sweepTo := model.AccountId(2)
+	account := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.ActiveAccount, Balance: decimal.NewFromInt(10)}
+	closedAccount := &model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.ClosedAccount, Balance: decimal.NewFromInt(0)}
+	suite.storage.On("Get", accountId).Return(account, nil)
+	suite.storage.On("Get", sweepTo).Return(&model.Account{Id: sweepTo, Owner: userId, Type: model.SavingsAccount, Currency: "EUR", Status: model.ActiveAccount}, nil)
+	suite.storage.On("Close", accountId, &sweepTo).Return(closedAccount, nil)
+
+	result, err := suite.service.Close(&dto.CloseAccountRequest{Id: accountId, SweepTo: &sweepTo}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), closedAccount, result)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceSuite) TestShouldNotCloseAnAccountIntoItself() {
+	accountId := model.AccountId(1)
+
+	_, err := suite.service.Close(&dto.CloseAccountRequest{Id: accountId, SweepTo: &accountId}, 1)
+
+	assert.Equal(suite.T(), errors.NewValidationError("sweep_to", "The remaining money cannot be swept to the closed account"), err)
+	suite.storage.AssertNotCalled(suite.T(), "Close", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTopUpAFrozenAccount() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	suite.storage.On("Get", accountId).Return(&model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.FrozenAccount}, nil)
+
+	_, err := suite.service.TopUp(&dto.TopUpRequest{Id: accountId, Amount: decimal.NewFromInt(10)}, userId)
+
+	assert.Equal(suite.T(), &errors.AccountFrozenError{AccountId: accountId}, err)
+	suite.storage.AssertNotCalled(suite.T(), "TopUp", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferToAClosedAccount() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR", Status: model.ActiveAccount}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Currency: "EUR", Status: model.ClosedAccount}, nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10)}, userId)
+
+	assert.Equal(suite.T(), &errors.AccountClosedError{AccountId: 2}, err)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferToASystemAccount() {
+	userId := model.UserId(1)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR", Status: model.ActiveAccount}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(model.NewSystemAccount(model.CashInClearingAccount, "EUR"), nil)
+
+	_, err := suite.service.Transfer(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10)}, userId)
+
+	assert.Equal(suite.T(), &errors.AccountDoesNotExistError{AccountId: 2}, err)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotSweepToASystemAccount() {
+	userId := model.UserId(1)
+	accountId := model.AccountId(1)
+	sweepTo := model.AccountId(2)
+	suite.storage.On("Get", accountId).Return(&model.Account{Id: accountId, Owner: userId, Currency: "EUR", Status: model.ActiveAccount}, nil)
+	suite.storage.On("Get", sweepTo).Return(model.NewSystemAccount(model.FeesAccount, "EUR"), nil)
+
+	_, err := suite.service.Close(&dto.CloseAccountRequest{Id: accountId, SweepTo: &sweepTo}, userId)
+
+	assert.Equal(suite.T(), &errors.AccountDoesNotExistError{AccountId: sweepTo}, err)
+	suite.storage.AssertNotCalled(suite.T(), "Close", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldGetTransferOfOwnAccount() {
+	userId := model.UserId(1)
+	transfer := &model.Transfer{Id: 3, From: 1, To: 2, Amount: decimal.NewFromInt(10), CreditAmount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(90)}
+	suite.storage.On("GetTransfer", model.TransferId(3)).Return(transfer, nil)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR"}, nil)
+
+	found, err := suite.service.GetTransfer(3, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), transfer, found)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotTransferTwiceForTheSameOrigin() {
+	origin := "scheduled_transfer:3"
+	made := &model.Transfer{Id: 7, From: 1, To: 2, Amount: decimal.NewFromInt(10), CreditAmount: decimal.NewFromInt(10), Origin: &origin}
+	suite.storage.On("GetTransferByOrigin", origin).Return(made, nil)
+
+	transfer, err := suite.service.Transfer(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10), Origin: &origin}, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), made, transfer)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotGetTransferOfAnotherUser() {
+	transfer := &model.Transfer{Id: 3, From: 1, To: 2, Amount: decimal.NewFromInt(10), CreditAmount: decimal.NewFromInt(10)}
+	suite.storage.On("GetTransfer", model.TransferId(3)).Return(transfer, nil)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: 1, Currency: "EUR"}, nil)
+
+	found, err := suite.service.GetTransfer(3, 2)
+
+	assert.Equal(suite.T(), &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2}, err)
+	assert.Nil(suite.T(), found)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotGetTopUpOfAnotherUser() {
+	entry := &model.LedgerEntry{Id: 4, AccountId: 1, Type: model.TopUpEntry, Amount: decimal.NewFromInt(10), Balance: decimal.NewFromInt(10)}
+	suite.ledger.On("GetTopUp", model.LedgerEntryId(4)).Return(entry, nil)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: 1, Currency: "EUR"}, nil)
+
+	found, err := suite.service.GetTopUp(4, 2)
+
+	assert.Equal(suite.T(), &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2}, err)
+	assert.Nil(suite.T(), found)
+}
+
+func (suite *AccountServiceSuite) TestShouldScheduleTransfer() {
+	userId := model.UserId(1)
+	executeAt := time.Now().Add(time.Hour)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: userId, Currency: "EUR", Balance: decimal.Zero}, nil)
+	suite.storage.On("Get", model.AccountId(2)).Return(&model.Account{Id: 2, Owner: 2, Currency: "EUR"}, nil)
+	scheduled := &model.ScheduledTransfer{Owner: userId, From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: executeAt}
+	created := &model.ScheduledTransfer{Id: 1, Owner: userId, From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: executeAt, Status: model.PendingTransfer}
+	suite.scheduled.On("Create", scheduled).Return(created, nil)
+
+	result, err := suite.service.Schedule(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: &executeAt}, userId)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), created, result)
+	suite.storage.AssertNotCalled(suite.T(), "TransferWithinLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotScheduleTransferInThePast() {
+	executeAt := time.Now().Add(-time.Minute)
+
+	_, err := suite.service.Schedule(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: &executeAt}, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "execute_at", Message: "The execution time has to be in the future"})
+	suite.scheduled.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotScheduleTransferFromAccountOfAnotherUser() {
+	executeAt := time.Now().Add(time.Hour)
+	suite.storage.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: 1, Currency: "EUR"}, nil)
+
+	_, err := suite.service.Schedule(&dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: &executeAt}, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+	suite.scheduled.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *AccountServiceSuite) TestShouldCancelScheduledTransfer() {
+	pending := &model.ScheduledTransfer{Id: 3, Owner: 1, From: 1, To: 2, Status: model.PendingTransfer}
+	cancelled := &model.ScheduledTransfer{Id: 3, Owner: 1, From: 1, To: 2, Status: model.CancelledTransfer}
+	suite.scheduled.On("Get", model.ScheduledTransferId(3)).Return(pending, nil)
+	suite.scheduled.On("Cancel", model.ScheduledTransferId(3)).Return(cancelled, nil)
+
+	result, err := suite.service.CancelScheduled(3, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), cancelled, result)
+}
+
+func (suite *AccountServiceSuite) TestShouldNotCancelScheduledTransferOfAnotherUser() {
+	suite.scheduled.On("Get", model.ScheduledTransferId(3)).Return(&model.ScheduledTransfer{Id: 3, Owner: 1, From: 1, To: 2, Status: model.PendingTransfer}, nil)
+
+	_, err := suite.service.CancelScheduled(3, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+	suite.scheduled.AssertNotCalled(suite.T(), "Cancel", mock.Anything)
+}
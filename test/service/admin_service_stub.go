@@ -0,0 +1,74 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+)
+
+type StubAdminService struct {
+	mock.Mock
+}
+
+func (service *StubAdminService) TrialBalance(user model.UserId) (*model.TrialBalance, error) {
+	args := service.Called(user)
+	if trialBalance, ok := args.Get(0).(*model.TrialBalance); ok {
+		return trialBalance, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) Reverse(request *dto.ReverseTransferRequest, user model.UserId) (*model.Transfer, error) {
+	args := service.Called(request, user)
+	if reversal, ok := args.Get(0).(*model.Transfer); ok {
+		return reversal, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) SetOverdraftLimit(request *dto.OverdraftLimitRequest, user model.UserId) (*model.Account, error) {
+	args := service.Called(request, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) Unfreeze(accountId model.AccountId, user model.UserId) (*model.Account, error) {
+	args := service.Called(accountId, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) OverdrawnAccounts(user model.UserId) ([]model.Account, error) {
+	args := service.Called(user)
+	if accounts, ok := args.Get(0).([]model.Account); ok {
+		return accounts, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) SetTransferLimits(request *dto.TransferLimitsRequest, user model.UserId) (*model.Account, error) {
+	args := service.Called(request, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubAdminService) SetInterestRate(request *dto.InterestRateRequest, user model.UserId) (*model.Account, error) {
+	args := service.Called(request, user)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
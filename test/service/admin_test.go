@@ -0,0 +1,177 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/test/storage"
+	"testing"
+)
+
+type AdminServiceSuite struct {
+	suite.Suite
+	accounts *storage.StubAccountStorage
+	journal  *storage.StubJournalStorage
+	service  service.AdminService
+}
+
+func TestAdminServiceSuite(t *testing.T) {
+	suite.Run(t, new(AdminServiceSuite))
+}
+
+func (suite *AdminServiceSuite) SetupTest() {
+	suite.accounts = new(storage.StubAccountStorage)
+	suite.journal = new(storage.StubJournalStorage)
+	suite.service = service.NewAdminService(suite.accounts, suite.journal, []model.UserId{1})
+}
+
+func (suite *AdminServiceSuite) TestShouldGetTrialBalanceForAdmin() {
+	trialBalance := &model.TrialBalance{Totals: []model.CurrencyTotal{{Currency: "EUR", Debits: decimal.NewFromInt(10), Credits: decimal.NewFromInt(10)}}}
+	suite.journal.On("TrialBalance").Return(trialBalance, nil)
+
+	result, err := suite.service.TrialBalance(1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), trialBalance, result)
+	suite.journal.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotGetTrialBalanceForCustomer() {
+	_, err := suite.service.TrialBalance(2)
+
+	assert.Equal(suite.T(), &errors.AdminAccessRequiredError{UserId: 2}, err)
+	suite.journal.AssertNotCalled(suite.T(), "TrialBalance")
+}
+
+func (suite *AdminServiceSuite) TestShouldReverseTransferForAdmin() {
+	originalId := model.TransferId(3)
+	reversal := &model.Transfer{Id: 4, From: 2, To: 1, Amount: decimal.NewFromInt(10), CreditAmount: decimal.NewFromInt(10), ReversalOf: &originalId}
+	suite.accounts.On("Reverse", originalId, true).Return(reversal, nil)
+
+	result, err := suite.service.Reverse(&dto.ReverseTransferRequest{Id: originalId, Partial: true}, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), reversal, result)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotReverseTransferForCustomer() {
+	_, err := suite.service.Reverse(&dto.ReverseTransferRequest{Id: 3}, 2)
+
+	assert.Equal(suite.T(), &errors.AdminAccessRequiredError{UserId: 2}, err)
+	suite.accounts.AssertNotCalled(suite.T(), "Reverse", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminServiceSuite) TestShouldNotReverseTransferTwice() {
+	suite.accounts.On("Reverse", model.TransferId(3), false).Return(nil, &errors.TransferAlreadyReversedError{TransferId: 3, ReversalId: 4})
+
+	_, err := suite.service.Reverse(&dto.ReverseTransferRequest{Id: 3}, 1)
+
+	assert.Equal(suite.T(), &errors.TransferAlreadyReversedError{TransferId: 3, ReversalId: 4}, err)
+}
+
+func (suite *AdminServiceSuite) TestShouldSetOverdraftLimitForAdmin() {
+	limit := decimal.NewFromInt(500)
+	account := &model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR", OverdraftLimit: limit}
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR"}, nil)
+	suite.accounts.On("SetOverdraftLimit", model.AccountId(3), limit).Return(account, nil)
+
+	result, err := suite.service.SetOverdraftLimit(&dto.OverdraftLimitRequest{AccountId: 3, OverdraftLimit: limit}, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), account, result)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotSetOverdraftLimitOfSystemAccount() {
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Type: model.SystemAccount, Currency: "EUR"}, nil)
+
+	_, err := suite.service.SetOverdraftLimit(&dto.OverdraftLimitRequest{AccountId: 3, OverdraftLimit: decimal.NewFromInt(500)}, 1)
+
+	assert.Equal(suite.T(), &errors.AccountDoesNotExistError{AccountId: 3}, err)
+	suite.accounts.AssertNotCalled(suite.T(), "SetOverdraftLimit", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminServiceSuite) TestShouldNotSetOverdraftLimitWithTooManyDecimalPlaces() {
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Type: model.CheckingAccount, Currency: "JPY"}, nil)
+
+	_, err := suite.service.SetOverdraftLimit(&dto.OverdraftLimitRequest{AccountId: 3, OverdraftLimit: decimal.RequireFromString("0.5")}, 1)
+
+	assert.Equal(suite.T(), errors.NewValidationError("overdraft_limit", "The overdraft limit can have at most 0 decimal places in JPY"), err)
+}
+
+func (suite *AdminServiceSuite) TestShouldSetTransferLimitsForAdmin() {
+	daily := decimal.NewFromInt(300)
+	limits := model.TransferLimits{DailyLimit: decimal.NewNullDecimal(daily)}
+	account := &model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR", TransferLimits: limits}
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR"}, nil)
+	suite.accounts.On("SetTransferLimits", model.AccountId(3), limits).Return(account, nil)
+
+	result, err := suite.service.SetTransferLimits(&dto.TransferLimitsRequest{AccountId: 3, TransferLimits: dto.TransferLimits{Daily: &daily}}, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), account, result)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotSetTransferLimitThatIsNotPositive() {
+	monthly := decimal.Zero
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Type: model.CheckingAccount, Currency: "EUR"}, nil)
+
+	_, err := suite.service.SetTransferLimits(&dto.TransferLimitsRequest{AccountId: 3, TransferLimits: dto.TransferLimits{Monthly: &monthly}}, 1)
+
+	assert.Equal(suite.T(), errors.NewValidationError("monthly", "The limit has to be positive"), err)
+	suite.accounts.AssertNotCalled(suite.T(), "SetTransferLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminServiceSuite) TestShouldSetInterestRateForAdmin() {
+	rate := decimal.RequireFromString("0.02")
+	account := &model.Account{Id: 3, Owner: 2, Type: model.SavingsAccount, Currency: "EUR", InterestRate: rate}
+	suite.accounts.On("Get", model.AccountId(3)).Return(&model.Account{Id: 3, Owner: 2, Type: model.SavingsAccount, Currency: "EUR"}, nil)
+	suite.accounts.On("SetInterestRate", model.AccountId(3), rate).Return(account, nil)
+
+	result, err := suite.service.SetInterestRate(&dto.InterestRateRequest{AccountId: 3, InterestRate: rate}, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), account, result)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotSetNegativeInterestRate() {
+	_, err := suite.service.SetInterestRate(&dto.InterestRateRequest{AccountId: 3, InterestRate: decimal.NewFromFloat(-0.01)}, 1)
+
+	assert.Equal(suite.T(), errors.NewValidationError("interest_rate", "The interest rate cannot be negative"), err)
+	suite.accounts.AssertNotCalled(suite.T(), "SetInterestRate", mock.Anything, mock.Anything)
+}
+
+func (suite *AdminServiceSuite) TestShouldNotListOverdrawnAccountsForCustomer() {
+	_, err := suite.service.OverdrawnAccounts(2)
+
+	assert.Equal(suite.T(), &errors.AdminAccessRequiredError{UserId: 2}, err)
+	suite.accounts.AssertNotCalled(suite.T(), "ListOverdrawn")
+}
+
+func (suite *AdminServiceSuite) TestShouldUnfreezeAccountForAdmin() {
+	account := &model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR", Status: model.FrozenAccount}
+	unfrozen := &model.Account{Id: 3, Owner: 2, Type: model.CheckingAccount, Currency: "EUR", Status: model.ActiveAccount}
+	suite.accounts.On("Get", model.AccountId(3)).Return(account, nil)
+	suite.accounts.On("SetStatus", model.AccountId(3), model.ActiveAccount).Return(unfrozen, nil)
+
+	result, err := suite.service.Unfreeze(3, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), unfrozen, result)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *AdminServiceSuite) TestShouldNotUnfreezeAccountForItsOwner() {
+	_, err := suite.service.Unfreeze(3, 2)
+
+	assert.Equal(suite.T(), &errors.AdminAccessRequiredError{UserId: 2}, err)
+	suite.accounts.AssertNotCalled(suite.T(), "SetStatus", mock.Anything, mock.Anything)
+}
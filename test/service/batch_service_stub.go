@@ -0,0 +1,29 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+)
+
+type StubBatchService struct {
+	mock.Mock
+}
+
+func (service *StubBatchService) Create(request *dto.BatchRequest, user model.UserId) (*model.Batch, error) {
+	args := service.Called(request, user)
+	if batch, ok := args.Get(0).(*model.Batch); ok {
+		return batch, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubBatchService) Get(id model.BatchId, user model.UserId) (*model.Batch, error) {
+	args := service.Called(id, user)
+	if batch, ok := args.Get(0).(*model.Batch); ok {
+		return batch, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	test_errors "golang_bank_demo/test/errors"
+	"testing"
+)
+
+type BatchServiceSuite struct {
+	suite.Suite
+	accounts *StubAccountService
+	storage  storage.BatchStorage
+	service  service.BatchService
+}
+
+func TestBatchServiceSuite(t *testing.T) {
+	suite.Run(t, new(BatchServiceSuite))
+}
+
+func (suite *BatchServiceSuite) SetupTest() {
+	suite.accounts = new(StubAccountService)
+	suite.storage = storage.NewInMemoryBatchStorage()
+	suite.service = service.NewBatchService(suite.accounts, suite.storage)
+}
+
+func (suite *BatchServiceSuite) request(mode model.BatchMode) *dto.BatchRequest {
+	return &dto.BatchRequest{Mode: mode, Lines: []dto.BatchLineRequest{
+		{Line: 1, Reference: "rent", Transfer: dto.TransferRequest{From: 1, To: 7, Amount: decimal.NewFromInt(60)}},
+		{Line: 2, Transfer: dto.TransferRequest{From: 1, To: 8, Amount: decimal.NewFromInt(50)}},
+	}}
+}
+
+func (suite *BatchServiceSuite) TestShouldRunAllOrNothingBatch() {
+	request := suite.request(model.AllOrNothingBatch)
+	suite.accounts.On("Quote", mock.Anything, model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("TransferAll", []*dto.TransferRequest{&request.Lines[0].Transfer, &request.Lines[1].Transfer}, model.UserId(1)).
+		Return([]model.Transfer{{Id: 3}, {Id: 4}}, nil)
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CompletedBatch, batch.Status)
+	assert.Equal(suite.T(), model.CompletedBatchLine, batch.Lines[0].Status)
+	assert.Equal(suite.T(), model.TransferId(3), *batch.Lines[0].TransferId)
+	assert.Equal(suite.T(), "rent", batch.Lines[0].Reference)
+	assert.Equal(suite.T(), model.TransferId(4), *batch.Lines[1].TransferId)
+	suite.accounts.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything)
+}
+
+func (suite *BatchServiceSuite) TestShouldSkipTheOtherLinesWhenOneFailsInAllOrNothingBatch() {
+	request := suite.request(model.AllOrNothingBatch)
+	suite.accounts.On("Quote", mock.Anything, model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("TransferAll", mock.Anything, model.UserId(1)).
+		Return(nil, &errors.BatchTransferError{Index: 1, Err: &errors.BalanceTooLowError{AccountId: 1, Shortfall: decimal.NewFromInt(10)}})
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.FailedBatch, batch.Status)
+	assert.Equal(suite.T(), model.SkippedBatchLine, batch.Lines[0].Status)
+	assert.Nil(suite.T(), batch.Lines[0].ErrorType)
+	assert.Equal(suite.T(), model.FailedBatchLine, batch.Lines[1].Status)
+	assert.Equal(suite.T(), "BalanceTooLowError", *batch.Lines[1].ErrorType)
+}
+
+func (suite *BatchServiceSuite) TestShouldKeepTheTypeOfErrorThatIsNotAPointer() {
+	request := suite.request(model.BestEffortBatch)
+	suite.accounts.On("Quote", mock.Anything, model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("Transfer", &request.Lines[0].Transfer, model.UserId(1)).Return(&model.Transfer{Id: 3}, nil)
+	suite.accounts.On("Transfer", &request.Lines[1].Transfer, model.UserId(1)).Return(nil, test_errors.TimeoutError{})
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.FailedBatchLine, batch.Lines[1].Status)
+	assert.Equal(suite.T(), "TimeoutError", *batch.Lines[1].ErrorType)
+	assert.Equal(suite.T(), "timeout", *batch.Lines[1].ErrorMessage)
+}
+
+func (suite *BatchServiceSuite) TestShouldNotTransferAnyWhenOneLineIsInvalidInAllOrNothingBatch() {
+	request := suite.request(model.AllOrNothingBatch)
+	request.Lines[1].Err = errors.NewValidationError("amount", "The amount has to be a number")
+	suite.accounts.On("Quote", mock.Anything, model.UserId(1)).Return(&model.Transfer{}, nil)
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.FailedBatch, batch.Status)
+	assert.Equal(suite.T(), model.SkippedBatchLine, batch.Lines[0].Status)
+	assert.Equal(suite.T(), "ValidationError", *batch.Lines[1].ErrorType)
+	assert.Equal(suite.T(), "Invalid field 'amount': The amount has to be a number", *batch.Lines[1].ErrorMessage)
+	suite.accounts.AssertNotCalled(suite.T(), "TransferAll", mock.Anything, mock.Anything)
+}
+
+func (suite *BatchServiceSuite) TestShouldKeepGoingAfterFailedLineInBestEffortBatch() {
+	request := suite.request(model.BestEffortBatch)
+	suite.accounts.On("Quote", &request.Lines[0].Transfer, model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("Quote", &request.Lines[1].Transfer, model.UserId(1)).Return(nil, &errors.AccountDoesNotExistError{AccountId: 8})
+	suite.accounts.On("Transfer", &request.Lines[0].Transfer, model.UserId(1)).Return(&model.Transfer{Id: 3}, nil)
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.PartiallyCompletedBatch, batch.Status)
+	assert.Equal(suite.T(), model.TransferId(3), *batch.Lines[0].TransferId)
+	assert.Equal(suite.T(), "AccountDoesNotExistError", *batch.Lines[1].ErrorType)
+	suite.accounts.AssertNumberOfCalls(suite.T(), "Transfer", 1)
+}
+
+func (suite *BatchServiceSuite) TestShouldFailLineInAnotherCurrencyThanTheSourceAccount() {
+	request := suite.request(model.BestEffortBatch)
+	request.Lines = request.Lines[:1]
+	request.Lines[0].Currency = "USD"
+	suite.accounts.On("Quote", mock.Anything, model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("Get", model.AccountId(1)).Return(&model.Account{Id: 1, Owner: 1, Currency: "EUR"}, nil)
+
+	batch, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.FailedBatch, batch.Status)
+	assert.Equal(suite.T(), "Invalid field 'currency': The currency has to be the one of the source account", *batch.Lines[0].ErrorMessage)
+	suite.accounts.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything)
+}
+
+func (suite *BatchServiceSuite) TestShouldNotCreateEmptyBatch() {
+	_, err := suite.service.Create(&dto.BatchRequest{Mode: model.BestEffortBatch}, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("file", "The file has to contain at least one transfer"))
+}
+
+func (suite *BatchServiceSuite) TestShouldGetBatchOnlyForItsOwner() {
+	created, _ := suite.storage.Create(suite.request(model.BestEffortBatch).Batch(1))
+
+	found, err := suite.service.Get(created.Id, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(found.Lines))
+
+	_, err = suite.service.Get(created.Id, 2)
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+}
@@ -0,0 +1,19 @@
+package service
+
+import "time"
+
+type StubClock struct {
+	now time.Time
+}
+
+func NewStubClock(now time.Time) *StubClock {
+	return &StubClock{now: now}
+}
+
+func (clock *StubClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *StubClock) Set(now time.Time) {
+	clock.now = now
+}
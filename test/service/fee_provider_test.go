@@ -0,0 +1,106 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type FeeProviderSuite struct {
+	suite.Suite
+}
+
+func TestFeeProviderSuite(t *testing.T) {
+	suite.Run(t, new(FeeProviderSuite))
+}
+
+func (suite *FeeProviderSuite) fee(provider service.FeeProvider, operation model.FeeOperation, accountType model.AccountType, amount string) string {
+	fee, err := provider.Fee(operation, &model.Account{Type: accountType, Currency: "EUR"}, decimal.RequireFromString(amount))
+	assert.NoError(suite.T(), err)
+	return fee.String()
+}
+
+func (suite *FeeProviderSuite) TestShouldPickTheFirstMatchingRule() {
+	provider := service.NewStaticFeeProvider(model.FeeSchedule{
+		{Operation: model.TransferOperation, AccountType: model.SavingsAccount, Flat: decimal.NewFromInt(2)},
+		{Operation: model.TransferOperation, Flat: decimal.RequireFromString("0.5")},
+	})
+
+	assert.Equal(suite.T(), "2", suite.fee(provider, model.TransferOperation, model.SavingsAccount, "100"))
+	assert.Equal(suite.T(), "0.5", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "100"))
+	assert.Equal(suite.T(), "0", suite.fee(provider, model.TopUpOperation, model.CheckingAccount, "100"))
+}
+
+func (suite *FeeProviderSuite) TestShouldCapPercentageFee() {
+	provider := service.NewStaticFeeProvider(model.FeeSchedule{{
+		Operation: model.TransferOperation,
+		Rate:      decimal.RequireFromString("0.01"),
+		Min:       decimal.NewNullDecimal(decimal.RequireFromString("0.5")),
+		Max:       decimal.NewNullDecimal(decimal.NewFromInt(5)),
+	}})
+
+	assert.Equal(suite.T(), "0.5", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "10"))
+	assert.Equal(suite.T(), "1.23", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "123.45"))
+	assert.Equal(suite.T(), "5", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "1000"))
+}
+
+func (suite *FeeProviderSuite) TestShouldChargeByTheTierOfTheAmount() {
+	provider := service.NewStaticFeeProvider(model.FeeSchedule{{
+		Operation: model.TopUpOperation,
+		Tiers: []model.FeeTier{
+			{UpTo: decimal.NewNullDecimal(decimal.NewFromInt(100))},
+			{UpTo: decimal.NewNullDecimal(decimal.NewFromInt(1000)), Flat: decimal.NewFromInt(1)},
+			{Rate: decimal.RequireFromString("0.002")},
+		},
+	}})
+
+	assert.Equal(suite.T(), "0", suite.fee(provider, model.TopUpOperation, model.CheckingAccount, "100"))
+	assert.Equal(suite.T(), "1", suite.fee(provider, model.TopUpOperation, model.CheckingAccount, "100.01"))
+	assert.Equal(suite.T(), "4", suite.fee(provider, model.TopUpOperation, model.CheckingAccount, "2000"))
+}
+
+func (suite *FeeProviderSuite) TestShouldLoadAndReloadScheduleFromFile() {
+	path := filepath.Join(suite.T().TempDir(), "fees.yaml")
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("rules:\n  - operation: transfer\n    flat: \"0.50\"\n"), 0600))
+	provider, err := service.NewFileFeeProvider(path)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "0.5", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "100"))
+
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("rules:\n  - operation: transfer\n    rate: 0.01\n    max: 5\n"), 0600))
+	later := time.Now().Add(time.Minute)
+	assert.NoError(suite.T(), os.Chtimes(path, later, later))
+
+	assert.Equal(suite.T(), "1", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "100"))
+	assert.Equal(suite.T(), "5", suite.fee(provider, model.TransferOperation, model.CheckingAccount, "1000"))
+}
+
+func (suite *FeeProviderSuite) TestShouldNotLoadInvalidSchedules() {
+	for _, content := range []string{
+		"rules:\n  - operation: withdrawal\n",
+		"rules:\n  - operation: transfer\n    account_type: credit\n",
+		"rules:\n  - operation: transfer\n    flat: \"-1\"\n",
+		"rules:\n  - operation: transfer\n    min: \"5\"\n    max: \"1\"\n",
+		"rules:\n  - operation: transfer\n    tiers:\n      - up_to: \"100\"\n      - up_to: \"50\"\n",
+	} {
+		path := filepath.Join(suite.T().TempDir(), "fees.yaml")
+		assert.NoError(suite.T(), os.WriteFile(path, []byte(content), 0600))
+
+		provider, err := service.NewFileFeeProvider(path)
+
+		assert.Error(suite.T(), err, content)
+		assert.Nil(suite.T(), provider)
+	}
+}
+
+func (suite *FeeProviderSuite) TestShouldLoadTheBundledSchedule() {
+	_, err := service.NewFileFeeProvider("../../fees.yaml")
+
+	assert.NoError(suite.T(), err)
+}
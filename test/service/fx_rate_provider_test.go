@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/service"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type FxRateProviderSuite struct {
+	suite.Suite
+}
+
+func TestFxRateProviderSuite(t *testing.T) {
+	suite.Run(t, new(FxRateProviderSuite))
+}
+
+func (suite *FxRateProviderSuite) TestShouldQuoteDirectAndInverseRates() {
+	provider := service.NewStaticFxRateProvider(map[string]decimal.Decimal{"EUR/USD": decimal.RequireFromString("1.25")})
+
+	directRate, err := provider.Rate("EUR", "USD")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), directRate.Equal(decimal.RequireFromString("1.25")))
+
+	inverseRate, err := provider.Rate("USD", "EUR")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), inverseRate.Equal(decimal.RequireFromString("0.8")))
+
+	sameCurrencyRate, err := provider.Rate("GBP", "GBP")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), sameCurrencyRate.Equal(decimal.NewFromInt(1)))
+}
+
+func (suite *FxRateProviderSuite) TestShouldNotQuoteUnknownPair() {
+	provider := service.NewStaticFxRateProvider(map[string]decimal.Decimal{"EUR/USD": decimal.RequireFromString("1.25")})
+
+	_, err := provider.Rate("EUR", "GBP")
+
+	assert.ErrorIs(suite.T(), err, &errors.FxRateUnavailableError{From: "EUR", To: "GBP"})
+}
+
+func (suite *FxRateProviderSuite) TestShouldApplySpread() {
+	provider := service.NewSpreadFxRateProvider(
+		service.NewStaticFxRateProvider(map[string]decimal.Decimal{"EUR/USD": decimal.RequireFromString("1.2")}),
+		decimal.RequireFromString("0.01"),
+	)
+
+	rate, err := provider.Rate("EUR", "USD")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rate.Equal(decimal.RequireFromString("1.188")))
+
+	sameCurrencyRate, err := provider.Rate("EUR", "EUR")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), sameCurrencyRate.Equal(decimal.NewFromInt(1)))
+}
+
+func (suite *FxRateProviderSuite) TestShouldLoadAndReloadRatesFromFile() {
+	path := filepath.Join(suite.T().TempDir(), "fx_rates.yaml")
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("rates:\n  EUR/USD: \"1.10\"\n"), 0600))
+	provider, err := service.NewFileFxRateProvider(path)
+	assert.NoError(suite.T(), err)
+
+	rate, err := provider.Rate("EUR", "USD")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rate.Equal(decimal.RequireFromString("1.1")))
+
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("rates:\n  EUR/USD: \"1.20\"\n"), 0600))
+	later := time.Now().Add(time.Minute)
+	assert.NoError(suite.T(), os.Chtimes(path, later, later))
+
+	rate, err = provider.Rate("EUR", "USD")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), rate.Equal(decimal.RequireFromString("1.2")))
+}
+
+func (suite *FxRateProviderSuite) TestShouldNotLoadInvalidRates() {
+	path := filepath.Join(suite.T().TempDir(), "fx_rates.yaml")
+	assert.NoError(suite.T(), os.WriteFile(path, []byte("rates:\n  EUR/USD: \"-1\"\n"), 0600))
+
+	provider, err := service.NewFileFxRateProvider(path)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), provider)
+}
+
+func (suite *FxRateProviderSuite) TestShouldLoadTheBundledRates() {
+	_, err := service.NewFileFxRateProvider("../../fx_rates.yaml")
+
+	assert.NoError(suite.T(), err)
+}
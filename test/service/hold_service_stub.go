@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+)
+
+type StubHoldService struct {
+	mock.Mock
+}
+
+func (service *StubHoldService) Place(request *dto.HoldRequest, user model.UserId) (*model.Hold, error) {
+	args := service.Called(request, user)
+	if hold, ok := args.Get(0).(*model.Hold); ok {
+		return hold, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubHoldService) Get(id model.HoldId, user model.UserId) (*model.Hold, error) {
+	args := service.Called(id, user)
+	if hold, ok := args.Get(0).(*model.Hold); ok {
+		return hold, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubHoldService) Capture(request *dto.CaptureHoldRequest, user model.UserId) (*model.Transfer, error) {
+	args := service.Called(request, user)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubHoldService) Release(id model.HoldId, user model.UserId) (*model.Hold, error) {
+	args := service.Called(id, user)
+	if hold, ok := args.Get(0).(*model.Hold); ok {
+		return hold, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
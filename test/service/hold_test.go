@@ -0,0 +1,191 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"testing"
+	"time"
+)
+
+type HoldServiceSuite struct {
+	suite.Suite
+	accounts *StubAccountService
+	storage  *storage.InMemoryAccountStorage
+	clock    *StubClock
+	service  service.HoldService
+	from     *model.Account
+	to       *model.Account
+}
+
+func TestHoldServiceSuite(t *testing.T) {
+	suite.Run(t, new(HoldServiceSuite))
+}
+
+func (suite *HoldServiceSuite) SetupTest() {
+	suite.accounts = new(StubAccountService)
+	suite.storage = storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	suite.clock = NewStubClock(time.Date(2023, 1, 15, 10, 0, 0, 0, time.UTC))
+	suite.service = service.NewHoldService(suite.accounts, suite.storage, suite.clock, &config.Holds{Expiration: 24 * time.Hour}, model.Limits{})
+	suite.from, _ = suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	suite.to, _ = suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(suite.from.Id, decimal.NewFromInt(100), decimal.Zero)
+	suite.accounts.On("Get", suite.from.Id).Return(suite.from, nil)
+}
+
+func (suite *HoldServiceSuite) placeHold(amount int64) *model.Hold {
+	request := &dto.HoldRequest{From: suite.from.Id, To: suite.to.Id, Amount: decimal.NewFromInt(amount)}
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).
+		Return(model.NewTransfer(suite.from.Id, suite.to.Id, request.Amount), nil).Once()
+	hold, err := suite.service.Place(request, 1)
+	assert.NoError(suite.T(), err)
+	return hold
+}
+
+func (suite *HoldServiceSuite) available() string {
+	account, _ := suite.storage.Get(suite.from.Id)
+	return account.Available().String()
+}
+
+func (suite *HoldServiceSuite) TestShouldPlaceHoldUntilTheDefaultExpiration() {
+	hold := suite.placeHold(80)
+
+	assert.Equal(suite.T(), model.ActiveHold, hold.Status)
+	assert.Equal(suite.T(), time.Date(2023, 1, 16, 10, 0, 0, 0, time.UTC), hold.ExpiresAt)
+	assert.Equal(suite.T(), "20", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldNotPlaceHoldThatExpiresInThePast() {
+	expiresAt := time.Date(2023, 1, 15, 9, 0, 0, 0, time.UTC)
+	request := &dto.HoldRequest{From: suite.from.Id, To: suite.to.Id, Amount: decimal.NewFromInt(80), ExpiresAt: &expiresAt}
+
+	_, err := suite.service.Place(request, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("expires_at", "The expiry time has to be in the future"))
+	suite.accounts.AssertNotCalled(suite.T(), "Quote")
+}
+
+func (suite *HoldServiceSuite) TestShouldCaptureTheWholeHoldByDefault() {
+	hold := suite.placeHold(80)
+	request := &dto.CaptureHoldRequest{Id: hold.Id}
+	suite.accounts.On("Quote", request.TransferRequest(hold), model.UserId(1)).
+		Return(model.NewTransfer(suite.from.Id, suite.to.Id, hold.Amount), nil)
+
+	transfer, err := suite.service.Capture(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "80", transfer.Amount.String())
+	assert.Equal(suite.T(), "20", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldCapturePartOfHold() {
+	hold := suite.placeHold(80)
+	amount := decimal.NewFromInt(30)
+	request := &dto.CaptureHoldRequest{Id: hold.Id, Amount: &amount}
+	suite.accounts.On("Quote", request.TransferRequest(hold), model.UserId(1)).
+		Return(model.NewTransfer(suite.from.Id, suite.to.Id, amount), nil)
+
+	transfer, err := suite.service.Capture(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "30", transfer.Amount.String())
+	assert.Equal(suite.T(), "70", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldReserveTheFeeAndChargeItOnCapture() {
+	placeRequest := &dto.HoldRequest{From: suite.from.Id, To: suite.to.Id, Amount: decimal.NewFromInt(80)}
+	quoted := model.NewTransfer(suite.from.Id, suite.to.Id, placeRequest.Amount)
+	quoted.Fee = decimal.NewFromInt(2)
+	suite.accounts.On("Quote", placeRequest.TransferRequest(), model.UserId(1)).Return(quoted, nil).Once()
+	hold, err := suite.service.Place(placeRequest, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "2", hold.Fee.String())
+	assert.Equal(suite.T(), "18", suite.available())
+
+	amount := decimal.NewFromInt(30)
+	request := &dto.CaptureHoldRequest{Id: hold.Id, Amount: &amount}
+	captured := model.NewTransfer(suite.from.Id, suite.to.Id, amount)
+	captured.Fee = decimal.NewFromInt(1)
+	suite.accounts.On("Quote", request.TransferRequest(hold), model.UserId(1)).Return(captured, nil)
+
+	transfer, err := suite.service.Capture(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", transfer.Fee.String())
+	assert.Equal(suite.T(), "69", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldNotCaptureMoreThanHeld() {
+	hold := suite.placeHold(80)
+	amount := decimal.NewFromInt(90)
+
+	_, err := suite.service.Capture(&dto.CaptureHoldRequest{Id: hold.Id, Amount: &amount}, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("amount", "The captured amount cannot be more than the held amount"))
+}
+
+func (suite *HoldServiceSuite) TestShouldNotCaptureExpiredHold() {
+	hold := suite.placeHold(80)
+	suite.clock.Set(hold.ExpiresAt)
+
+	_, err := suite.service.Capture(&dto.CaptureHoldRequest{Id: hold.Id}, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.HoldNotActiveError{HoldId: hold.Id, Status: model.ExpiredHold})
+	assert.Equal(suite.T(), "20", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldNotCaptureOverTheDailyLimit() {
+	limited := service.NewHoldService(suite.accounts, suite.storage, suite.clock, &config.Holds{Expiration: time.Hour}, model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(50))}}})
+	hold := suite.placeHold(80)
+	request := &dto.CaptureHoldRequest{Id: hold.Id}
+	suite.accounts.On("Quote", request.TransferRequest(hold), model.UserId(1)).
+		Return(model.NewTransfer(suite.from.Id, suite.to.Id, hold.Amount), nil)
+
+	_, err := limited.Capture(request, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(suite.from.Id), Period: model.DailyPeriod})
+	assert.Equal(suite.T(), "20", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldNotReleaseHoldOfAnotherUser() {
+	hold := suite.placeHold(80)
+	other := new(StubAccountService)
+	other.On("Get", suite.from.Id).Return(nil, &errors.ForbiddenAccountAccessError{AccountId: suite.from.Id, UserId: 2})
+	otherService := service.NewHoldService(other, suite.storage, suite.clock, &config.Holds{Expiration: time.Hour}, model.Limits{})
+
+	_, err := otherService.Release(hold.Id, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: suite.from.Id, UserId: 2})
+	assert.Equal(suite.T(), "20", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldReleaseHold() {
+	hold := suite.placeHold(80)
+
+	released, err := suite.service.Release(hold.Id, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ReleasedHold, released.Status)
+	assert.Equal(suite.T(), "100", suite.available())
+}
+
+func (suite *HoldServiceSuite) TestShouldExpireHoldsByTheClock() {
+	hold := suite.placeHold(80)
+	expirer := service.NewHoldExpirer(suite.storage, suite.clock, time.Minute)
+
+	expired, err := expirer.ExpireDue()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, expired)
+
+	suite.clock.Set(hold.ExpiresAt)
+	expired, err = expirer.ExpireDue()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, expired)
+	assert.Equal(suite.T(), "100", suite.available())
+}
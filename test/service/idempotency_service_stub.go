@@ -0,0 +1,29 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+)
+
+type StubIdempotencyService struct {
+	mock.Mock
+}
+
+func (service *StubIdempotencyService) Begin(user model.UserId, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	args := service.Called(user, key, requestHash)
+	if record, ok := args.Get(0).(*model.IdempotencyRecord); ok {
+		return record, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubIdempotencyService) Complete(user model.UserId, key string, statusCode int, response []byte) error {
+	args := service.Called(user, key, statusCode, response)
+	return args.Error(0)
+}
+
+func (service *StubIdempotencyService) Release(user model.UserId, key string) error {
+	args := service.Called(user, key)
+	return args.Error(0)
+}
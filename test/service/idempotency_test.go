@@ -0,0 +1,97 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/test/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+type IdempotencyServiceSuite struct {
+	suite.Suite
+	storage *storage.StubIdempotencyStorage
+	service service.IdempotencyService
+}
+
+func TestIdempotencyServiceSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyServiceSuite))
+}
+
+func (suite *IdempotencyServiceSuite) SetupTest() {
+	suite.storage = new(storage.StubIdempotencyStorage)
+	suite.service = service.NewIdempotencyService(suite.storage, time.Hour)
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldBeginNewRequest() {
+	userId := model.UserId(1)
+	suite.storage.On("Reserve", userId, "key", "hash", time.Hour).Return(nil, nil)
+
+	record, err := suite.service.Begin(userId, "key", "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldReturnCompletedRequest() {
+	userId := model.UserId(1)
+	statusCode := 200
+	stored := &model.IdempotencyRecord{UserId: userId, Key: "key", RequestHash: "hash", StatusCode: &statusCode, Response: []byte("{}")}
+	suite.storage.On("Reserve", userId, "key", "hash", time.Hour).Return(stored, nil)
+
+	record, err := suite.service.Begin(userId, "key", "hash")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), stored, record)
+	suite.storage.AssertExpectations(suite.T())
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldNotBeginWhenKeyIsUsedForDifferentRequest() {
+	userId := model.UserId(1)
+	statusCode := 200
+	stored := &model.IdempotencyRecord{UserId: userId, Key: "key", RequestHash: "another_hash", StatusCode: &statusCode}
+	suite.storage.On("Reserve", userId, "key", "hash", time.Hour).Return(stored, nil)
+
+	record, err := suite.service.Begin(userId, "key", "hash")
+
+	assert.ErrorIs(suite.T(), err, &errors.IdempotencyKeyReuseError{Key: "key"})
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldNotBeginWhenRequestIsInProgress() {
+	userId := model.UserId(1)
+	stored := &model.IdempotencyRecord{UserId: userId, Key: "key", RequestHash: "hash"}
+	suite.storage.On("Reserve", userId, "key", "hash", time.Hour).Return(stored, nil)
+
+	record, err := suite.service.Begin(userId, "key", "hash")
+
+	assert.ErrorIs(suite.T(), err, &errors.IdempotencyKeyInProgressError{Key: "key"})
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldNotBeginWhenKeyIsTooLong() {
+	userId := model.UserId(1)
+	key := strings.Repeat("k", 256)
+
+	record, err := suite.service.Begin(userId, key, "hash")
+
+	assert.ErrorIs(suite.T(), err, &errors.ValidationError{Field: "Idempotency-Key", Message: "The idempotency key has to be at most 255 characters"})
+	assert.Nil(suite.T(), record)
+	suite.storage.AssertNotCalled(suite.T(), "Reserve", userId, key, "hash", time.Hour)
+}
+
+func (suite *IdempotencyServiceSuite) TestShouldDeleteKeysOlderThanTheExpiration() {
+	expirer := service.NewIdempotencyKeyExpirer(suite.storage, &config.Idempotency{Expiration: time.Hour, Interval: time.Minute})
+	suite.storage.On("DeleteExpired", time.Hour).Return(2, nil)
+
+	deleted, err := expirer.DeleteExpired()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, deleted)
+}
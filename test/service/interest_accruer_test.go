@@ -0,0 +1,183 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"testing"
+	"time"
+)
+
+type InterestAccruerSuite struct {
+	suite.Suite
+	storage *storage.InMemoryAccountStorage
+	ledger  *storage.InMemoryLedgerStorage
+	clock   *StubClock
+	account model.AccountId
+}
+
+func TestInterestAccruerSuite(t *testing.T) {
+	suite.Run(t, new(InterestAccruerSuite))
+}
+
+func (suite *InterestAccruerSuite) SetupTest() {
+	suite.ledger = storage.NewInMemoryLedgerStorage()
+	suite.storage = storage.NewInMemoryAccountStorage(suite.ledger)
+	suite.clock = NewStubClock(date(2023, 1, 1))
+	suite.ledger.SetClock(suite.clock.Now)
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.SavingsAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(account.Id, decimal.NewFromInt(1000), decimal.Zero)
+	suite.account = account.Id
+}
+
+func (suite *InterestAccruerSuite) accruer(dayCount model.DayCount) *service.InterestAccruer {
+	accruer, err := service.NewInterestAccruer(suite.storage, suite.clock, &config.Interest{Interval: time.Hour, DayCount: string(dayCount)})
+	assert.NoError(suite.T(), err)
+	return accruer
+}
+
+// runUntil accrues every day up to the given one, as a server would run at a few minutes past midnight
+func (suite *InterestAccruerSuite) runUntil(accruer *service.InterestAccruer, last time.Time) {
+	for day := model.Today(suite.clock.Now()); !day.After(last); day = day.AddDate(0, 0, 1) {
+		suite.clock.Set(day.AddDate(0, 0, 1).Add(5 * time.Minute))
+		accrued, err := accruer.AccrueDue()
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 1, accrued)
+	}
+}
+
+func (suite *InterestAccruerSuite) TestShouldCapitalizeMonthlyOverAYear() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+
+	suite.runUntil(suite.accruer(model.Actual365), date(2023, 12, 31))
+
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1037.11", account.Balance.String())
+	assert.Equal(suite.T(), "0.006631", account.AccruedInterest.String())
+	entries, _ := suite.ledger.List(suite.account, &model.LedgerFilter{Types: []model.LedgerEntryType{model.InterestEntry}})
+	assert.Len(suite.T(), entries, 12)
+	assert.Equal(suite.T(), "3.2", entries[0].Amount.String())
+	trialBalance, _ := suite.storage.TrialBalance()
+	assert.True(suite.T(), trialBalance.IsBalanced())
+}
+
+func (suite *InterestAccruerSuite) TestShouldCapitalizeOverALeapYearByActual360() {
+	suite.clock.Set(date(2024, 1, 1))
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.05"))
+
+	suite.runUntil(suite.accruer(model.Actual360), date(2024, 12, 31))
+
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1052.03", account.Balance.String())
+	assert.Equal(suite.T(), "0.00434444444444444438", account.AccruedInterest.String())
+}
+
+func (suite *InterestAccruerSuite) TestShouldAccrueEveryDayOnlyOnce() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	accruer := suite.accruer(model.Actual365)
+	suite.clock.Set(date(2023, 2, 1).Add(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		accrued, err := accruer.AccrueDue()
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), 1, accrued)
+		_, err = accruer.Accrue(date(2023, 1, 31))
+		assert.NoError(suite.T(), err)
+	}
+
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1000.1", account.Balance.String())
+	assert.Equal(suite.T(), "0", account.AccruedInterest.String())
+}
+
+func (suite *InterestAccruerSuite) TestShouldAccrueTheBalanceAtTheEndOfTheDay() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	accruer := suite.accruer(model.Actual365)
+	suite.clock.Set(date(2023, 1, 2).Add(5 * time.Minute))
+	_, _ = accruer.AccrueDue()
+
+	suite.clock.Set(date(2023, 1, 3).Add(5 * time.Minute))
+	_, _ = suite.storage.TopUp(suite.account, decimal.NewFromInt(1000), decimal.Zero)
+	accrued, err := accruer.AccrueDue()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, accrued)
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "2000", account.Balance.String())
+	assert.Equal(suite.T(), "0.2", account.AccruedInterest.String())
+}
+
+func (suite *InterestAccruerSuite) TestShouldCatchUpTheMissedDaysInOrder() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	accruer := suite.accruer(model.Actual365)
+	suite.clock.Set(date(2023, 1, 2).Add(5 * time.Minute))
+	_, _ = accruer.AccrueDue()
+
+	suite.clock.Set(date(2023, 2, 3).Add(5 * time.Minute))
+	accrued, err := accruer.AccrueDue()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, accrued)
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1003.1", account.Balance.String())
+	assert.Equal(suite.T(), "0.20062", account.AccruedInterest.String())
+	last, _ := suite.storage.LastAccruedDay(suite.account)
+	assert.Equal(suite.T(), date(2023, 2, 2), *last)
+}
+
+func (suite *InterestAccruerSuite) TestShouldNotAccrueTheDaysWithoutRateWhenTheRateIsSetAgain() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	accruer := suite.accruer(model.Actual365)
+	suite.runUntil(accruer, date(2023, 1, 31))
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.Zero)
+
+	suite.clock.Set(date(2023, 2, 10).Add(5 * time.Minute))
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	suite.clock.Set(date(2023, 2, 11).Add(5 * time.Minute))
+	accrued, err := accruer.AccrueDue()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, accrued)
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1003.1", account.Balance.String())
+	assert.Equal(suite.T(), "0.10031", account.AccruedInterest.String())
+	last, _ := suite.storage.LastAccruedDay(suite.account)
+	assert.Equal(suite.T(), date(2023, 2, 10), *last)
+}
+
+func (suite *InterestAccruerSuite) TestShouldNotAccrueDayThatHasNotFinished() {
+	_, _ = suite.storage.SetInterestRate(suite.account, decimal.RequireFromString("0.0365"))
+	suite.clock.Set(date(2023, 1, 31).Add(23 * time.Hour))
+
+	_, err := suite.accruer(model.Actual365).Accrue(date(2023, 1, 31))
+
+	assert.EqualError(suite.T(), err, "The day 2023-01-31 has not finished yet")
+	account, _ := suite.storage.Get(suite.account)
+	assert.Equal(suite.T(), "1000", account.Balance.String())
+}
+
+func (suite *InterestAccruerSuite) TestShouldNotCreateWithUnknownDayCount() {
+	_, err := service.NewInterestAccruer(suite.storage, suite.clock, &config.Interest{DayCount: "ACT/ACT"})
+
+	assert.EqualError(suite.T(), err, "Unknown day count convention 'ACT/ACT'")
+}
+
+func (suite *InterestAccruerSuite) TestShouldCountThirty360Days() {
+	for day, expected := range map[time.Time]int64{
+		date(2023, 1, 15):  1,
+		date(2023, 1, 30):  0,
+		date(2023, 1, 31):  1,
+		date(2023, 2, 28):  3,
+		date(2024, 2, 28):  1,
+		date(2024, 2, 29):  2,
+		date(2023, 12, 31): 1,
+	} {
+		days, yearDays := model.Thirty360.DayFraction(day)
+		assert.Equal(suite.T(), expected, days, day.Format("2006-01-02"))
+		assert.Equal(suite.T(), int64(360), yearDays)
+	}
+}
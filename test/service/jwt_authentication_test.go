@@ -0,0 +1,187 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	jwtSecret   = "secret"
+	jwtIssuer   = "https://auth.bank.demo"
+	jwtAudience = "bank-api"
+	jwtKeyId    = "key-1"
+)
+
+type JwtAuthenticationServiceSuite struct {
+	suite.Suite
+	privateKey *rsa.PrivateKey
+	jwksFile   string
+	service    service.AuthenticationService
+}
+
+func TestJwtAuthenticationServiceSuite(t *testing.T) {
+	suite.Run(t, new(JwtAuthenticationServiceSuite))
+}
+
+func (suite *JwtAuthenticationServiceSuite) SetupSuite() {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(suite.T(), err)
+	suite.privateKey = privateKey
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": jwtKeyId,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		}},
+	})
+	suite.jwksFile = filepath.Join(suite.T().TempDir(), "jwks.json")
+	assert.NoError(suite.T(), os.WriteFile(suite.jwksFile, jwks, 0600))
+}
+
+func (suite *JwtAuthenticationServiceSuite) SetupTest() {
+	authService, err := service.NewJwtAuthenticationService(&config.Jwt{
+		Secret:    jwtSecret,
+		JwksFile:  suite.jwksFile,
+		Issuer:    jwtIssuer,
+		Audience:  jwtAudience,
+		UserClaim: "sub",
+	})
+	assert.NoError(suite.T(), err)
+	suite.service = authService
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "1",
+		"iss": jwtIssuer,
+		"aud": jwtAudience,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"nbf": time.Now().Add(-time.Minute).Unix(),
+	}
+}
+
+func (suite *JwtAuthenticationServiceSuite) hs256(claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	assert.NoError(suite.T(), err)
+	return token
+}
+
+func (suite *JwtAuthenticationServiceSuite) rs256(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = jwtKeyId
+	signed, err := token.SignedString(suite.privateKey)
+	assert.NoError(suite.T(), err)
+	return signed
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldGetUserFromHs256Token() {
+	user, err := suite.service.GetUser(suite.hs256(validClaims()))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.UserId(1), *user)
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldGetUserFromRs256Token() {
+	claims := validClaims()
+	claims["sub"] = 2
+
+	user, err := suite.service.GetUser(suite.rs256(claims))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.UserId(2), *user)
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldGetUserFromConfiguredClaim() {
+	authService, err := service.NewJwtAuthenticationService(&config.Jwt{Secret: jwtSecret, UserClaim: "user_id"})
+	assert.NoError(suite.T(), err)
+	claims := validClaims()
+	claims["user_id"] = 5
+
+	user, err := authService.GetUser(suite.hs256(claims))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.UserId(5), *user)
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldGetUserFromPemPublicKey() {
+	publicKey, err := x509.MarshalPKIXPublicKey(&suite.privateKey.PublicKey)
+	assert.NoError(suite.T(), err)
+	pemFile := filepath.Join(suite.T().TempDir(), "public.pem")
+	assert.NoError(suite.T(), os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0600))
+	authService, err := service.NewJwtAuthenticationService(&config.Jwt{PublicKeyFile: pemFile, UserClaim: "sub"})
+	assert.NoError(suite.T(), err)
+
+	user, err := authService.GetUser(suite.rs256(validClaims()))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.UserId(1), *user)
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldRejectInvalidTokens() {
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	withoutExpiration := validClaims()
+	delete(withoutExpiration, "exp")
+	notYetValid := validClaims()
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "https://evil.demo"
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "another-api"
+	withoutUser := validClaims()
+	delete(withoutUser, "sub")
+	nonNumericUser := validClaims()
+	nonNumericUser["sub"] = "alice"
+	wrongSecret, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("another secret"))
+	wrongAlgorithm, _ := jwt.NewWithClaims(jwt.SigningMethodHS512, validClaims()).SignedString([]byte(jwtSecret))
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	unknownKey := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims())
+	unknownKey.Header["kid"] = "key-2"
+	unknownKeyToken, _ := unknownKey.SignedString(suite.privateKey)
+
+	tokens := map[string]string{
+		"expired":           suite.hs256(expired),
+		"withoutExpiration": suite.hs256(withoutExpiration),
+		"notYetValid":       suite.rs256(notYetValid),
+		"wrongIssuer":       suite.hs256(wrongIssuer),
+		"wrongAudience":     suite.rs256(wrongAudience),
+		"withoutUser":       suite.hs256(withoutUser),
+		"nonNumericUser":    suite.hs256(nonNumericUser),
+		"wrongSecret":       wrongSecret,
+		"wrongAlgorithm":    wrongAlgorithm,
+		"unsigned":          unsigned,
+		"unknownKey":        unknownKeyToken,
+		"malformed":         "not.a.token",
+	}
+	for name, token := range tokens {
+		user, err := suite.service.GetUser(token)
+
+		assert.EqualError(suite.T(), err, "The user cannot access the api", name)
+		assert.Nil(suite.T(), user, name)
+	}
+}
+
+func (suite *JwtAuthenticationServiceSuite) TestShouldNotCreateServiceWithoutKeys() {
+	authService, err := service.NewJwtAuthenticationService(&config.Jwt{UserClaim: "sub"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), authService)
+}
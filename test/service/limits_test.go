@@ -0,0 +1,45 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/service"
+	"testing"
+)
+
+type LimitsSuite struct {
+	suite.Suite
+}
+
+func TestLimitsSuite(t *testing.T) {
+	suite.Run(t, new(LimitsSuite))
+}
+
+func (suite *LimitsSuite) TestShouldReadTheLimitsOfEveryCurrencyAsDecimals() {
+	limits, err := service.NewLimits(config.Limits{
+		"EUR": {PerTransaction: "10000", Daily: "0.1", UserDaily: "0"},
+		"JPY": {PerTransaction: "1300000"},
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "10000", limits.Of("EUR").Account.PerTransactionLimit.Decimal.String())
+	assert.Equal(suite.T(), "0.1", limits.Of("EUR").Account.DailyLimit.Decimal.String())
+	assert.False(suite.T(), limits.Of("EUR").Account.MonthlyLimit.Valid)
+	assert.False(suite.T(), limits.Of("EUR").User.DailyLimit.Valid)
+	assert.Equal(suite.T(), "1300000", limits.Of("JPY").Account.PerTransactionLimit.Decimal.String())
+	assert.True(suite.T(), limits.Of("USD").Account.IsEmpty())
+	assert.True(suite.T(), limits.Of("USD").User.IsEmpty())
+}
+
+func (suite *LimitsSuite) TestShouldNotReadInvalidLimits() {
+	_, err := service.NewLimits(config.Limits{"EUR": {Monthly: "-1"}})
+	assert.EqualError(suite.T(), err, "Invalid limits of EUR: the monthly cannot be negative")
+
+	_, err = service.NewLimits(config.Limits{"EUR": {UserDaily: "lots"}})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "Invalid limits of EUR: invalid user_daily")
+
+	_, err = service.NewLimits(config.Limits{"XYZ": {Daily: "100"}})
+	assert.EqualError(suite.T(), err, "Unknown currency 'XYZ' in the limits")
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+// FailingEventPublisher fails the given number of events before it publishes them again
+type FailingEventPublisher struct {
+	failures  int
+	published []model.Event
+}
+
+func (publisher *FailingEventPublisher) Publish(event *model.Event) error {
+	if publisher.failures > 0 {
+		publisher.failures--
+		return fmt.Errorf("broker down")
+	}
+	publisher.published = append(publisher.published, *event)
+	return nil
+}
+
+type OutboxRelaySuite struct {
+	suite.Suite
+	storage *storage.InMemoryAccountStorage
+	account model.AccountId
+}
+
+func TestOutboxRelaySuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelaySuite))
+}
+
+func (suite *OutboxRelaySuite) SetupTest() {
+	suite.storage = storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	suite.account = account.Id
+	for i := 0; i < 4; i++ {
+		_, _ = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+	}
+}
+
+func (suite *OutboxRelaySuite) relay(publisher service.EventPublisher) *service.OutboxRelay {
+	return service.NewOutboxRelay(suite.storage, publisher, &config.Events{Interval: time.Second, BatchSize: 2})
+}
+
+func (suite *OutboxRelaySuite) TestShouldPublishAllPendingEventsInBatches() {
+	publisher := &FailingEventPublisher{}
+
+	published, err := suite.relay(publisher).PublishPending()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 5, published)
+	assert.Equal(suite.T(), model.AccountCreatedEvent, publisher.published[0].Type)
+	for i, event := range publisher.published {
+		assert.Equal(suite.T(), model.EventId(i+1), event.Id)
+	}
+}
+
+func (suite *OutboxRelaySuite) TestShouldPublishFailedEventAgainInOrder() {
+	publisher := &FailingEventPublisher{failures: 1}
+	relay := suite.relay(publisher)
+
+	published, err := relay.PublishPending()
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 0, published)
+
+	published, err = relay.PublishPending()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 5, published)
+	assert.Equal(suite.T(), model.EventId(1), publisher.published[0].Id)
+}
+
+func (suite *OutboxRelaySuite) TestShouldWriteEventsAsJsonLines() {
+	var out bytes.Buffer
+
+	_, err := suite.relay(service.NewWriterEventPublisher(&out)).PublishPending()
+
+	assert.NoError(suite.T(), err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(suite.T(), lines, 5)
+	var event map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(suite.T(), "AccountToppedUp", event["type"])
+	assert.Equal(suite.T(), float64(1), event["version"])
+	assert.Equal(suite.T(), float64(suite.account), event["account_id"])
+	assert.Contains(suite.T(), event, "occurred_at")
+	assert.Equal(suite.T(), "10", event["data"].(map[string]interface{})["amount"])
+}
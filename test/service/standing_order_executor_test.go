@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+type StandingOrderExecutorSuite struct {
+	suite.Suite
+	accounts *StubAccountService
+	storage  storage.StandingOrderStorage
+	clock    *StubClock
+	executor *service.StandingOrderExecutor
+	request  interface{}
+}
+
+func TestStandingOrderExecutorSuite(t *testing.T) {
+	suite.Run(t, new(StandingOrderExecutorSuite))
+}
+
+func (suite *StandingOrderExecutorSuite) SetupTest() {
+	suite.accounts = new(StubAccountService)
+	suite.storage = storage.NewInMemoryStandingOrderStorage()
+	suite.clock = NewStubClock(date(2023, 1, 15))
+	orderConfig := &config.StandingOrders{MaxRetries: 2, RetryInterval: time.Hour}
+	suite.executor = service.NewStandingOrderExecutor(suite.accounts, suite.storage, suite.clock, orderConfig)
+	suite.request = mock.MatchedBy(func(request *dto.TransferRequest) bool {
+		return request.From == 1 && request.To == 7 && request.Amount.Equal(decimal.NewFromInt(100)) &&
+			request.Origin != nil && strings.HasPrefix(*request.Origin, "standing_order:1:")
+	})
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func (suite *StandingOrderExecutorSuite) create(recurrence model.Recurrence, endDate *time.Time) *model.StandingOrder {
+	request := &dto.StandingOrderRequest{From: 1, To: 7, Amount: decimal.NewFromInt(100), Frequency: recurrence.Frequency, Day: recurrence.Day}
+	if endDate != nil {
+		request.EndDate = dto.NewDate(*endDate)
+	}
+	order, _ := suite.storage.Create(request.StandingOrder(1, model.Today(suite.clock.Now())))
+	return order
+}
+
+func (suite *StandingOrderExecutorSuite) runOn(now time.Time) *model.StandingOrder {
+	suite.clock.Set(now)
+	assert.NoError(suite.T(), suite.executor.ExecuteDue())
+	order, _ := suite.storage.Get(1)
+	return order
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRunMonthlyOrderOnTheLastDayOfShorterMonths() {
+	order := suite.create(model.Recurrence{Frequency: model.MonthlyFrequency, Day: 31}, nil)
+	assert.Equal(suite.T(), date(2023, 1, 31), order.NextRunOn)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Times(3)
+
+	order = suite.runOn(date(2023, 1, 30))
+	assert.Equal(suite.T(), date(2023, 1, 31), order.NextRunOn)
+	order = suite.runOn(date(2023, 1, 31).Add(time.Hour))
+	assert.Equal(suite.T(), date(2023, 2, 28), order.NextRunOn)
+	assert.Equal(suite.T(), model.TransferId(5), *order.LastTransferId)
+	order = suite.runOn(date(2023, 2, 28))
+	assert.Equal(suite.T(), date(2023, 3, 31), order.NextRunOn)
+	order = suite.runOn(date(2023, 3, 31))
+	assert.Equal(suite.T(), date(2023, 4, 30), order.NextRunOn)
+
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRunOnLeapDay() {
+	suite.clock.Set(date(2024, 2, 1))
+	order := suite.create(model.Recurrence{Frequency: model.MonthlyFrequency, Day: 30}, nil)
+
+	assert.Equal(suite.T(), date(2024, 2, 29), order.NextRunOn)
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRunWeeklyOrderOnTheWeekday() {
+	// The 15th of January 2023 is a Sunday
+	order := suite.create(model.Recurrence{Frequency: model.WeeklyFrequency, Day: 7}, nil)
+	assert.Equal(suite.T(), date(2023, 1, 15), order.NextRunOn)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+
+	order = suite.runOn(date(2023, 1, 15))
+
+	assert.Equal(suite.T(), date(2023, 1, 22), order.NextRunOn)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldMakeUpMissedRuns() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Times(3)
+
+	order := suite.runOn(date(2023, 1, 17).Add(time.Hour))
+
+	assert.Equal(suite.T(), date(2023, 1, 18), order.NextRunOn)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldFinishOrderAfterTheEndDate() {
+	endDate := date(2023, 1, 16)
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, &endDate)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Times(2)
+
+	order := suite.runOn(date(2023, 1, 20))
+
+	assert.Equal(suite.T(), model.FinishedStandingOrder, order.Status)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRetryFailedRunAndThenSuspendTheOrder() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(nil, &errors.BalanceTooLowError{AccountId: 1}).Times(3)
+
+	order := suite.runOn(date(2023, 1, 15))
+	assert.Equal(suite.T(), model.ActiveStandingOrder, order.Status)
+	assert.Equal(suite.T(), 1, order.Failures)
+	assert.Equal(suite.T(), "BalanceTooLowError", *order.ErrorType)
+	assert.Equal(suite.T(), date(2023, 1, 15).Add(time.Hour), order.DueAt)
+
+	order = suite.runOn(date(2023, 1, 15).Add(30 * time.Minute))
+	assert.Equal(suite.T(), 1, order.Failures)
+	order = suite.runOn(date(2023, 1, 15).Add(time.Hour))
+	assert.Equal(suite.T(), 2, order.Failures)
+	order = suite.runOn(date(2023, 1, 15).Add(2 * time.Hour))
+	assert.Equal(suite.T(), 3, order.Failures)
+	assert.Equal(suite.T(), model.SuspendedStandingOrder, order.Status)
+
+	order = suite.runOn(date(2023, 1, 20))
+	assert.Equal(suite.T(), model.SuspendedStandingOrder, order.Status)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldResetFailuresAfterSuccessfulRetry() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(nil, &errors.BalanceTooLowError{AccountId: 1}).Once()
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+
+	suite.runOn(date(2023, 1, 15))
+	order := suite.runOn(date(2023, 1, 15).Add(time.Hour))
+
+	assert.Equal(suite.T(), 0, order.Failures)
+	assert.Nil(suite.T(), order.ErrorType)
+	assert.Equal(suite.T(), date(2023, 1, 16), order.NextRunOn)
+	assert.Equal(suite.T(), date(2023, 1, 16), order.DueAt)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRetryRunWithItsOrigin() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	origin := "standing_order:1:2023-01-15"
+	request := &dto.TransferRequest{From: 1, To: 7, Amount: decimal.NewFromInt(100), Origin: &origin}
+	suite.accounts.On("Transfer", request, model.UserId(1)).Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Once()
+	suite.accounts.On("Transfer", request, model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+
+	suite.runOn(date(2023, 1, 15))
+	order := suite.runOn(date(2023, 1, 15).Add(time.Hour))
+
+	assert.Equal(suite.T(), model.TransferId(5), *order.LastTransferId)
+	assert.Equal(suite.T(), date(2023, 1, 16), order.NextRunOn)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldCountServerErrorAsFailure() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Once()
+
+	order := suite.runOn(date(2023, 1, 15))
+
+	assert.Equal(suite.T(), model.ActiveStandingOrder, order.Status)
+	assert.Equal(suite.T(), 1, order.Failures)
+	assert.Equal(suite.T(), "InternalServerError", *order.ErrorType)
+	assert.Equal(suite.T(), date(2023, 1, 15).Add(time.Hour), order.DueAt)
+	suite.accounts.AssertNumberOfCalls(suite.T(), "Transfer", 1)
+}
+
+func (suite *StandingOrderExecutorSuite) TestShouldRunTheNextOrderWhenTheFirstFailsWithServerError() {
+	suite.create(model.Recurrence{Frequency: model.DailyFrequency}, nil)
+	second, _ := suite.storage.Create((&dto.StandingOrderRequest{From: 2, To: 7, Amount: decimal.NewFromInt(50), Frequency: model.DailyFrequency}).
+		StandingOrder(1, date(2023, 1, 15)))
+	suite.accounts.On("Transfer", suite.request, model.UserId(1)).Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Once()
+	suite.accounts.On("Transfer", mock.MatchedBy(func(request *dto.TransferRequest) bool { return request.From == 2 }), model.UserId(1)).
+		Return(&model.Transfer{Id: 5}, nil).Once()
+
+	suite.runOn(date(2023, 1, 15))
+
+	ran, _ := suite.storage.Get(second.Id)
+	assert.Equal(suite.T(), model.TransferId(5), *ran.LastTransferId)
+	assert.Equal(suite.T(), date(2023, 1, 16), ran.NextRunOn)
+	suite.accounts.AssertExpectations(suite.T())
+}
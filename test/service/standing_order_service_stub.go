@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/model"
+)
+
+type StubStandingOrderService struct {
+	mock.Mock
+}
+
+func (service *StubStandingOrderService) Create(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error) {
+	args := service.Called(request, user)
+	if order, ok := args.Get(0).(*model.StandingOrder); ok {
+		return order, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubStandingOrderService) Get(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error) {
+	args := service.Called(id, user)
+	if order, ok := args.Get(0).(*model.StandingOrder); ok {
+		return order, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubStandingOrderService) List(user model.UserId) ([]model.StandingOrder, error) {
+	args := service.Called(user)
+	if orders, ok := args.Get(0).([]model.StandingOrder); ok {
+		return orders, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubStandingOrderService) Update(request *dto.StandingOrderRequest, user model.UserId) (*model.StandingOrder, error) {
+	args := service.Called(request, user)
+	if order, ok := args.Get(0).(*model.StandingOrder); ok {
+		return order, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (service *StubStandingOrderService) Cancel(id model.StandingOrderId, user model.UserId) (*model.StandingOrder, error) {
+	args := service.Called(id, user)
+	if order, ok := args.Get(0).(*model.StandingOrder); ok {
+		return order, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
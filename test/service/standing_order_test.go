@@ -0,0 +1,164 @@
+package service
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"testing"
+	"time"
+)
+
+type StandingOrderServiceSuite struct {
+	suite.Suite
+	accounts *StubAccountService
+	storage  storage.StandingOrderStorage
+	clock    *StubClock
+	service  service.StandingOrderService
+}
+
+func TestStandingOrderServiceSuite(t *testing.T) {
+	suite.Run(t, new(StandingOrderServiceSuite))
+}
+
+func (suite *StandingOrderServiceSuite) SetupTest() {
+	suite.accounts = new(StubAccountService)
+	suite.storage = storage.NewInMemoryStandingOrderStorage()
+	suite.clock = NewStubClock(time.Date(2023, 1, 15, 10, 0, 0, 0, time.UTC))
+	suite.service = service.NewStandingOrderService(suite.accounts, suite.storage, suite.clock)
+}
+
+func (suite *StandingOrderServiceSuite) monthlyRequest() *dto.StandingOrderRequest {
+	return &dto.StandingOrderRequest{From: 1, To: 7, Amount: decimal.NewFromInt(100), Frequency: model.MonthlyFrequency, Day: 1}
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldCreateStandingOrder() {
+	request := suite.monthlyRequest()
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+
+	order, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActiveStandingOrder, order.Status)
+	assert.Equal(suite.T(), date(2023, 2, 1), order.NextRunOn)
+	assert.Equal(suite.T(), date(2023, 2, 1), order.DueAt)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldStartStandingOrderOnTheStartDate() {
+	request := suite.monthlyRequest()
+	request.StartDate = dto.NewDate(date(2023, 3, 10))
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+
+	order, err := suite.service.Create(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), date(2023, 4, 1), order.NextRunOn)
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotCreateStandingOrderWithInvalidRule() {
+	request := suite.monthlyRequest()
+	request.Frequency = model.WeeklyFrequency
+	request.Day = 8
+
+	_, err := suite.service.Create(request, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("day", "The day of a weekly order has to be between 1 (Monday) and 7 (Sunday)"))
+	suite.accounts.AssertNotCalled(suite.T(), "Quote")
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotCreateStandingOrderThatWouldNeverRun() {
+	request := suite.monthlyRequest()
+	request.EndDate = dto.NewDate(date(2023, 1, 31))
+
+	_, err := suite.service.Create(request, 1)
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("end_date", "The order would not run before the end date"))
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotCreateStandingOrderFromAccountOfAnotherUser() {
+	request := suite.monthlyRequest()
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(2)).
+		Return(nil, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+
+	_, err := suite.service.Create(request, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+	orders, _ := suite.storage.ListByOwner(2)
+	assert.Empty(suite.T(), orders)
+}
+
+// run is the transfer request of the run of the order with the origin
+func (suite *StandingOrderServiceSuite) run(request *dto.StandingOrderRequest, origin string) *dto.TransferRequest {
+	transferRequest := request.TransferRequest()
+	transferRequest.Origin = &origin
+	return transferRequest
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotRunUpdatedOrderAgainOnTheSameDay() {
+	request := &dto.StandingOrderRequest{From: 1, To: 7, Amount: decimal.NewFromInt(100), Frequency: model.DailyFrequency}
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("Transfer", suite.run(request, "standing_order:1:2023-01-15"), model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+	created, _ := suite.service.Create(request, 1)
+	assert.Equal(suite.T(), date(2023, 1, 15), created.NextRunOn)
+	executor := service.NewStandingOrderExecutor(suite.accounts, suite.storage, suite.clock, &config.StandingOrders{})
+	assert.NoError(suite.T(), executor.ExecuteDue())
+
+	request.Id = created.Id
+	updated, err := suite.service.Update(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), date(2023, 1, 16), updated.NextRunOn)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldReactivateSuspendedOrderOnUpdate() {
+	request := &dto.StandingOrderRequest{From: 1, To: 7, Amount: decimal.NewFromInt(100), Frequency: model.DailyFrequency}
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+	suite.accounts.On("Transfer", suite.run(request, "standing_order:1:2023-01-15"), model.UserId(1)).
+		Return(nil, &errors.BalanceTooLowError{AccountId: 1}).Once()
+	created, _ := suite.service.Create(request, 1)
+	executor := service.NewStandingOrderExecutor(suite.accounts, suite.storage, suite.clock, &config.StandingOrders{})
+	assert.NoError(suite.T(), executor.ExecuteDue())
+	suspended, _ := suite.storage.Get(created.Id)
+	assert.Equal(suite.T(), model.SuspendedStandingOrder, suspended.Status)
+
+	request.Id = created.Id
+	updated, err := suite.service.Update(request, 1)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActiveStandingOrder, updated.Status)
+	assert.Equal(suite.T(), 0, updated.Failures)
+	assert.Nil(suite.T(), updated.ErrorType)
+	assert.Equal(suite.T(), date(2023, 1, 15), updated.NextRunOn)
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotUpdateCancelledOrder() {
+	request := suite.monthlyRequest()
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+	created, _ := suite.service.Create(request, 1)
+	_, err := suite.service.Cancel(created.Id, 1)
+	assert.NoError(suite.T(), err)
+
+	request.Id = created.Id
+	_, err = suite.service.Update(request, 1)
+
+	assert.ErrorIs(suite.T(), err, &errors.StandingOrderEndedError{StandingOrderId: created.Id, Status: model.CancelledStandingOrder})
+}
+
+func (suite *StandingOrderServiceSuite) TestShouldNotCancelOrderOfAnotherUser() {
+	request := suite.monthlyRequest()
+	suite.accounts.On("Quote", request.TransferRequest(), model.UserId(1)).Return(&model.Transfer{}, nil)
+	created, _ := suite.service.Create(request, 1)
+
+	_, err := suite.service.Cancel(created.Id, 2)
+
+	assert.ErrorIs(suite.T(), err, &errors.ForbiddenAccountAccessError{AccountId: 1, UserId: 2})
+	order, _ := suite.storage.Get(created.Id)
+	assert.Equal(suite.T(), model.ActiveStandingOrder, order.Status)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/config"
+	"golang_bank_demo/src/dto"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/service"
+	"golang_bank_demo/src/storage"
+	"testing"
+	"time"
+)
+
+type TransferSchedulerSuite struct {
+	suite.Suite
+	accounts  *StubAccountService
+	storage   storage.ScheduledTransferStorage
+	scheduler *service.TransferScheduler
+	now       time.Time
+}
+
+func TestTransferSchedulerSuite(t *testing.T) {
+	suite.Run(t, new(TransferSchedulerSuite))
+}
+
+func (suite *TransferSchedulerSuite) SetupTest() {
+	suite.accounts = new(StubAccountService)
+	suite.storage = storage.NewInMemoryScheduledTransferStorage()
+	suite.scheduler = service.NewTransferScheduler(suite.accounts, suite.storage,
+		&config.Scheduler{Interval: time.Minute, MaxAttempts: 3, RetryInterval: time.Minute})
+	suite.now = time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (suite *TransferSchedulerSuite) schedule(executeAt time.Time) *model.ScheduledTransfer {
+	scheduled, _ := suite.storage.Create(&model.ScheduledTransfer{Owner: 1, From: 1, To: 2, Amount: decimal.NewFromInt(10), ExecuteAt: executeAt})
+	return scheduled
+}
+
+func (suite *TransferSchedulerSuite) request(scheduled *model.ScheduledTransfer) *dto.TransferRequest {
+	origin := scheduled.Origin()
+	return &dto.TransferRequest{From: 1, To: 2, Amount: decimal.NewFromInt(10), Origin: &origin}
+}
+
+func (suite *TransferSchedulerSuite) TestShouldExecuteDueTransfers() {
+	due := suite.schedule(suite.now.Add(-time.Minute))
+	notDue := suite.schedule(suite.now.Add(time.Minute))
+	suite.accounts.On("Transfer", suite.request(due), model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+
+	err := suite.scheduler.ExecuteDue(suite.now)
+
+	assert.NoError(suite.T(), err)
+	executed, _ := suite.storage.Get(due.Id)
+	assert.Equal(suite.T(), model.ExecutedTransfer, executed.Status)
+	assert.Equal(suite.T(), model.TransferId(5), *executed.TransferId)
+	pending, _ := suite.storage.Get(notDue.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, pending.Status)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *TransferSchedulerSuite) TestShouldRecordTheErrorOfFailedTransfer() {
+	scheduled := suite.schedule(suite.now)
+	suite.accounts.On("Transfer", suite.request(scheduled), model.UserId(1)).
+		Return(nil, &errors.BalanceTooLowError{AccountId: 1})
+
+	err := suite.scheduler.ExecuteDue(suite.now)
+
+	assert.NoError(suite.T(), err)
+	failed, _ := suite.storage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.FailedTransfer, failed.Status)
+	assert.Equal(suite.T(), "BalanceTooLowError", *failed.ErrorType)
+	assert.Equal(suite.T(), "The account 1 does not have enough money", *failed.ErrorMessage)
+	assert.Nil(suite.T(), failed.TransferId)
+}
+
+func (suite *TransferSchedulerSuite) TestShouldRetryTransferLaterOnServerError() {
+	scheduled := suite.schedule(suite.now)
+	suite.accounts.On("Transfer", suite.request(scheduled), model.UserId(1)).
+		Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Twice()
+
+	err := suite.scheduler.ExecuteDue(suite.now)
+	assert.NoError(suite.T(), err)
+	pending, _ := suite.storage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, pending.Status)
+	assert.Equal(suite.T(), 1, pending.Attempts)
+	assert.Equal(suite.T(), suite.now.Add(time.Minute), pending.ExecuteAt)
+
+	err = suite.scheduler.ExecuteDue(pending.ExecuteAt)
+	assert.NoError(suite.T(), err)
+	pending, _ = suite.storage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, pending.Status)
+	assert.Equal(suite.T(), 2, pending.Attempts)
+	assert.Equal(suite.T(), suite.now.Add(3*time.Minute), pending.ExecuteAt)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *TransferSchedulerSuite) TestShouldExecuteTheNextTransferWhenTheFirstFailsWithServerError() {
+	first := suite.schedule(suite.now.Add(-time.Minute))
+	second := suite.schedule(suite.now)
+	suite.accounts.On("Transfer", suite.request(first), model.UserId(1)).
+		Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Once()
+	suite.accounts.On("Transfer", suite.request(second), model.UserId(1)).Return(&model.Transfer{Id: 5}, nil).Once()
+
+	err := suite.scheduler.ExecuteDue(suite.now)
+
+	assert.NoError(suite.T(), err)
+	pending, _ := suite.storage.Get(first.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, pending.Status)
+	executed, _ := suite.storage.Get(second.Id)
+	assert.Equal(suite.T(), model.ExecutedTransfer, executed.Status)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *TransferSchedulerSuite) TestShouldFailTransferWhenTheAttemptsAreUsedUp() {
+	scheduled := suite.schedule(suite.now)
+	suite.accounts.On("Transfer", suite.request(scheduled), model.UserId(1)).
+		Return(nil, &errors.InternalServerError{Err: fmt.Errorf("connection refused")}).Times(3)
+
+	for i := 0; i < 3; i++ {
+		err := suite.scheduler.ExecuteDue(suite.now.Add(time.Duration(i) * time.Hour))
+		assert.NoError(suite.T(), err)
+	}
+
+	failed, _ := suite.storage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.FailedTransfer, failed.Status)
+	assert.Equal(suite.T(), 3, failed.Attempts)
+	assert.Equal(suite.T(), "InternalServerError", *failed.ErrorType)
+	suite.accounts.AssertExpectations(suite.T())
+}
+
+func (suite *TransferSchedulerSuite) TestShouldNotExecuteCancelledTransfer() {
+	scheduled := suite.schedule(suite.now)
+	_, _ = suite.storage.Cancel(scheduled.Id)
+
+	err := suite.scheduler.ExecuteDue(suite.now)
+
+	assert.NoError(suite.T(), err)
+	suite.accounts.AssertNotCalled(suite.T(), "Transfer")
+}
@@ -0,0 +1,86 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/statement"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Run the tests with -update to write the golden files again after a deliberate change of the format
+var update = flag.Bool("update", false, "update the golden files")
+
+type Camt053WriterSuite struct {
+	suite.Suite
+	statement *model.Statement
+}
+
+func TestCamt053WriterSuite(t *testing.T) {
+	suite.Run(t, new(Camt053WriterSuite))
+}
+
+func (suite *Camt053WriterSuite) SetupTest() {
+	suite.statement = &model.Statement{
+		Account:        &model.Account{Id: 1, Name: "Operations", Type: model.CheckingAccount, Currency: "EUR"},
+		From:           time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:      time.Date(2023, 2, 1, 6, 0, 0, 0, time.UTC),
+		OpeningBalance: decimal.NewFromInt(100),
+	}
+}
+
+func (suite *Camt053WriterSuite) write(entries []model.LedgerEntry) []byte {
+	var out bytes.Buffer
+	writer := statement.NewWriter(model.Camt053Statement, &out)
+	assert.NoError(suite.T(), writer.Begin(suite.statement))
+	for i := range entries {
+		assert.NoError(suite.T(), writer.Entry(&entries[i]))
+	}
+	assert.NoError(suite.T(), writer.End())
+	return out.Bytes()
+}
+
+func (suite *Camt053WriterSuite) assertGolden(name string, actual []byte) {
+	golden := filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(suite.T(), os.WriteFile(golden, actual, 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), string(expected), string(actual))
+}
+
+func (suite *Camt053WriterSuite) TestShouldWriteEntriesWithFeesAndCounterparties() {
+	counterparty := model.AccountId(2)
+	journals := []model.JournalId{5, 6, 7, 8}
+	suite.statement.ClosingBalance = decimal.RequireFromString("-25.1")
+
+	result := suite.write([]model.LedgerEntry{
+		{Id: 11, AccountId: 1, JournalId: &journals[0], Type: model.TopUpEntry, Amount: decimal.NewFromInt(50), Balance: decimal.NewFromInt(149),
+			Fee: decimal.NewFromInt(1), CreatedAt: time.Date(2023, 1, 5, 10, 30, 0, 0, time.UTC)},
+		{Id: 12, AccountId: 1, JournalId: &journals[1], Type: model.TransferInEntry, Amount: decimal.RequireFromString("25.5"), Balance: decimal.RequireFromString("174.5"),
+			Counterparty: &counterparty, Fee: decimal.Zero, CreatedAt: time.Date(2023, 1, 12, 14, 0, 0, 0, time.UTC)},
+		{Id: 13, AccountId: 1, JournalId: &journals[2], Type: model.TransferOutEntry, Amount: decimal.NewFromInt(-200), Balance: decimal.RequireFromString("-25.6"),
+			Counterparty: &counterparty, Fee: decimal.RequireFromString("0.1"), CreatedAt: time.Date(2023, 1, 20, 8, 0, 0, 0, time.UTC)},
+		{Id: 14, AccountId: 1, JournalId: &journals[3], Type: model.InterestEntry, Amount: decimal.RequireFromString("0.5"), Balance: decimal.RequireFromString("-25.1"),
+			Fee: decimal.Zero, CreatedAt: time.Date(2023, 1, 31, 0, 5, 0, 0, time.UTC)},
+	})
+
+	suite.assertGolden("camt053_entries.xml", result)
+}
+
+func (suite *Camt053WriterSuite) TestShouldWritePeriodWithoutEntries() {
+	suite.statement.Account.Name = ""
+	suite.statement.ClosingBalance = decimal.NewFromInt(100)
+
+	result := suite.write(nil)
+
+	suite.assertGolden("camt053_empty.xml", result)
+}
@@ -0,0 +1,89 @@
+package statement
+
+import (
+	"bytes"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/statement"
+	"testing"
+	"time"
+)
+
+type StatementWriterSuite struct {
+	suite.Suite
+	statement *model.Statement
+	entries   []model.LedgerEntry
+}
+
+func TestStatementWriterSuite(t *testing.T) {
+	suite.Run(t, new(StatementWriterSuite))
+}
+
+func (suite *StatementWriterSuite) SetupTest() {
+	counterparty := model.AccountId(2)
+	suite.statement = &model.Statement{
+		Account:        &model.Account{Id: 1, Name: "Main", Type: model.CheckingAccount, Currency: "EUR"},
+		From:           time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: decimal.NewFromInt(100),
+	}
+	suite.entries = []model.LedgerEntry{
+		{Id: 3, AccountId: 1, Type: model.TopUpEntry, Amount: decimal.NewFromInt(50), Balance: decimal.NewFromInt(150),
+			Fee: decimal.Zero, CreatedAt: time.Date(2023, 1, 5, 10, 30, 0, 0, time.UTC)},
+		{Id: 4, AccountId: 1, Type: model.TransferOutEntry, Amount: decimal.NewFromInt(-30), Balance: decimal.RequireFromString("119.9"),
+			Counterparty: &counterparty, Fee: decimal.RequireFromString("0.1"), CreatedAt: time.Date(2023, 1, 20, 8, 0, 0, 0, time.UTC)},
+	}
+}
+
+func (suite *StatementWriterSuite) write(format model.StatementFormat, entries []model.LedgerEntry, closing decimal.Decimal) string {
+	var out bytes.Buffer
+	suite.statement.ClosingBalance = closing
+	writer := statement.NewWriter(format, &out)
+	assert.NoError(suite.T(), writer.Begin(suite.statement))
+	for i := range entries {
+		assert.NoError(suite.T(), writer.Entry(&entries[i]))
+	}
+	assert.NoError(suite.T(), writer.End())
+	return out.String()
+}
+
+func (suite *StatementWriterSuite) TestShouldWriteCsv() {
+	result := suite.write(model.CsvStatement, suite.entries, decimal.RequireFromString("119.9"))
+
+	assert.Equal(suite.T(), "date,type,counterparty,amount,fee,balance\n"+
+		"2023-01-01T00:00:00Z,opening_balance,,,,100.00\n"+
+		"2023-01-05T10:30:00Z,top_up,,50.00,0.00,150.00\n"+
+		"2023-01-20T08:00:00Z,transfer_out,2,-30.00,0.10,119.90\n"+
+		"2023-02-01T00:00:00Z,closing_balance,,,,119.90\n", result)
+}
+
+func (suite *StatementWriterSuite) TestShouldWriteJson() {
+	result := suite.write(model.JsonStatement, suite.entries, decimal.RequireFromString("119.9"))
+
+	assert.Equal(suite.T(), "{\"account_id\":1,\"currency\":\"EUR\",\"from\":\"2023-01-01T00:00:00Z\",\"to\":\"2023-02-01T00:00:00Z\","+
+		"\"opening_balance\":\"100.00\",\"transactions\":["+
+		"{\"id\":3,\"type\":\"top_up\",\"amount\":\"50\",\"balance\":\"150\",\"created_at\":\"2023-01-05T10:30:00Z\"},"+
+		"{\"id\":4,\"type\":\"transfer_out\",\"amount\":\"-30\",\"balance\":\"119.9\",\"counterparty\":2,\"fee\":\"0.1\",\"created_at\":\"2023-01-20T08:00:00Z\"}"+
+		"],\"closing_balance\":\"119.90\"}\n", result)
+}
+
+func (suite *StatementWriterSuite) TestShouldWriteJsonWithoutEntries() {
+	result := suite.write(model.JsonStatement, nil, decimal.NewFromInt(100))
+
+	assert.Equal(suite.T(), "{\"account_id\":1,\"currency\":\"EUR\",\"from\":\"2023-01-01T00:00:00Z\",\"to\":\"2023-02-01T00:00:00Z\","+
+		"\"opening_balance\":\"100.00\",\"transactions\":[],\"closing_balance\":\"100.00\"}\n", result)
+}
+
+func (suite *StatementWriterSuite) TestShouldWriteText() {
+	result := suite.write(model.TextStatement, suite.entries, decimal.RequireFromString("119.9"))
+
+	assert.Equal(suite.T(), "Statement of the account 1 (Main) in EUR\n"+
+		"From 2023-01-01 00:00:00 until 2023-02-01 00:00:00 UTC\n\n"+
+		"Date                 Type                        Amount         Fee           Balance  Counterparty\n"+
+		"                     Opening balance                                           100.00\n"+
+		"2023-01-05 10:30:00  top_up                       50.00        0.00            150.00\n"+
+		"2023-01-20 08:00:00  transfer_out                -30.00        0.10            119.90  2\n"+
+		"                     Closing balance                                           119.90\n", result)
+}
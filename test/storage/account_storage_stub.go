@@ -10,8 +10,8 @@ type StubAccountStorage struct {
 	mock.Mock
 }
 
-func (storage *StubAccountStorage) Create(owner model.UserId) (*model.Account, error) {
-	args := storage.Called(owner)
+func (storage *StubAccountStorage) Create(account *model.Account) (*model.Account, error) {
+	args := storage.Called(account)
 	if account, ok := args.Get(0).(*model.Account); ok {
 		return account, args.Error(1)
 	} else {
@@ -28,12 +28,137 @@ func (storage *StubAccountStorage) Get(accountId model.AccountId) (*model.Accoun
 	}
 }
 
-func (storage *StubAccountStorage) TopUp(accountId model.AccountId, amount decimal.Decimal) error {
-	args := storage.Called(accountId, amount)
-	return args.Error(0)
+func (storage *StubAccountStorage) ListByOwner(owner model.UserId) ([]model.Account, error) {
+	args := storage.Called(owner)
+	if accounts, ok := args.Get(0).([]model.Account); ok {
+		return accounts, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) ListOverdrawn() ([]model.Account, error) {
+	args := storage.Called()
+	if accounts, ok := args.Get(0).([]model.Account); ok {
+		return accounts, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) TopUp(accountId model.AccountId, amount, fee decimal.Decimal) (*model.LedgerEntry, error) {
+	args := storage.Called(accountId, amount, fee)
+	if entry, ok := args.Get(0).(*model.LedgerEntry); ok {
+		return entry, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) Transfer(transfer *model.Transfer) (*model.Transfer, error) {
+	args := storage.Called(transfer)
+	if created, ok := args.Get(0).(*model.Transfer); ok {
+		return created, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) TransferWithinLimits(transfer *model.Transfer, limits model.Limits) (*model.Transfer, error) {
+	args := storage.Called(transfer, limits)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
 }
 
-func (storage *StubAccountStorage) Transfer(from, to model.AccountId, amount decimal.Decimal) error {
-	args := storage.Called(from, to, amount)
-	return args.Error(0)
+func (storage *StubAccountStorage) TransferLegs(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	args := storage.Called(transfers, limits)
+	if transfers, ok := args.Get(0).([]model.Transfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) TransferAll(transfers []*model.Transfer, limits model.Limits) ([]model.Transfer, error) {
+	args := storage.Called(transfers, limits)
+	if transfers, ok := args.Get(0).([]model.Transfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) GetTransferByOrigin(origin string) (*model.Transfer, error) {
+	args := storage.Called(origin)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) GetTransfer(transferId model.TransferId) (*model.Transfer, error) {
+	args := storage.Called(transferId)
+	if transfer, ok := args.Get(0).(*model.Transfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) Reverse(transferId model.TransferId, partial bool) (*model.Transfer, error) {
+	args := storage.Called(transferId, partial)
+	if reversal, ok := args.Get(0).(*model.Transfer); ok {
+		return reversal, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) SetStatus(accountId model.AccountId, status model.AccountStatus) (*model.Account, error) {
+	args := storage.Called(accountId, status)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) SetOverdraftLimit(accountId model.AccountId, limit decimal.Decimal) (*model.Account, error) {
+	args := storage.Called(accountId, limit)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) SetTransferLimits(accountId model.AccountId, limits model.TransferLimits) (*model.Account, error) {
+	args := storage.Called(accountId, limits)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) SetInterestRate(accountId model.AccountId, rate decimal.Decimal) (*model.Account, error) {
+	args := storage.Called(accountId, rate)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubAccountStorage) Close(accountId model.AccountId, sweepTo *model.AccountId) (*model.Account, error) {
+	args := storage.Called(accountId, sweepTo)
+	if account, ok := args.Get(0).(*model.Account); ok {
+		return account, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
 }
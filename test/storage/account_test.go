@@ -1,40 +1,59 @@
 package storage
 
 import (
+	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
 	"golang_bank_demo/src/storage"
 	"golang_bank_demo/test/postgres"
 	"testing"
 )
 
+// AccountStorageSuite is the contract that every storage backend has to fulfil
 type AccountStorageSuite struct {
 	suite.Suite
-	postgres.PostgresTestSuite
 	storage storage.AccountStorage
 }
 
-func TestAccountStorageSuite(t *testing.T) {
-	suite.Run(t, new(AccountStorageSuite))
+type PostgresAccountStorageSuite struct {
+	AccountStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresAccountStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresAccountStorageSuite))
 }
 
-func (suite *AccountStorageSuite) SetupSuite() {
+func (suite *PostgresAccountStorageSuite) SetupSuite() {
 	suite.PostgresTestSuite.SetupSuite(suite.T())
 	suite.storage = storage.NewPostgresAccountStorage(suite.Db)
 }
 
-func (suite *AccountStorageSuite) SetupTest() {
+func (suite *PostgresAccountStorageSuite) SetupTest() {
 	suite.PostgresTestSuite.SetupTest(suite.T())
 }
 
-func (suite *AccountStorageSuite) TearDownTest() {
+func (suite *PostgresAccountStorageSuite) TearDownTest() {
 	suite.PostgresTestSuite.TearDownTest(suite.T())
 }
 
+type InMemoryAccountStorageSuite struct {
+	AccountStorageSuite
+}
+
+func TestInMemoryAccountStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryAccountStorageSuite))
+}
+
+func (suite *InMemoryAccountStorageSuite) SetupTest() {
+	suite.storage = storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+}
+
 func (suite *AccountStorageSuite) TestShouldCreateAndGetAnAccount() {
-	createdAccount, err := suite.storage.Create(1)
+	createdAccount, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 
 	foundAccount, err := suite.storage.Get(createdAccount.Id)
@@ -42,14 +61,40 @@ func (suite *AccountStorageSuite) TestShouldCreateAndGetAnAccount() {
 	assert.Equal(suite.T(), createdAccount, foundAccount)
 }
 
-func (suite *AccountStorageSuite) TestShouldNotCreateADuplicateAccount() {
-	createdAccount, err := suite.storage.Create(1)
+func (suite *AccountStorageSuite) TestShouldCreateSeveralAccountsForOneUser() {
+	firstAccount, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	secondAccount, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	savingsAccount, err := suite.storage.Create(&model.Account{Owner: 1, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+
+	accounts, err := suite.storage.ListByOwner(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []model.Account{*firstAccount, *secondAccount, *savingsAccount}, accounts)
+}
+
+func (suite *AccountStorageSuite) TestShouldListNoAccountsForNewUser() {
+	accounts, err := suite.storage.ListByOwner(1)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), accounts)
+}
+
+func (suite *AccountStorageSuite) TestShouldNotCreateAnAccountWithDuplicateName() {
+	createdAccount, err := suite.storage.Create(&model.Account{Owner: 1, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), createdAccount)
 
-	duplicateAccount, err := suite.storage.Create(1)
-	assert.ErrorIs(suite.T(), err, &errors.DuplicateAccountError{UserId: 1})
+	duplicateAccount, err := suite.storage.Create(&model.Account{Owner: 1, Name: "Holidays", Type: model.CheckingAccount, Currency: "EUR"})
+	assert.ErrorIs(suite.T(), err, &errors.DuplicateAccountError{UserId: 1, Name: "Holidays"})
 	assert.Nil(suite.T(), duplicateAccount)
+
+	anotherUserAccount, err := suite.storage.Create(&model.Account{Owner: 2, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), anotherUserAccount)
 }
 
 func (suite *AccountStorageSuite) TestShouldNotGetAccountThatDoesNotExist() {
@@ -60,70 +105,590 @@ func (suite *AccountStorageSuite) TestShouldNotGetAccountThatDoesNotExist() {
 }
 
 func (suite *AccountStorageSuite) TestShouldTopUpTheAccount() {
-	createdAccount, err := suite.storage.Create(1)
+	createdAccount, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 
-	err = suite.storage.TopUp(createdAccount.Id, decimal.NewFromInt(100))
+	_, err = suite.storage.TopUp(createdAccount.Id, decimal.NewFromInt(100), decimal.Zero)
 	assert.NoError(suite.T(), err)
-	err = suite.storage.TopUp(createdAccount.Id, decimal.NewFromInt(200))
+	_, err = suite.storage.TopUp(createdAccount.Id, decimal.NewFromInt(200), decimal.Zero)
 	assert.NoError(suite.T(), err)
 
 	foundAccount, err := suite.storage.Get(createdAccount.Id)
 	assert.NoError(suite.T(), err)
 
-	assert.Equal(suite.T(), foundAccount.Balance, decimal.NewFromInt(300))
+	assert.Equal(suite.T(), "300", foundAccount.Balance.String())
 }
 
 func (suite *AccountStorageSuite) TestShouldNotTopUpTheAccountThatDoesNotExist() {
-	err := suite.storage.TopUp(123, decimal.NewFromInt(100))
+	_, err := suite.storage.TopUp(123, decimal.NewFromInt(100), decimal.Zero)
 
 	assert.ErrorIs(suite.T(), err, &errors.AccountDoesNotExistError{AccountId: 123})
 }
 
 func (suite *AccountStorageSuite) TestShouldTransfer() {
-	createdAccount1, err := suite.storage.Create(1)
+	createdAccount1, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 
-	createdAccount2, err := suite.storage.Create(2)
+	createdAccount2, err := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 
-	err = suite.storage.TopUp(createdAccount1.Id, decimal.NewFromInt(200))
+	_, err = suite.storage.TopUp(createdAccount1.Id, decimal.NewFromInt(200), decimal.Zero)
 	assert.NoError(suite.T(), err)
 
-	err = suite.storage.Transfer(createdAccount1.Id, createdAccount2.Id, decimal.NewFromInt(200))
+	_, err = suite.storage.Transfer(model.NewTransfer(createdAccount1.Id, createdAccount2.Id, decimal.NewFromInt(200)))
 	assert.NoError(suite.T(), err)
 
 	foundAccount1, err := suite.storage.Get(createdAccount1.Id)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), foundAccount1.Balance, decimal.NewFromInt(0))
+	assert.Equal(suite.T(), "0", foundAccount1.Balance.String())
 
 	foundAccount2, err := suite.storage.Get(createdAccount2.Id)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), foundAccount2.Balance, decimal.NewFromInt(200))
+	assert.Equal(suite.T(), "200", foundAccount2.Balance.String())
 }
 
 func (suite *AccountStorageSuite) TestShouldNotTransferFromAccountThatDoesNotExist() {
-	err := suite.storage.Transfer(1, 2, decimal.NewFromInt(100))
+	_, err := suite.storage.Transfer(model.NewTransfer(1, 2, decimal.NewFromInt(100)))
 
 	assert.ErrorIs(suite.T(), err, &errors.AccountDoesNotExistError{AccountId: 1})
 }
 
 func (suite *AccountStorageSuite) TestShouldNotTransferToAccountThatDoesNotExist() {
-	createdAccount1, err := suite.storage.Create(1)
+	createdAccount1, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
-	err = suite.storage.TopUp(createdAccount1.Id, decimal.NewFromInt(300))
+	_, err = suite.storage.TopUp(createdAccount1.Id, decimal.NewFromInt(300), decimal.Zero)
 	assert.NoError(suite.T(), err)
 
-	err = suite.storage.Transfer(createdAccount1.Id, 2, decimal.NewFromInt(100))
+	_, err = suite.storage.Transfer(model.NewTransfer(createdAccount1.Id, 123, decimal.NewFromInt(100)))
 
-	assert.ErrorIs(suite.T(), err, &errors.AccountDoesNotExistError{AccountId: 2})
+	assert.ErrorIs(suite.T(), err, &errors.AccountDoesNotExistError{AccountId: 123})
 }
 
 func (suite *AccountStorageSuite) TestShouldNotTransferWhenNtEnoughMoney() {
-	createdAccount1, err := suite.storage.Create(1)
+	createdAccount1, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
 	assert.NoError(suite.T(), err)
 
-	err = suite.storage.Transfer(createdAccount1.Id, 2, decimal.NewFromInt(100))
+	_, err = suite.storage.Transfer(model.NewTransfer(createdAccount1.Id, 2, decimal.NewFromInt(100)))
 
 	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: createdAccount1.Id})
 }
+
+func (suite *AccountStorageSuite) TestShouldCreateAnAccountInCurrency() {
+	createdAccount, err := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "JPY"})
+	assert.NoError(suite.T(), err)
+
+	foundAccount, err := suite.storage.Get(createdAccount.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.Currency("JPY"), foundAccount.Currency)
+}
+
+func (suite *AccountStorageSuite) TestShouldFreezeAndUnfreezeAnAccount() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+
+	frozenAccount, err := suite.storage.SetStatus(account.Id, model.FrozenAccount)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.FrozenAccount, frozenAccount.Status)
+	_, err = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+	assert.Equal(suite.T(), &errors.AccountFrozenError{AccountId: account.Id}, err)
+
+	activeAccount, err := suite.storage.SetStatus(account.Id, model.ActiveAccount)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ActiveAccount, activeAccount.Status)
+	_, err = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AccountStorageSuite) TestShouldNotTransferToFrozenAccount() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(10), decimal.Zero)
+	_, _ = suite.storage.SetStatus(to.Id, model.FrozenAccount)
+
+	_, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(5)))
+
+	assert.Equal(suite.T(), &errors.AccountFrozenError{AccountId: to.Id}, err)
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "10", fromAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldReturnTheLedgerEntryOfTopUp() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+
+	entry, err := suite.storage.TopUp(account.Id, decimal.NewFromInt(15), decimal.Zero)
+
+	assert.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), entry.Id)
+	assert.False(suite.T(), entry.CreatedAt.IsZero())
+	assert.Equal(suite.T(), model.TopUpEntry, entry.Type)
+	assert.Equal(suite.T(), "15", entry.Amount.String())
+	assert.Equal(suite.T(), "25", entry.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldGetTransfer() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "70", transfer.Balance.String())
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(5), decimal.Zero)
+
+	found, err := suite.storage.GetTransfer(transfer.Id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), transfer.Id, found.Id)
+	assert.Equal(suite.T(), from.Id, found.From)
+	assert.Equal(suite.T(), to.Id, found.To)
+	assert.Equal(suite.T(), "30", found.Amount.String())
+	assert.Equal(suite.T(), "70", found.Balance.String())
+	assert.Equal(suite.T(), model.CompletedTransaction, found.Status())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotGetTransferThatDoesNotExist() {
+	_, err := suite.storage.GetTransfer(123)
+
+	assert.ErrorIs(suite.T(), err, &errors.TransferDoesNotExistError{TransferId: 123})
+}
+
+func (suite *AccountStorageSuite) TestShouldMakeOneTransferPerOrigin() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	origin := "scheduled_transfer:1"
+	transfer := model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30))
+	transfer.Origin = &origin
+	made, err := suite.storage.Transfer(transfer)
+	assert.NoError(suite.T(), err)
+
+	found, err := suite.storage.GetTransferByOrigin(origin)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), made.Id, found.Id)
+	assert.Equal(suite.T(), "70", found.Balance.String())
+	again := model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30))
+	again.Origin = &origin
+	_, err = suite.storage.Transfer(again)
+	assert.IsType(suite.T(), &errors.InternalServerError{}, err)
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "70", account.Balance.String())
+	notMade, err := suite.storage.GetTransferByOrigin("scheduled_transfer:2")
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), notMade)
+}
+
+func (suite *AccountStorageSuite) TestShouldReverseTransfer() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), transfer.Id)
+
+	reversal, err := suite.storage.Reverse(transfer.Id, false)
+
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), transfer.Id, reversal.Id)
+	assert.Equal(suite.T(), &transfer.Id, reversal.ReversalOf)
+	assert.Equal(suite.T(), to.Id, reversal.From)
+	assert.Equal(suite.T(), from.Id, reversal.To)
+	assert.Equal(suite.T(), "30", reversal.Amount.String())
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "100", fromAccount.Balance.String())
+	toAccount, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "0", toAccount.Balance.String())
+	reversed, _ := suite.storage.GetTransfer(transfer.Id)
+	assert.Equal(suite.T(), model.ReversedTransaction, reversed.Status())
+	assert.Equal(suite.T(), &reversal.Id, reversed.ReversedBy)
+}
+
+func (suite *AccountStorageSuite) TestShouldNotReverseTransferTwice() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, _ := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	reversal, err := suite.storage.Reverse(transfer.Id, false)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.storage.Reverse(transfer.Id, false)
+
+	assert.Equal(suite.T(), &errors.TransferAlreadyReversedError{TransferId: transfer.Id, ReversalId: reversal.Id}, err)
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "100", fromAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotReverseTransferThatDoesNotExist() {
+	_, err := suite.storage.Reverse(123, false)
+
+	assert.ErrorIs(suite.T(), err, &errors.TransferDoesNotExistError{TransferId: 123})
+}
+
+func (suite *AccountStorageSuite) TestShouldNotReverseWhenReceiverHasSpentTheMoney() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	other, _ := suite.storage.Create(&model.Account{Owner: 3, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, _ := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	_, _ = suite.storage.Transfer(model.NewTransfer(to.Id, other.Id, decimal.NewFromInt(20)))
+
+	_, err := suite.storage.Reverse(transfer.Id, false)
+
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: to.Id})
+	assert.EqualError(suite.T(), err, fmt.Sprintf("The account %d does not have enough money, 20 more is needed", to.Id))
+	toAccount, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "10", toAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldPartiallyReverseWhenReceiverHasSpentTheMoney() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	other, _ := suite.storage.Create(&model.Account{Owner: 3, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, _ := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	_, _ = suite.storage.Transfer(model.NewTransfer(to.Id, other.Id, decimal.NewFromInt(20)))
+
+	reversal, err := suite.storage.Reverse(transfer.Id, true)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "10", reversal.Amount.String())
+	assert.Equal(suite.T(), "10", reversal.CreditAmount.String())
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "80", fromAccount.Balance.String())
+	toAccount, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "0", toAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldCloseAnEmptyAccount() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+
+	closedAccount, err := suite.storage.Close(account.Id, nil)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ClosedAccount, closedAccount.Status)
+	_, err = suite.storage.SetStatus(account.Id, model.ActiveAccount)
+	assert.Equal(suite.T(), &errors.AccountClosedError{AccountId: account.Id}, err)
+}
+
+func (suite *AccountStorageSuite) TestShouldNotCloseAnAccountWithMoney() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+
+	_, err := suite.storage.Close(account.Id, nil)
+
+	assert.Equal(suite.T(), &errors.NonZeroBalanceError{AccountId: account.Id}, err)
+	foundAccount, _ := suite.storage.Get(account.Id)
+	assert.Equal(suite.T(), model.ActiveAccount, foundAccount.Status)
+}
+
+func (suite *AccountStorageSuite) TestShouldSweepMoneyWhenClosingAnAccount() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	target, _ := suite.storage.Create(&model.Account{Owner: 1, Name: "Savings", Type: model.SavingsAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(account.Id, decimal.NewFromInt(10), decimal.Zero)
+
+	closedAccount, err := suite.storage.Close(account.Id, &target.Id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ClosedAccount, closedAccount.Status)
+	assert.Equal(suite.T(), "0", closedAccount.Balance.String())
+	targetAccount, _ := suite.storage.Get(target.Id)
+	assert.Equal(suite.T(), "10", targetAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldChargeTheFeeOfTopUp() {
+	account, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+
+	entry, err := suite.storage.TopUp(account.Id, decimal.NewFromInt(100), decimal.RequireFromString("1.5"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1.5", entry.Fee.String())
+	assert.Equal(suite.T(), "98.5", entry.Balance.String())
+	updated, _ := suite.storage.Get(account.Id)
+	assert.Equal(suite.T(), "98.5", updated.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldChargeTheFeeOfTransferToTheSender() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer := model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(50))
+	transfer.Fee = decimal.NewFromInt(2)
+
+	created, err := suite.storage.Transfer(transfer)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "48", created.Balance.String())
+	found, _ := suite.storage.GetTransfer(created.Id)
+	assert.Equal(suite.T(), "2", found.Fee.String())
+	assert.Equal(suite.T(), "48", found.Balance.String())
+	receiver, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "50", receiver.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotTransferWhenTheFeeIsNotCovered() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(50), decimal.Zero)
+	transfer := model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(50))
+	transfer.Fee = decimal.NewFromInt(2)
+
+	_, err := suite.storage.Transfer(transfer)
+
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: from.Id})
+	assert.EqualError(suite.T(), err, fmt.Sprintf("The account %d does not have enough money, 2 more is needed", from.Id))
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "50", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldTransferWithinOverdraftLimit() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(50), decimal.Zero)
+	updated, err := suite.storage.SetOverdraftLimit(from.Id, decimal.NewFromInt(100))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "150", updated.Available().String())
+
+	transfer, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(120)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "-70", transfer.Balance.String())
+
+	_, err = suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(40)))
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: from.Id})
+	assert.EqualError(suite.T(), err, fmt.Sprintf("The account %d does not have enough money, 10 more is needed", from.Id))
+}
+
+func (suite *AccountStorageSuite) TestShouldRepayAccountOverdrawnPastItsLoweredLimit() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.SetOverdraftLimit(from.Id, decimal.NewFromInt(500))
+	_, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(500)))
+	assert.NoError(suite.T(), err)
+	_, _ = suite.storage.SetOverdraftLimit(from.Id, decimal.NewFromInt(100))
+
+	entry, err := suite.storage.TopUp(from.Id, decimal.NewFromInt(200), decimal.NewFromInt(1))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "-301", entry.Balance.String())
+	_, err = suite.storage.Transfer(model.NewTransfer(to.Id, from.Id, decimal.NewFromInt(100)))
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(1)))
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: from.Id})
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "-201", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldListOnlyOverdrawnCustomerAccounts() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(to.Id, decimal.NewFromInt(10), decimal.Zero)
+	_, _ = suite.storage.SetOverdraftLimit(from.Id, decimal.NewFromInt(100))
+	_, _ = suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+
+	accounts, err := suite.storage.ListOverdrawn()
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(accounts))
+	assert.Equal(suite.T(), from.Id, accounts[0].Id)
+	assert.Equal(suite.T(), "-30", accounts[0].Balance.String())
+	assert.Equal(suite.T(), "100", accounts[0].OverdraftLimit.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotTransferMoreThanTheDailyLimit() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}}}
+	_, err := suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(70)), limits)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(40)), limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(from.Id), Period: model.DailyPeriod})
+	assert.Equal(suite.T(), "30", err.(*errors.LimitExceededError).Remaining.String())
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "430", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldApplyTheLimitsOfTheCurrencyOfTheSourceAccount() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "USD"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "USD"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	limits := model.Limits{
+		"EUR": {Account: model.TransferLimits{PerTransactionLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}},
+		"USD": {Account: model.TransferLimits{PerTransactionLimit: decimal.NewNullDecimal(decimal.NewFromInt(200))}},
+	}
+	_, err := suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(150)), limits)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(250)), limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(from.Id), Period: model.PerTransactionPeriod})
+	assert.Equal(suite.T(), "200", err.(*errors.LimitExceededError).Limit.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotTransferMoreThanThePerTransactionLimit() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{PerTransactionLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}}}
+
+	_, err := suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(101)), limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(from.Id), Period: model.PerTransactionPeriod})
+}
+
+func (suite *AccountStorageSuite) TestShouldCountTransfersFromAllAccountsOfTheUser() {
+	first, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	second, _ := suite.storage.Create(&model.Account{Owner: 1, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(first.Id, decimal.NewFromInt(500), decimal.Zero)
+	_, _ = suite.storage.TopUp(second.Id, decimal.NewFromInt(500), decimal.Zero)
+	limits := model.Limits{"EUR": {User: model.TransferLimits{MonthlyLimit: decimal.NewNullDecimal(decimal.NewFromInt(300))}}}
+	_, err := suite.storage.TransferWithinLimits(model.NewTransfer(first.Id, to.Id, decimal.NewFromInt(200)), limits)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.storage.TransferWithinLimits(model.NewTransfer(second.Id, to.Id, decimal.NewFromInt(150)), limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.UserLimitScope, Id: 1, Period: model.MonthlyPeriod})
+	assert.EqualError(suite.T(), err, "The transfer exceeds the monthly limit of 300 of the user 1, 100 is left")
+}
+
+func (suite *AccountStorageSuite) TestShouldApplyTheLimitsOfTheAccount() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	updated, err := suite.storage.SetTransferLimits(from.Id, model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(400))})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "400", updated.DailyLimit.Decimal.String())
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}}}
+
+	_, err = suite.storage.TransferWithinLimits(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(300)), limits)
+
+	assert.NoError(suite.T(), err)
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "400", account.DailyLimit.Decimal.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotCountReversalsIntoTheLimits() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	transfer, _ := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(100)))
+	_, err := suite.storage.Reverse(transfer.Id, false)
+	assert.NoError(suite.T(), err)
+	_, _ = suite.storage.TopUp(to.Id, decimal.NewFromInt(100), decimal.Zero)
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}}}
+
+	_, err = suite.storage.TransferWithinLimits(model.NewTransfer(to.Id, from.Id, decimal.NewFromInt(100)), limits)
+
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *AccountStorageSuite) TestShouldTransferAll() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+
+	transfers, err := suite.storage.TransferAll([]*model.Transfer{
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)),
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(40)),
+	}, model.Limits{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(transfers))
+	assert.Equal(suite.T(), "40", transfers[0].Balance.String())
+	assert.Equal(suite.T(), "0", transfers[1].Balance.String())
+	account, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "100", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldTransferNothingWhenOneOfAllTransfersFails() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+
+	_, err := suite.storage.TransferAll([]*model.Transfer{
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)),
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(50)),
+	}, model.Limits{})
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 1})
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: from.Id})
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "100", fromAccount.Balance.String())
+	toAccount, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "0", toAccount.Balance.String())
+	transfer, err := suite.storage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(100)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "0", transfer.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldCountEarlierTransfersOfAllIntoTheLimits() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(500), decimal.Zero)
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(100))}}}
+
+	_, err := suite.storage.TransferAll([]*model.Transfer{
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(70)),
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(40)),
+	}, limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 1})
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(from.Id), Period: model.DailyPeriod})
+	account, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "500", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldTransferLegs() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	first, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	second, _ := suite.storage.Create(&model.Account{Owner: 3, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+
+	transfers, err := suite.storage.TransferLegs([]*model.Transfer{
+		model.NewTransfer(from.Id, first.Id, decimal.NewFromInt(60)),
+		model.NewTransfer(from.Id, second.Id, decimal.NewFromInt(40)),
+	}, model.Limits{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "40", transfers[0].Balance.String())
+	assert.Equal(suite.T(), "0", transfers[1].Balance.String())
+	firstAccount, _ := suite.storage.Get(first.Id)
+	assert.Equal(suite.T(), "60", firstAccount.Balance.String())
+	secondAccount, _ := suite.storage.Get(second.Id)
+	assert.Equal(suite.T(), "40", secondAccount.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldNotTransferLegsWhenTheTotalIsNotCovered() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	second := model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(40))
+	second.Fee = decimal.NewFromInt(1)
+
+	_, err := suite.storage.TransferLegs([]*model.Transfer{model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)), second}, model.Limits{})
+
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: from.Id})
+	assert.EqualError(suite.T(), err, fmt.Sprintf("The account %d does not have enough money, 1 more is needed", from.Id))
+	account, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "0", account.Balance.String())
+}
+
+func (suite *AccountStorageSuite) TestShouldTransferNoLegWhenOneFails() {
+	from, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	frozen, _ := suite.storage.Create(&model.Account{Owner: 3, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.SetStatus(frozen.Id, model.FrozenAccount)
+	_, _ = suite.storage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+
+	_, err := suite.storage.TransferLegs([]*model.Transfer{
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)),
+		model.NewTransfer(from.Id, frozen.Id, decimal.NewFromInt(40)),
+	}, model.Limits{})
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchTransferError{Index: 1})
+	assert.ErrorIs(suite.T(), err, &errors.AccountFrozenError{AccountId: frozen.Id})
+	fromAccount, _ := suite.storage.Get(from.Id)
+	assert.Equal(suite.T(), "100", fromAccount.Balance.String())
+	toAccount, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), "0", toAccount.Balance.String())
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+)
+
+type StubBatchStorage struct {
+	mock.Mock
+}
+
+func (storage *StubBatchStorage) Create(batch *model.Batch) (*model.Batch, error) {
+	args := storage.Called(batch)
+	if created, ok := args.Get(0).(*model.Batch); ok {
+		return created, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubBatchStorage) Get(id model.BatchId) (*model.Batch, error) {
+	args := storage.Called(id)
+	if batch, ok := args.Get(0).(*model.Batch); ok {
+		return batch, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubBatchStorage) Finish(batch *model.Batch) (*model.Batch, error) {
+	args := storage.Called(batch)
+	if finished, ok := args.Get(0).(*model.Batch); ok {
+		return finished, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
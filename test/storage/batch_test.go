@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+)
+
+// BatchStorageSuite is the contract that every storage backend has to fulfil
+type BatchStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	batchStorage   storage.BatchStorage
+	from           model.AccountId
+	to             model.AccountId
+}
+
+type PostgresBatchStorageSuite struct {
+	BatchStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresBatchStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresBatchStorageSuite))
+}
+
+func (suite *PostgresBatchStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.batchStorage = storage.NewPostgresBatchStorage(suite.Db)
+}
+
+func (suite *PostgresBatchStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+	suite.createAccounts()
+}
+
+func (suite *PostgresBatchStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryBatchStorageSuite struct {
+	BatchStorageSuite
+}
+
+func TestInMemoryBatchStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryBatchStorageSuite))
+}
+
+func (suite *InMemoryBatchStorageSuite) SetupTest() {
+	suite.accountStorage = storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	suite.batchStorage = storage.NewInMemoryBatchStorage()
+	suite.createAccounts()
+}
+
+func (suite *BatchStorageSuite) createAccounts() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.accountStorage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	suite.from = from.Id
+	suite.to = to.Id
+}
+
+func (suite *BatchStorageSuite) create() *model.Batch {
+	batch, err := suite.batchStorage.Create(&model.Batch{
+		Owner:  1,
+		Mode:   model.BestEffortBatch,
+		Status: model.ProcessingBatch,
+		Lines: []model.BatchLine{
+			{Line: 1, Reference: "rent", From: suite.from, To: suite.to, Amount: decimal.NewFromInt(60), Status: model.PendingBatchLine},
+			{Line: 2, From: suite.from, To: suite.to, Amount: decimal.NewFromInt(50), Status: model.PendingBatchLine},
+		},
+	})
+	assert.NoError(suite.T(), err)
+	return batch
+}
+
+func (suite *BatchStorageSuite) TestShouldCreateAndGetBatch() {
+	created := suite.create()
+
+	found, err := suite.batchStorage.Get(created.Id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.UserId(1), found.Owner)
+	assert.Equal(suite.T(), model.BestEffortBatch, found.Mode)
+	assert.Equal(suite.T(), model.ProcessingBatch, found.Status)
+	assert.False(suite.T(), found.CreatedAt.IsZero())
+	assert.Equal(suite.T(), 2, len(found.Lines))
+	assert.Equal(suite.T(), created.Id, found.Lines[0].BatchId)
+	assert.Equal(suite.T(), "rent", found.Lines[0].Reference)
+	assert.Equal(suite.T(), "60", found.Lines[0].Amount.String())
+	assert.Equal(suite.T(), model.PendingBatchLine, found.Lines[0].Status)
+	assert.Equal(suite.T(), 2, found.Lines[1].Line)
+	assert.Nil(suite.T(), found.Lines[1].TransferId)
+}
+
+func (suite *BatchStorageSuite) TestShouldNotGetBatchThatDoesNotExist() {
+	_, err := suite.batchStorage.Get(42)
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchDoesNotExistError{BatchId: 42})
+}
+
+func (suite *BatchStorageSuite) TestShouldStoreTheResultsOfTheLines() {
+	batch := suite.create()
+	transfer, err := suite.accountStorage.Transfer(model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(60)))
+	assert.NoError(suite.T(), err)
+	errorType, message := "BalanceTooLowError", "The account does not have enough money"
+	batch.Lines[0].Status = model.CompletedBatchLine
+	batch.Lines[0].TransferId = &transfer.Id
+	batch.Lines[1].Status = model.FailedBatchLine
+	batch.Lines[1].ErrorType = &errorType
+	batch.Lines[1].ErrorMessage = &message
+	batch.Finish()
+
+	finished, err := suite.batchStorage.Finish(batch)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.PartiallyCompletedBatch, finished.Status)
+	found, _ := suite.batchStorage.Get(batch.Id)
+	assert.Equal(suite.T(), model.PartiallyCompletedBatch, found.Status)
+	assert.Equal(suite.T(), model.CompletedBatchLine, found.Lines[0].Status)
+	assert.Equal(suite.T(), transfer.Id, *found.Lines[0].TransferId)
+	assert.Equal(suite.T(), model.FailedBatchLine, found.Lines[1].Status)
+	assert.Equal(suite.T(), errorType, *found.Lines[1].ErrorType)
+	assert.Equal(suite.T(), message, *found.Lines[1].ErrorMessage)
+}
+
+func (suite *BatchStorageSuite) TestShouldNotFinishBatchThatDoesNotExist() {
+	_, err := suite.batchStorage.Finish(&model.Batch{Id: 42, Status: model.FailedBatch})
+
+	assert.ErrorIs(suite.T(), err, &errors.BatchDoesNotExistError{BatchId: 42})
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+const (
+	concurrentAccounts  = 5
+	concurrentWorkers   = 20
+	transfersPerWorker  = 50
+	initialTestBalance  = 1000
+	maxTestTransferSize = 100
+)
+
+// ConcurrentTransferSuite is the contract that every storage backend has to fulfil
+type ConcurrentTransferSuite struct {
+	suite.Suite
+	storage storage.AccountStorage
+}
+
+type PostgresConcurrentTransferSuite struct {
+	ConcurrentTransferSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresConcurrentTransferSuite(t *testing.T) {
+	suite.Run(t, new(PostgresConcurrentTransferSuite))
+}
+
+func (suite *PostgresConcurrentTransferSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.storage = storage.NewPostgresAccountStorage(suite.Db)
+}
+
+func (suite *PostgresConcurrentTransferSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+}
+
+func (suite *PostgresConcurrentTransferSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryConcurrentTransferSuite struct {
+	ConcurrentTransferSuite
+}
+
+func TestInMemoryConcurrentTransferSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryConcurrentTransferSuite))
+}
+
+func (suite *InMemoryConcurrentTransferSuite) SetupTest() {
+	suite.storage = storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+}
+
+func (suite *ConcurrentTransferSuite) TestShouldNotDeadlockOnOppositeTransfers() {
+	accountIds := suite.createAccounts(2)
+	first, second := accountIds[0], accountIds[1]
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*concurrentWorkers*transfersPerWorker)
+
+	for worker := 0; worker < concurrentWorkers; worker++ {
+		from, to := first, second
+		if worker%2 == 1 {
+			from, to = second, first
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < transfersPerWorker; i++ {
+				_, err := suite.storage.Transfer(model.NewTransfer(from, to, decimal.NewFromInt(1)))
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+	suite.assertTotalBalance(accountIds)
+}
+
+func (suite *ConcurrentTransferSuite) TestShouldConserveMoneyUnderConcurrentTransfers() {
+	accountIds := suite.createAccounts(concurrentAccounts)
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentWorkers*transfersPerWorker)
+
+	for worker := 0; worker < concurrentWorkers; worker++ {
+		random := rand.New(rand.NewSource(int64(worker)))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < transfersPerWorker; i++ {
+				from := accountIds[random.Intn(len(accountIds))]
+				to := accountIds[random.Intn(len(accountIds))]
+				amount := decimal.NewFromInt(random.Int63n(maxTestTransferSize) + 1)
+				_, err := suite.storage.Transfer(model.NewTransfer(from, to, amount))
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if _, ok := err.(*errors.BalanceTooLowError); err != nil && !ok {
+			assert.NoError(suite.T(), err)
+		}
+	}
+	suite.assertTotalBalance(accountIds)
+}
+
+func (suite *ConcurrentTransferSuite) TestShouldNotExceedTheUserLimitUnderConcurrentTransfers() {
+	first, _ := suite.storage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	second, _ := suite.storage.Create(&model.Account{Owner: 1, Name: "Holidays", Type: model.SavingsAccount, Currency: "EUR"})
+	to, _ := suite.storage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.storage.TopUp(first.Id, decimal.NewFromInt(initialTestBalance), decimal.Zero)
+	_, _ = suite.storage.TopUp(second.Id, decimal.NewFromInt(initialTestBalance), decimal.Zero)
+	limits := model.Limits{"EUR": {User: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(initialTestBalance / 2))}}}
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentWorkers*transfersPerWorker)
+
+	for worker := 0; worker < concurrentWorkers; worker++ {
+		from := first.Id
+		if worker%2 == 1 {
+			from = second.Id
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < transfersPerWorker; i++ {
+				_, err := suite.storage.TransferWithinLimits(model.NewTransfer(from, to.Id, decimal.NewFromInt(5)), limits)
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if _, ok := err.(*errors.LimitExceededError); err != nil && !ok {
+			assert.NoError(suite.T(), err)
+		}
+	}
+	received, _ := suite.storage.Get(to.Id)
+	assert.Equal(suite.T(), decimal.NewFromInt(initialTestBalance/2).String(), received.Balance.String())
+}
+
+func (suite *ConcurrentTransferSuite) createAccounts(count int) []model.AccountId {
+	accountIds := make([]model.AccountId, 0, count)
+	for i := 0; i < count; i++ {
+		account, err := suite.storage.Create(&model.Account{Owner: model.UserId(i + 1), Type: model.CheckingAccount, Currency: "EUR"})
+		assert.NoError(suite.T(), err)
+		_, err = suite.storage.TopUp(account.Id, decimal.NewFromInt(initialTestBalance), decimal.Zero)
+		assert.NoError(suite.T(), err)
+		accountIds = append(accountIds, account.Id)
+	}
+	return accountIds
+}
+
+func (suite *ConcurrentTransferSuite) assertTotalBalance(accountIds []model.AccountId) {
+	total := decimal.NewFromInt(0)
+	for _, accountId := range accountIds {
+		account, err := suite.storage.Get(accountId)
+		assert.NoError(suite.T(), err)
+		assert.False(suite.T(), account.Balance.IsNegative())
+		total = total.Add(account.Balance)
+	}
+	assert.Equal(suite.T(), decimal.NewFromInt(int64(len(accountIds)*initialTestBalance)).String(), total.String())
+}
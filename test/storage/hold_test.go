@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+	"time"
+)
+
+// HoldStorageSuite is the contract that every storage backend has to fulfil
+type HoldStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	holdStorage    storage.HoldStorage
+	from           model.AccountId
+	to             model.AccountId
+}
+
+type PostgresHoldStorageSuite struct {
+	HoldStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresHoldStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresHoldStorageSuite))
+}
+
+func (suite *PostgresHoldStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.holdStorage = storage.NewPostgresHoldStorage(suite.Db)
+}
+
+func (suite *PostgresHoldStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+	suite.createAccounts()
+}
+
+func (suite *PostgresHoldStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryHoldStorageSuite struct {
+	HoldStorageSuite
+}
+
+func TestInMemoryHoldStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryHoldStorageSuite))
+}
+
+func (suite *InMemoryHoldStorageSuite) SetupTest() {
+	accounts := storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	suite.accountStorage = accounts
+	suite.holdStorage = accounts
+	suite.createAccounts()
+}
+
+func (suite *HoldStorageSuite) createAccounts() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	suite.from = from.Id
+	suite.to = to.Id
+	_, _ = suite.accountStorage.TopUp(suite.from, decimal.NewFromInt(100), decimal.Zero)
+}
+
+func (suite *HoldStorageSuite) place(amount int64, expiresAt time.Time) *model.Hold {
+	hold, err := suite.holdStorage.PlaceHold(&model.Hold{
+		From:      suite.from,
+		To:        suite.to,
+		Amount:    decimal.NewFromInt(amount),
+		ExpiresAt: expiresAt,
+	})
+	assert.NoError(suite.T(), err)
+	return hold
+}
+
+func (suite *HoldStorageSuite) assertBalances(balance string, available string) {
+	account, err := suite.accountStorage.Get(suite.from)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), balance, account.Balance.String())
+	assert.Equal(suite.T(), available, account.Available().String())
+}
+
+func (suite *HoldStorageSuite) TestShouldPlaceHoldOnAvailableBalance() {
+	hold := suite.place(80, time.Now().Add(time.Hour))
+
+	assert.Equal(suite.T(), model.ActiveHold, hold.Status)
+	assert.Equal(suite.T(), "80", hold.Amount.String())
+	assert.Nil(suite.T(), hold.TransferId)
+	suite.assertBalances("100", "20")
+
+	_, err := suite.holdStorage.PlaceHold(&model.Hold{From: suite.from, To: suite.to, Amount: decimal.NewFromInt(30), ExpiresAt: hold.ExpiresAt})
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: suite.from})
+}
+
+func (suite *HoldStorageSuite) TestShouldHoldTheFeeWithTheAmount() {
+	hold, err := suite.holdStorage.PlaceHold(&model.Hold{
+		From:      suite.from,
+		To:        suite.to,
+		Amount:    decimal.NewFromInt(80),
+		Fee:       decimal.NewFromInt(2),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "2", hold.Fee.String())
+	suite.assertBalances("100", "18")
+
+	_, err = suite.holdStorage.ReleaseHold(hold.Id)
+	assert.NoError(suite.T(), err)
+	suite.assertBalances("100", "100")
+}
+
+func (suite *HoldStorageSuite) TestShouldNotTransferHeldMoney() {
+	suite.place(80, time.Now().Add(time.Hour))
+
+	_, err := suite.accountStorage.Transfer(model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(30)))
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: suite.from})
+
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(20)))
+	assert.NoError(suite.T(), err)
+	suite.assertBalances("80", "0")
+}
+
+func (suite *HoldStorageSuite) TestShouldCapturePartOfHoldAndReleaseTheRest() {
+	hold := suite.place(80, time.Now().Add(time.Hour))
+
+	transfer, err := suite.holdStorage.CaptureHold(hold.Id, model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(50)), model.Limits{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "50", transfer.Amount.String())
+	assert.Equal(suite.T(), "50", transfer.Balance.String())
+	suite.assertBalances("50", "50")
+	captured, _ := suite.holdStorage.GetHold(hold.Id)
+	assert.Equal(suite.T(), model.CapturedHold, captured.Status)
+	assert.Equal(suite.T(), transfer.Id, *captured.TransferId)
+
+	_, err = suite.holdStorage.CaptureHold(hold.Id, model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(10)), model.Limits{})
+	assert.ErrorIs(suite.T(), err, &errors.HoldNotActiveError{HoldId: hold.Id, Status: model.CapturedHold})
+}
+
+func (suite *HoldStorageSuite) TestShouldNotCaptureMoreThanHeld() {
+	hold := suite.place(50, time.Now().Add(time.Hour))
+
+	_, err := suite.holdStorage.CaptureHold(hold.Id, model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(60)), model.Limits{})
+
+	assert.ErrorIs(suite.T(), err, errors.NewValidationError("amount", "The captured amount cannot be more than the held amount"))
+	suite.assertBalances("100", "50")
+}
+
+func (suite *HoldStorageSuite) TestShouldNotCaptureWhenTheFeeIsNotCovered() {
+	hold := suite.place(100, time.Now().Add(time.Hour))
+	transfer := model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(100))
+	transfer.Fee = decimal.NewFromInt(1)
+
+	_, err := suite.holdStorage.CaptureHold(hold.Id, transfer, model.Limits{})
+
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: suite.from})
+	active, _ := suite.holdStorage.GetHold(hold.Id)
+	assert.Equal(suite.T(), model.ActiveHold, active.Status)
+	suite.assertBalances("100", "0")
+}
+
+func (suite *HoldStorageSuite) TestShouldNotCaptureOverTheDailyLimit() {
+	hold := suite.place(80, time.Now().Add(time.Hour))
+	_, err := suite.accountStorage.Transfer(model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(20)))
+	assert.NoError(suite.T(), err)
+	limits := model.Limits{"EUR": {Account: model.TransferLimits{DailyLimit: decimal.NewNullDecimal(decimal.NewFromInt(60))}}}
+
+	_, err = suite.holdStorage.CaptureHold(hold.Id, model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(50)), limits)
+
+	assert.ErrorIs(suite.T(), err, &errors.LimitExceededError{Scope: model.AccountLimitScope, Id: int64(suite.from), Period: model.DailyPeriod})
+	assert.Equal(suite.T(), "40", err.(*errors.LimitExceededError).Remaining.String())
+	active, _ := suite.holdStorage.GetHold(hold.Id)
+	assert.Equal(suite.T(), model.ActiveHold, active.Status)
+	suite.assertBalances("80", "0")
+}
+
+func (suite *HoldStorageSuite) TestShouldReleaseHoldOnce() {
+	hold := suite.place(80, time.Now().Add(time.Hour))
+
+	released, err := suite.holdStorage.ReleaseHold(hold.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.ReleasedHold, released.Status)
+	suite.assertBalances("100", "100")
+
+	_, err = suite.holdStorage.ReleaseHold(hold.Id)
+	assert.ErrorIs(suite.T(), err, &errors.HoldNotActiveError{HoldId: hold.Id, Status: model.ReleasedHold})
+	suite.assertBalances("100", "100")
+}
+
+func (suite *HoldStorageSuite) TestShouldNotGetHoldThatDoesNotExist() {
+	_, err := suite.holdStorage.GetHold(123)
+
+	assert.ErrorIs(suite.T(), err, &errors.HoldDoesNotExistError{HoldId: 123})
+}
+
+func (suite *HoldStorageSuite) TestShouldExpireDueHolds() {
+	now := time.Now()
+	due := suite.place(30, now.Add(-time.Minute))
+	notDue := suite.place(40, now.Add(time.Hour))
+
+	expired, err := suite.holdStorage.ExpireHolds(now)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, expired)
+
+	hold, _ := suite.holdStorage.GetHold(due.Id)
+	assert.Equal(suite.T(), model.ExpiredHold, hold.Status)
+	hold, _ = suite.holdStorage.GetHold(notDue.Id)
+	assert.Equal(suite.T(), model.ActiveHold, hold.Status)
+	suite.assertBalances("100", "60")
+
+	expired, _ = suite.holdStorage.ExpireHolds(now)
+	assert.Equal(suite.T(), 0, expired)
+}
+
+func (suite *HoldStorageSuite) TestShouldNotCloseAccountWithActiveHold() {
+	hold := suite.place(80, time.Now().Add(time.Hour))
+
+	_, err := suite.accountStorage.Close(suite.to, nil)
+
+	assert.Equal(suite.T(), &errors.ActiveHoldsError{AccountId: suite.to}, err)
+	_, err = suite.holdStorage.ReleaseHold(hold.Id)
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.Close(suite.to, nil)
+	assert.NoError(suite.T(), err)
+}
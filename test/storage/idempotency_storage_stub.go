@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type StubIdempotencyStorage struct {
+	mock.Mock
+}
+
+func (storage *StubIdempotencyStorage) Reserve(userId model.UserId, key string, requestHash string, expiration time.Duration) (*model.IdempotencyRecord, error) {
+	args := storage.Called(userId, key, requestHash, expiration)
+	if record, ok := args.Get(0).(*model.IdempotencyRecord); ok {
+		return record, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubIdempotencyStorage) Complete(userId model.UserId, key string, statusCode int, response []byte) error {
+	args := storage.Called(userId, key, statusCode, response)
+	return args.Error(0)
+}
+
+func (storage *StubIdempotencyStorage) Release(userId model.UserId, key string) error {
+	args := storage.Called(userId, key)
+	return args.Error(0)
+}
+
+func (storage *StubIdempotencyStorage) DeleteExpired(expiration time.Duration) (int, error) {
+	args := storage.Called(expiration)
+	return args.Int(0), args.Error(1)
+}
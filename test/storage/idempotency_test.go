@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+	"time"
+)
+
+// IdempotencyStorageSuite is the contract that every storage backend has to fulfil
+type IdempotencyStorageSuite struct {
+	suite.Suite
+	storage storage.IdempotencyStorage
+}
+
+type PostgresIdempotencyStorageSuite struct {
+	IdempotencyStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresIdempotencyStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresIdempotencyStorageSuite))
+}
+
+func (suite *PostgresIdempotencyStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.storage = storage.NewPostgresIdempotencyStorage(suite.Db)
+}
+
+func (suite *PostgresIdempotencyStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+}
+
+func (suite *PostgresIdempotencyStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryIdempotencyStorageSuite struct {
+	IdempotencyStorageSuite
+}
+
+func TestInMemoryIdempotencyStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryIdempotencyStorageSuite))
+}
+
+func (suite *InMemoryIdempotencyStorageSuite) SetupTest() {
+	suite.storage = storage.NewInMemoryIdempotencyStorage()
+}
+
+func (suite *IdempotencyStorageSuite) TestShouldReserveAndCompleteKey() {
+	record, err := suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+
+	record, err = suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hash", record.RequestHash)
+	assert.Nil(suite.T(), record.StatusCode)
+
+	err = suite.storage.Complete(1, "key", 200, []byte("{}"))
+	assert.NoError(suite.T(), err)
+
+	record, err = suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 200, *record.StatusCode)
+	assert.Equal(suite.T(), []byte("{}"), record.Response)
+}
+
+func (suite *IdempotencyStorageSuite) TestShouldKeepKeysOfDifferentUsersApart() {
+	record, err := suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+
+	record, err = suite.storage.Reserve(model.UserId(2), "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *IdempotencyStorageSuite) TestShouldReserveExpiredKeyAgain() {
+	_, err := suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	err = suite.storage.Complete(1, "key", 200, []byte("{}"))
+	assert.NoError(suite.T(), err)
+	time.Sleep(10 * time.Millisecond)
+
+	record, err := suite.storage.Reserve(1, "key", "another_hash", time.Millisecond)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *IdempotencyStorageSuite) TestShouldReserveReleasedKeyAgain() {
+	_, err := suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+
+	err = suite.storage.Release(1, "key")
+	assert.NoError(suite.T(), err)
+
+	record, err := suite.storage.Reserve(1, "key", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
+
+func (suite *IdempotencyStorageSuite) TestShouldDeleteOnlyExpiredKeys() {
+	_, err := suite.storage.Reserve(1, "old", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	time.Sleep(50 * time.Millisecond)
+	_, err = suite.storage.Reserve(1, "new", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+
+	deleted, err := suite.storage.DeleteExpired(25 * time.Millisecond)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, deleted)
+
+	record, err := suite.storage.Reserve(1, "new", "hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), record)
+	record, err = suite.storage.Reserve(1, "old", "another_hash", time.Hour)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), record)
+}
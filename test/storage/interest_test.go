@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+	"time"
+)
+
+// InterestStorageSuite is the contract that every storage backend has to fulfil
+type InterestStorageSuite struct {
+	suite.Suite
+	accountStorage  storage.AccountStorage
+	ledgerStorage   storage.LedgerStorage
+	interestStorage storage.InterestStorage
+	savings         model.AccountId
+	checking        model.AccountId
+}
+
+type PostgresInterestStorageSuite struct {
+	InterestStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresInterestStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresInterestStorageSuite))
+}
+
+func (suite *PostgresInterestStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.ledgerStorage = storage.NewPostgresLedgerStorage(suite.Db)
+	suite.interestStorage = storage.NewPostgresInterestStorage(suite.Db)
+}
+
+func (suite *PostgresInterestStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+	suite.createAccounts()
+}
+
+func (suite *PostgresInterestStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryInterestStorageSuite struct {
+	InterestStorageSuite
+}
+
+func TestInMemoryInterestStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryInterestStorageSuite))
+}
+
+func (suite *InMemoryInterestStorageSuite) SetupTest() {
+	ledger := storage.NewInMemoryLedgerStorage()
+	accounts := storage.NewInMemoryAccountStorage(ledger)
+	suite.accountStorage = accounts
+	suite.ledgerStorage = ledger
+	suite.interestStorage = accounts
+	suite.createAccounts()
+}
+
+func (suite *InterestStorageSuite) createAccounts() {
+	savings, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.SavingsAccount, Currency: "EUR"})
+	checking, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	suite.savings = savings.Id
+	suite.checking = checking.Id
+	_, _ = suite.accountStorage.TopUp(suite.savings, decimal.NewFromInt(1000), decimal.Zero)
+}
+
+func (suite *InterestStorageSuite) accrue(day time.Time, dayCount model.DayCount) *model.InterestAccrual {
+	accrual, err := suite.interestStorage.AccrueInterest(suite.savings, day, dayCount)
+	assert.NoError(suite.T(), err)
+	return accrual
+}
+
+func (suite *InterestStorageSuite) TestShouldListInterestBearingAccounts() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.savings, decimal.RequireFromString("0.02"))
+
+	accounts, err := suite.interestStorage.ListInterestBearing()
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), accounts, 1)
+	assert.Equal(suite.T(), suite.savings, accounts[0].Id)
+	assert.Equal(suite.T(), "0.02", accounts[0].InterestRate.String())
+	assert.True(suite.T(), model.Today(time.Now()).Equal(*accounts[0].InterestSince))
+}
+
+func (suite *InterestStorageSuite) TestShouldAccrueDayOnlyOnce() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.savings, decimal.RequireFromString("0.0365"))
+	today := model.Today(time.Now())
+
+	accrual := suite.accrue(today, model.Actual365)
+	assert.Equal(suite.T(), "0.1", accrual.Amount.String())
+	assert.Equal(suite.T(), "0.1", accrual.Accrued.String())
+	assert.Equal(suite.T(), "0", accrual.Capitalized.String())
+
+	_, _ = suite.accountStorage.TopUp(suite.savings, decimal.NewFromInt(1000), decimal.Zero)
+	accrual = suite.accrue(today, model.Actual365)
+	assert.Equal(suite.T(), "1000", accrual.Balance.String())
+	assert.Equal(suite.T(), "0.1", accrual.Amount.String())
+
+	account, _ := suite.accountStorage.Get(suite.savings)
+	assert.Equal(suite.T(), "0.1", account.AccruedInterest.String())
+	assert.Equal(suite.T(), "2000", account.Balance.String())
+}
+
+func (suite *InterestStorageSuite) TestShouldAccrueTheBalanceAtTheEndOfTheDay() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.savings, decimal.RequireFromString("0.0365"))
+	today := model.Today(time.Now())
+	last, err := suite.interestStorage.LastAccruedDay(suite.savings)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), last)
+
+	accrual := suite.accrue(today.AddDate(0, 0, -1), model.Actual365)
+	assert.Equal(suite.T(), "0", accrual.Balance.String())
+	assert.Equal(suite.T(), "0", accrual.Amount.String())
+
+	accrual = suite.accrue(today, model.Actual365)
+	assert.Equal(suite.T(), "1000", accrual.Balance.String())
+	assert.Equal(suite.T(), "0.1", accrual.Amount.String())
+	last, err = suite.interestStorage.LastAccruedDay(suite.savings)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), today.Equal(*last))
+}
+
+func (suite *InterestStorageSuite) TestShouldCapitalizeWholeCentsAtTheEndOfMonth() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.savings, decimal.RequireFromString("0.01"))
+	today := model.Today(time.Now())
+	endOfNextMonth := time.Date(today.Year(), today.Month()+2, 0, 0, 0, 0, 0, time.UTC)
+
+	suite.accrue(endOfNextMonth.AddDate(0, 0, -1), model.Actual360)
+	accrual := suite.accrue(endOfNextMonth, model.Actual360)
+
+	assert.Equal(suite.T(), "0.02777777777777777778", accrual.Amount.String())
+	assert.Equal(suite.T(), "0.05", accrual.Capitalized.String())
+	assert.Equal(suite.T(), "0.00555555555555555556", accrual.Accrued.String())
+	assert.NotNil(suite.T(), accrual.JournalId)
+	account, _ := suite.accountStorage.Get(suite.savings)
+	assert.Equal(suite.T(), "1000.05", account.Balance.String())
+	assert.Equal(suite.T(), "0.00555555555555555556", account.AccruedInterest.String())
+
+	entries, err := suite.ledgerStorage.List(suite.savings, &model.LedgerFilter{Types: []model.LedgerEntryType{model.InterestEntry}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 1)
+	assert.Equal(suite.T(), "0.05", entries[0].Amount.String())
+	assert.Equal(suite.T(), "1000.05", entries[0].Balance.String())
+	assert.Equal(suite.T(), *accrual.JournalId, *entries[0].JournalId)
+}
+
+func (suite *InterestStorageSuite) TestShouldNotEarnInterestWithoutPositiveBalance() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.checking, decimal.RequireFromString("0.05"))
+
+	accrual, err := suite.interestStorage.AccrueInterest(suite.checking, time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC), model.Actual365)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "0", accrual.Amount.String())
+	assert.Equal(suite.T(), "0", accrual.Capitalized.String())
+	assert.Nil(suite.T(), accrual.JournalId)
+}
+
+func (suite *InterestStorageSuite) TestShouldNotAccrueOnClosedAccount() {
+	_, _ = suite.accountStorage.SetInterestRate(suite.checking, decimal.RequireFromString("0.05"))
+	_, _ = suite.accountStorage.Close(suite.checking, nil)
+
+	_, err := suite.interestStorage.AccrueInterest(suite.checking, time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC), model.Actual365)
+
+	assert.ErrorIs(suite.T(), err, &errors.AccountClosedError{AccountId: suite.checking})
+}
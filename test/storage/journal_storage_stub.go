@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+)
+
+type StubJournalStorage struct {
+	mock.Mock
+}
+
+func (storage *StubJournalStorage) Post(journal *model.Journal) error {
+	args := storage.Called(journal)
+	return args.Error(0)
+}
+
+func (storage *StubJournalStorage) TrialBalance() (*model.TrialBalance, error) {
+	args := storage.Called()
+	if trialBalance, ok := args.Get(0).(*model.TrialBalance); ok {
+		return trialBalance, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+)
+
+// JournalStorageSuite is the contract that every storage backend has to fulfil
+type JournalStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	journalStorage storage.JournalStorage
+}
+
+type PostgresJournalStorageSuite struct {
+	JournalStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresJournalStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresJournalStorageSuite))
+}
+
+func (suite *PostgresJournalStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.journalStorage = storage.NewPostgresJournalStorage(suite.Db)
+}
+
+func (suite *PostgresJournalStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+}
+
+func (suite *PostgresJournalStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryJournalStorageSuite struct {
+	JournalStorageSuite
+}
+
+func TestInMemoryJournalStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryJournalStorageSuite))
+}
+
+func (suite *InMemoryJournalStorageSuite) SetupTest() {
+	accountStorage := storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	suite.accountStorage = accountStorage
+	suite.journalStorage = accountStorage
+}
+
+func (suite *JournalStorageSuite) TestShouldKeepTrialBalanceAfterTopUpAndTransfer() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+
+	trialBalance, err := suite.journalStorage.TrialBalance()
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), trialBalance.IsBalanced())
+	assert.Len(suite.T(), trialBalance.Totals, 1)
+	assert.Equal(suite.T(), model.Currency("EUR"), trialBalance.Totals[0].Currency)
+	assert.Equal(suite.T(), "130", trialBalance.Totals[0].Debits.String())
+	assert.Equal(suite.T(), "130", trialBalance.Totals[0].Credits.String())
+	assert.Empty(suite.T(), trialBalance.Mismatches)
+}
+
+func (suite *JournalStorageSuite) TestShouldPostFeesToTheFeesAccount() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.NewFromInt(1))
+	assert.NoError(suite.T(), err)
+	transfer := model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30))
+	transfer.Fee = decimal.NewFromInt(2)
+	_, err = suite.accountStorage.Transfer(transfer)
+	assert.NoError(suite.T(), err)
+
+	trialBalance, err := suite.journalStorage.TrialBalance()
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), trialBalance.IsBalanced())
+	assert.Equal(suite.T(), "133", trialBalance.Totals[0].Credits.String())
+	assert.Empty(suite.T(), trialBalance.Mismatches)
+	systemAccounts, _ := suite.accountStorage.ListByOwner(model.SystemUser)
+	for _, account := range systemAccounts {
+		if account.Name == model.SystemAccountName(model.FeesAccount, "EUR") {
+			assert.Equal(suite.T(), "3", account.Balance.String())
+		}
+	}
+}
+
+func (suite *JournalStorageSuite) TestShouldBalanceConversionsThroughFxAccounts() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "USD"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.accountStorage.Transfer(&model.Transfer{
+		From:         account1.Id,
+		To:           account2.Id,
+		Amount:       decimal.NewFromInt(10),
+		CreditAmount: decimal.RequireFromString("11.3"),
+		FxRate:       decimal.NullDecimal{Decimal: decimal.RequireFromString("1.13"), Valid: true},
+	})
+	assert.NoError(suite.T(), err)
+
+	trialBalance, err := suite.journalStorage.TrialBalance()
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), trialBalance.IsBalanced())
+	assert.Len(suite.T(), trialBalance.Totals, 2)
+	assert.Equal(suite.T(), model.Currency("USD"), trialBalance.Totals[1].Currency)
+	assert.Equal(suite.T(), "11.3", trialBalance.Totals[1].Credits.String())
+}
+
+func (suite *JournalStorageSuite) TestShouldBalancePartialReversalOfConversion() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "USD"})
+	account3, _ := suite.accountStorage.Create(&model.Account{Owner: 3, Type: model.CheckingAccount, Currency: "USD"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	transfer, err := suite.accountStorage.Transfer(&model.Transfer{
+		From:         account1.Id,
+		To:           account2.Id,
+		Amount:       decimal.NewFromInt(10),
+		CreditAmount: decimal.RequireFromString("11.3"),
+		FxRate:       decimal.NullDecimal{Decimal: decimal.RequireFromString("1.13"), Valid: true},
+	})
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account2.Id, account3.Id, decimal.RequireFromString("5.65")))
+	assert.NoError(suite.T(), err)
+
+	reversal, err := suite.accountStorage.Reverse(transfer.Id, true)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "5.65", reversal.Amount.String())
+	assert.Equal(suite.T(), "5", reversal.CreditAmount.String())
+	foundAccount1, _ := suite.accountStorage.Get(account1.Id)
+	assert.Equal(suite.T(), "95", foundAccount1.Balance.String())
+	trialBalance, err := suite.journalStorage.TrialBalance()
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), trialBalance.IsBalanced())
+}
+
+func (suite *JournalStorageSuite) TestShouldPostBalancedJournal() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(50), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	journal := &model.Journal{Type: model.TransferJournal, Postings: []model.Posting{
+		{AccountId: account1.Id, Currency: "EUR", Amount: decimal.NewFromInt(-20)},
+		{AccountId: account2.Id, Currency: "EUR", Amount: decimal.NewFromInt(20)},
+	}}
+
+	err = suite.journalStorage.Post(journal)
+
+	assert.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), journal.Id)
+	assert.Equal(suite.T(), journal.Id, journal.Postings[0].JournalId)
+	foundAccount2, _ := suite.accountStorage.Get(account2.Id)
+	assert.Equal(suite.T(), "20", foundAccount2.Balance.String())
+	trialBalance, err := suite.journalStorage.TrialBalance()
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), trialBalance.IsBalanced())
+}
+
+func (suite *JournalStorageSuite) TestShouldRefuseUnbalancedJournal() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(50), decimal.Zero)
+	assert.NoError(suite.T(), err)
+
+	err = suite.journalStorage.Post(&model.Journal{Type: model.TransferJournal, Postings: []model.Posting{
+		{AccountId: account1.Id, Currency: "EUR", Amount: decimal.NewFromInt(-20)},
+		{AccountId: account2.Id, Currency: "EUR", Amount: decimal.NewFromInt(25)},
+	}})
+
+	assert.Equal(suite.T(), &errors.UnbalancedJournalError{Type: model.TransferJournal}, err)
+	foundAccount1, _ := suite.accountStorage.Get(account1.Id)
+	assert.Equal(suite.T(), "50", foundAccount1.Balance.String())
+}
+
+func (suite *JournalStorageSuite) TestShouldNotPostJournalOverdrawingCustomerAccount() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+
+	err := suite.journalStorage.Post(&model.Journal{Type: model.TransferJournal, Postings: []model.Posting{
+		{AccountId: account1.Id, Currency: "EUR", Amount: decimal.NewFromInt(-20)},
+		{AccountId: account2.Id, Currency: "EUR", Amount: decimal.NewFromInt(20)},
+	}})
+
+	assert.ErrorIs(suite.T(), err, &errors.BalanceTooLowError{AccountId: account1.Id})
+	assert.EqualError(suite.T(), err, fmt.Sprintf("The account %d does not have enough money, 20 more is needed", account1.Id))
+	foundAccount2, _ := suite.accountStorage.Get(account2.Id)
+	assert.Equal(suite.T(), "0", foundAccount2.Balance.String())
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+)
+
+type StubLedgerStorage struct {
+	mock.Mock
+}
+
+func (storage *StubLedgerStorage) List(accountId model.AccountId, filter *model.LedgerFilter) ([]model.LedgerEntry, error) {
+	args := storage.Called(accountId, filter)
+	if entries, ok := args.Get(0).([]model.LedgerEntry); ok {
+		return entries, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubLedgerStorage) GetTopUp(topUpId model.LedgerEntryId) (*model.LedgerEntry, error) {
+	args := storage.Called(topUpId)
+	if entry, ok := args.Get(0).(*model.LedgerEntry); ok {
+		return entry, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubLedgerStorage) Statement(statement *model.Statement, writer model.StatementWriter) error {
+	args := storage.Called(statement, writer)
+	return args.Error(0)
+}
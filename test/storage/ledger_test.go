@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+	"time"
+)
+
+// LedgerStorageSuite is the contract that every storage backend has to fulfil
+type LedgerStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	ledgerStorage  storage.LedgerStorage
+}
+
+type PostgresLedgerStorageSuite struct {
+	LedgerStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresLedgerStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresLedgerStorageSuite))
+}
+
+func (suite *PostgresLedgerStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.ledgerStorage = storage.NewPostgresLedgerStorage(suite.Db)
+}
+
+func (suite *PostgresLedgerStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+}
+
+func (suite *PostgresLedgerStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryLedgerStorageSuite struct {
+	LedgerStorageSuite
+}
+
+func TestInMemoryLedgerStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryLedgerStorageSuite))
+}
+
+func (suite *InMemoryLedgerStorageSuite) SetupTest() {
+	ledgerStorage := storage.NewInMemoryLedgerStorage()
+	suite.accountStorage = storage.NewInMemoryAccountStorage(ledgerStorage)
+	suite.ledgerStorage = ledgerStorage
+}
+
+func (suite *LedgerStorageSuite) TestShouldRecordTopUps() {
+	account, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.accountStorage.TopUp(account.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.TopUp(account.Id, decimal.NewFromInt(50), decimal.Zero)
+	assert.NoError(suite.T(), err)
+
+	entries, err := suite.ledgerStorage.List(account.Id, &model.LedgerFilter{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 2)
+	assert.Equal(suite.T(), model.TopUpEntry, entries[0].Type)
+	assert.True(suite.T(), entries[0].Amount.Equal(decimal.NewFromInt(50)))
+	assert.True(suite.T(), entries[0].Balance.Equal(decimal.NewFromInt(150)))
+	assert.Nil(suite.T(), entries[0].Counterparty)
+	assert.Equal(suite.T(), model.TopUpEntry, entries[1].Type)
+	assert.True(suite.T(), entries[1].Amount.Equal(decimal.NewFromInt(100)))
+	assert.True(suite.T(), entries[1].Balance.Equal(decimal.NewFromInt(100)))
+}
+
+func (suite *LedgerStorageSuite) TestShouldGetTopUpByIdOfItsReceipt() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	topUp, err := suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+
+	found, err := suite.ledgerStorage.GetTopUp(topUp.Id)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), account1.Id, found.AccountId)
+	assert.Equal(suite.T(), "100", found.Balance.String())
+	_, err = suite.ledgerStorage.GetTopUp(topUp.Id + 1)
+	assert.Equal(suite.T(), &errors.TopUpDoesNotExistError{TopUpId: topUp.Id + 1}, err)
+	_, err = suite.ledgerStorage.GetTopUp(topUp.Id + 1000)
+	assert.Equal(suite.T(), &errors.TopUpDoesNotExistError{TopUpId: topUp.Id + 1000}, err)
+}
+
+func (suite *LedgerStorageSuite) TestShouldRecordBothSidesOfTransfer() {
+	account1, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	account2, err := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+
+	entries1, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries1, 2)
+	assert.Equal(suite.T(), model.TransferOutEntry, entries1[0].Type)
+	assert.True(suite.T(), entries1[0].Amount.Equal(decimal.NewFromInt(-30)))
+	assert.True(suite.T(), entries1[0].Balance.Equal(decimal.NewFromInt(70)))
+	assert.Equal(suite.T(), &account2.Id, entries1[0].Counterparty)
+
+	entries2, err := suite.ledgerStorage.List(account2.Id, &model.LedgerFilter{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries2, 1)
+	assert.Equal(suite.T(), model.TransferInEntry, entries2[0].Type)
+	assert.True(suite.T(), entries2[0].Amount.Equal(decimal.NewFromInt(30)))
+	assert.True(suite.T(), entries2[0].Balance.Equal(decimal.NewFromInt(30)))
+	assert.Equal(suite.T(), &account1.Id, entries2[0].Counterparty)
+}
+
+func (suite *LedgerStorageSuite) TestShouldNotRecordFailedTransfer() {
+	account1, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, 123, decimal.NewFromInt(30)))
+	assert.Error(suite.T(), err)
+
+	entries, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 1)
+	assert.Equal(suite.T(), model.TopUpEntry, entries[0].Type)
+}
+
+func (suite *LedgerStorageSuite) TestShouldPageThroughEntries() {
+	account, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	for i := 1; i <= 3; i++ {
+		_, err = suite.accountStorage.TopUp(account.Id, decimal.NewFromInt(int64(i)), decimal.Zero)
+		assert.NoError(suite.T(), err)
+	}
+
+	firstPage, err := suite.ledgerStorage.List(account.Id, &model.LedgerFilter{Limit: 2})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), firstPage, 2)
+	assert.True(suite.T(), firstPage[0].Amount.Equal(decimal.NewFromInt(3)))
+	assert.True(suite.T(), firstPage[1].Amount.Equal(decimal.NewFromInt(2)))
+
+	secondPage, err := suite.ledgerStorage.List(account.Id, &model.LedgerFilter{Before: &firstPage[1].Id, Limit: 2})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), secondPage, 1)
+	assert.True(suite.T(), secondPage[0].Amount.Equal(decimal.NewFromInt(1)))
+}
+
+func (suite *LedgerStorageSuite) TestShouldFilterEntriesByTypeAndDate() {
+	account1, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	account2, err := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+
+	transfers, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{Types: []model.LedgerEntryType{model.TransferOutEntry}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), transfers, 1)
+	assert.Equal(suite.T(), model.TransferOutEntry, transfers[0].Type)
+
+	future := time.Now().Add(time.Hour)
+	futureEntries, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{From: &future})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), futureEntries)
+
+	pastEntries, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{To: &future})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), pastEntries, 2)
+}
+
+func (suite *LedgerStorageSuite) TestShouldRecordRateAndBothAmountsOfConversion() {
+	account1, err := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	assert.NoError(suite.T(), err)
+	account2, err := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "USD"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	assert.NoError(suite.T(), err)
+	rate := decimal.RequireFromString("1.13")
+
+	_, err = suite.accountStorage.Transfer(&model.Transfer{
+		From:         account1.Id,
+		To:           account2.Id,
+		Amount:       decimal.NewFromInt(10),
+		CreditAmount: decimal.RequireFromString("11.3"),
+		FxRate:       decimal.NullDecimal{Decimal: rate, Valid: true},
+	})
+	assert.NoError(suite.T(), err)
+
+	entries1, err := suite.ledgerStorage.List(account1.Id, &model.LedgerFilter{Types: []model.LedgerEntryType{model.TransferOutEntry}})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), entries1[0].Amount.Equal(decimal.NewFromInt(-10)))
+	assert.True(suite.T(), entries1[0].FxRate.Decimal.Equal(rate))
+
+	entries2, err := suite.ledgerStorage.List(account2.Id, &model.LedgerFilter{})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), entries2[0].Amount.Equal(decimal.RequireFromString("11.3")))
+	assert.True(suite.T(), entries2[0].Balance.Equal(decimal.RequireFromString("11.3")))
+	assert.True(suite.T(), entries2[0].FxRate.Decimal.Equal(rate))
+}
+
+// recordingStatementWriter keeps what it gets, to check what the storage passes to the writers
+type recordingStatementWriter struct {
+	statement *model.Statement
+	entries   []model.LedgerEntry
+}
+
+func (writer *recordingStatementWriter) Begin(statement *model.Statement) error {
+	writer.statement = statement
+	return nil
+}
+
+func (writer *recordingStatementWriter) Entry(entry *model.LedgerEntry) error {
+	writer.entries = append(writer.entries, *entry)
+	return nil
+}
+
+func (writer *recordingStatementWriter) End() error {
+	return nil
+}
+
+func (suite *LedgerStorageSuite) TestShouldWriteStatementFromBalanceBeforePeriod() {
+	account1, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	account2, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(100), decimal.Zero)
+	_, _ = suite.accountStorage.TopUp(account2.Id, decimal.NewFromInt(70), decimal.Zero)
+	time.Sleep(10 * time.Millisecond)
+	from := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	_, _ = suite.accountStorage.TopUp(account1.Id, decimal.NewFromInt(50), decimal.Zero)
+	_, _ = suite.accountStorage.Transfer(model.NewTransfer(account1.Id, account2.Id, decimal.NewFromInt(30)))
+	writer := &recordingStatementWriter{}
+
+	err := suite.ledgerStorage.Statement(&model.Statement{Account: account1, From: from, To: from.Add(time.Hour)}, writer)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "100", writer.statement.OpeningBalance.String())
+	assert.Len(suite.T(), writer.entries, 2)
+	assert.Equal(suite.T(), model.TopUpEntry, writer.entries[0].Type)
+	assert.Equal(suite.T(), "150", writer.entries[0].Balance.String())
+	assert.Equal(suite.T(), model.TransferOutEntry, writer.entries[1].Type)
+	assert.Equal(suite.T(), "120", writer.statement.ClosingBalance.String())
+}
+
+func (suite *LedgerStorageSuite) TestShouldWriteStatementOfPeriodWithoutEntries() {
+	account, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.accountStorage.TopUp(account.Id, decimal.NewFromInt(100), decimal.Zero)
+	past := &recordingStatementWriter{}
+	future := &recordingStatementWriter{}
+
+	err := suite.ledgerStorage.Statement(&model.Statement{Account: account, From: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2001, 2, 1, 0, 0, 0, 0, time.UTC)}, past)
+	assert.NoError(suite.T(), err)
+	err = suite.ledgerStorage.Statement(&model.Statement{Account: account, From: time.Now().Add(time.Hour), To: time.Now().Add(2 * time.Hour)}, future)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "0", past.statement.OpeningBalance.String())
+	assert.Empty(suite.T(), past.entries)
+	assert.Equal(suite.T(), "0", past.statement.ClosingBalance.String())
+	assert.Equal(suite.T(), "100", future.statement.OpeningBalance.String())
+	assert.Empty(suite.T(), future.entries)
+	assert.Equal(suite.T(), "100", future.statement.ClosingBalance.String())
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+)
+
+// OutboxStorageSuite is the contract that every storage backend has to fulfil
+type OutboxStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	outboxStorage  storage.OutboxStorage
+}
+
+type PostgresOutboxStorageSuite struct {
+	OutboxStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresOutboxStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresOutboxStorageSuite))
+}
+
+func (suite *PostgresOutboxStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.outboxStorage = storage.NewPostgresOutboxStorage(suite.Db)
+}
+
+func (suite *PostgresOutboxStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+}
+
+func (suite *PostgresOutboxStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryOutboxStorageSuite struct {
+	OutboxStorageSuite
+}
+
+func TestInMemoryOutboxStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryOutboxStorageSuite))
+}
+
+func (suite *InMemoryOutboxStorageSuite) SetupTest() {
+	accounts := storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	suite.accountStorage = accounts
+	suite.outboxStorage = accounts
+}
+
+func (suite *OutboxStorageSuite) publishAll() []model.Event {
+	var events []model.Event
+	_, err := suite.outboxStorage.Publish(100, func(event *model.Event) error {
+		events = append(events, *event)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	return events
+}
+
+func (suite *OutboxStorageSuite) TestShouldWriteEventsOfChanges() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	entry, _ := suite.accountStorage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	transfer, err := suite.accountStorage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(30)))
+	assert.NoError(suite.T(), err)
+
+	events := suite.publishAll()
+
+	assert.Len(suite.T(), events, 4)
+	assert.Equal(suite.T(), []model.EventType{model.AccountCreatedEvent, model.AccountCreatedEvent, model.AccountToppedUpEvent, model.MoneyTransferredEvent},
+		[]model.EventType{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+	assert.Equal(suite.T(), []model.AccountId{from.Id, to.Id, from.Id, from.Id},
+		[]model.AccountId{events[0].AccountId, events[1].AccountId, events[2].AccountId, events[3].AccountId})
+	assert.Equal(suite.T(), 1, events[3].Version)
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"account_id":%d,"owner":1,"type":"checking","currency":"EUR"}`, from.Id), string(events[0].Data))
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"account_id":%d,"ledger_entry_id":%d,"amount":"100","fee":"0","currency":"EUR","balance":"100"}`,
+		from.Id, entry.Id), string(events[2].Data))
+	assert.JSONEq(suite.T(), fmt.Sprintf(`{"transfer_id":%d,"from":%d,"to":%d,"amount":"30","currency":"EUR","credit_amount":"30","credit_currency":"EUR","fee":"0"}`,
+		transfer.Id, from.Id, to.Id), string(events[3].Data))
+}
+
+func (suite *OutboxStorageSuite) TestShouldPublishEventOnlyOnce() {
+	_, _ = suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+
+	assert.Len(suite.T(), suite.publishAll(), 1)
+	assert.Len(suite.T(), suite.publishAll(), 0)
+}
+
+func (suite *OutboxStorageSuite) TestShouldHoldBackEventsAfterFailedOne() {
+	for owner := 1; owner <= 3; owner++ {
+		_, _ = suite.accountStorage.Create(&model.Account{Owner: model.UserId(owner), Type: model.CheckingAccount, Currency: "EUR"})
+	}
+	failure := fmt.Errorf("broker down")
+	var attempted []model.EventId
+
+	published, err := suite.outboxStorage.Publish(100, func(event *model.Event) error {
+		attempted = append(attempted, event.Id)
+		if len(attempted) == 2 {
+			return failure
+		}
+		return nil
+	})
+
+	assert.Equal(suite.T(), failure, err)
+	assert.Equal(suite.T(), 1, published)
+	assert.Len(suite.T(), attempted, 2)
+	events := suite.publishAll()
+	assert.Len(suite.T(), events, 2)
+	assert.Equal(suite.T(), attempted[1], events[0].Id)
+}
+
+func (suite *OutboxStorageSuite) TestShouldPublishUpToLimit() {
+	for owner := 1; owner <= 3; owner++ {
+		_, _ = suite.accountStorage.Create(&model.Account{Owner: model.UserId(owner), Type: model.CheckingAccount, Currency: "EUR"})
+	}
+
+	published, err := suite.outboxStorage.Publish(2, func(event *model.Event) error { return nil })
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, published)
+	assert.Len(suite.T(), suite.publishAll(), 1)
+}
+
+func (suite *OutboxStorageSuite) TestShouldWriteNoEventsOfFailedChanges() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.accountStorage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	suite.publishAll()
+
+	_, err := suite.accountStorage.Transfer(model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(200)))
+	assert.IsType(suite.T(), &errors.BalanceTooLowError{}, err)
+	_, err = suite.accountStorage.TransferAll([]*model.Transfer{
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)),
+		model.NewTransfer(from.Id, to.Id, decimal.NewFromInt(60)),
+	}, model.Limits{})
+	assert.Error(suite.T(), err)
+
+	assert.Len(suite.T(), suite.publishAll(), 0)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/mock"
+	"golang_bank_demo/src/model"
+	"time"
+)
+
+type StubScheduledTransferStorage struct {
+	mock.Mock
+}
+
+func (storage *StubScheduledTransferStorage) Create(transfer *model.ScheduledTransfer) (*model.ScheduledTransfer, error) {
+	args := storage.Called(transfer)
+	if created, ok := args.Get(0).(*model.ScheduledTransfer); ok {
+		return created, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubScheduledTransferStorage) Get(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	args := storage.Called(id)
+	if transfer, ok := args.Get(0).(*model.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubScheduledTransferStorage) ListByOwner(owner model.UserId) ([]model.ScheduledTransfer, error) {
+	args := storage.Called(owner)
+	if transfers, ok := args.Get(0).([]model.ScheduledTransfer); ok {
+		return transfers, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubScheduledTransferStorage) Cancel(id model.ScheduledTransferId) (*model.ScheduledTransfer, error) {
+	args := storage.Called(id)
+	if transfer, ok := args.Get(0).(*model.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	} else {
+		return nil, args.Error(1)
+	}
+}
+
+func (storage *StubScheduledTransferStorage) ExecuteDue(now time.Time, execute func(transfer *model.ScheduledTransfer) error) (bool, error) {
+	args := storage.Called(now, execute)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ScheduledTransferStorageSuite is the contract that every storage backend has to fulfil
+type ScheduledTransferStorageSuite struct {
+	suite.Suite
+	accountStorage   storage.AccountStorage
+	scheduledStorage storage.ScheduledTransferStorage
+	from             model.AccountId
+	to               model.AccountId
+	now              time.Time
+}
+
+type PostgresScheduledTransferStorageSuite struct {
+	ScheduledTransferStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresScheduledTransferStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresScheduledTransferStorageSuite))
+}
+
+func (suite *PostgresScheduledTransferStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.scheduledStorage = storage.NewPostgresScheduledTransferStorage(suite.Db)
+}
+
+func (suite *PostgresScheduledTransferStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+	suite.createAccounts()
+}
+
+func (suite *PostgresScheduledTransferStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryScheduledTransferStorageSuite struct {
+	ScheduledTransferStorageSuite
+}
+
+func TestInMemoryScheduledTransferStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryScheduledTransferStorageSuite))
+}
+
+func (suite *InMemoryScheduledTransferStorageSuite) SetupTest() {
+	accounts := storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	scheduled := storage.NewInMemoryScheduledTransferStorage()
+	accounts.SetSchedules(scheduled, storage.NewInMemoryStandingOrderStorage())
+	suite.accountStorage = accounts
+	suite.scheduledStorage = scheduled
+	suite.createAccounts()
+}
+
+func (suite *ScheduledTransferStorageSuite) createAccounts() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	_, _ = suite.accountStorage.TopUp(from.Id, decimal.NewFromInt(100), decimal.Zero)
+	suite.from = from.Id
+	suite.to = to.Id
+	suite.now = time.Now().Truncate(time.Second)
+}
+
+func (suite *ScheduledTransferStorageSuite) schedule(executeAt time.Time) *model.ScheduledTransfer {
+	scheduled, err := suite.scheduledStorage.Create(&model.ScheduledTransfer{
+		Owner: 1, From: suite.from, To: suite.to, Amount: decimal.NewFromInt(10), ExecuteAt: executeAt,
+	})
+	assert.NoError(suite.T(), err)
+	return scheduled
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldCreateAndListScheduledTransfers() {
+	later := suite.schedule(suite.now.Add(2 * time.Hour))
+	sooner := suite.schedule(suite.now.Add(time.Hour))
+
+	assert.Equal(suite.T(), model.PendingTransfer, later.Status)
+	assert.Equal(suite.T(), "10", later.Amount.String())
+	assert.True(suite.T(), later.ExecuteAt.Equal(suite.now.Add(2*time.Hour)))
+
+	transfers, err := suite.scheduledStorage.ListByOwner(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(transfers))
+	assert.Equal(suite.T(), sooner.Id, transfers[0].Id)
+	assert.Equal(suite.T(), later.Id, transfers[1].Id)
+
+	transfers, err = suite.scheduledStorage.ListByOwner(2)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), transfers)
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldCancelScheduledTransfer() {
+	scheduled := suite.schedule(suite.now.Add(time.Hour))
+
+	cancelled, err := suite.scheduledStorage.Cancel(scheduled.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CancelledTransfer, cancelled.Status)
+
+	_, err = suite.scheduledStorage.Cancel(scheduled.Id)
+	assert.ErrorIs(suite.T(), err, &errors.ScheduledTransferNotPendingError{ScheduledTransferId: scheduled.Id, Status: model.CancelledTransfer})
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldNotCancelScheduledTransferThatDoesNotExist() {
+	_, err := suite.scheduledStorage.Cancel(123)
+
+	assert.ErrorIs(suite.T(), err, &errors.ScheduledTransferDoesNotExistError{ScheduledTransferId: 123})
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldExecuteDueTransfersInOrder() {
+	second := suite.schedule(suite.now.Add(-time.Minute))
+	first := suite.schedule(suite.now.Add(-time.Hour))
+	notDue := suite.schedule(suite.now.Add(time.Minute))
+
+	executed := make([]model.ScheduledTransferId, 0)
+	execute := func(scheduled *model.ScheduledTransfer) error {
+		executed = append(executed, scheduled.Id)
+		transfer, err := suite.accountStorage.Transfer(model.NewTransfer(scheduled.From, scheduled.To, scheduled.Amount))
+		if err != nil {
+			return err
+		}
+		scheduled.Status = model.ExecutedTransfer
+		scheduled.TransferId = &transfer.Id
+		return nil
+	}
+	for found := true; found; {
+		var err error
+		found, err = suite.scheduledStorage.ExecuteDue(suite.now, execute)
+		assert.NoError(suite.T(), err)
+	}
+
+	assert.Equal(suite.T(), []model.ScheduledTransferId{first.Id, second.Id}, executed)
+	found, _ := suite.scheduledStorage.Get(first.Id)
+	assert.Equal(suite.T(), model.ExecutedTransfer, found.Status)
+	assert.NotNil(suite.T(), found.TransferId)
+	found, _ = suite.scheduledStorage.Get(notDue.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, found.Status)
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldRecordFailureOfScheduledTransfer() {
+	scheduled := suite.schedule(suite.now)
+
+	found, err := suite.scheduledStorage.ExecuteDue(suite.now, func(transfer *model.ScheduledTransfer) error {
+		errorType, errorMessage := "BalanceTooLowError", "The account does not have enough money"
+		transfer.Status = model.FailedTransfer
+		transfer.ErrorType = &errorType
+		transfer.ErrorMessage = &errorMessage
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), found)
+
+	failed, _ := suite.scheduledStorage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.FailedTransfer, failed.Status)
+	assert.Equal(suite.T(), "BalanceTooLowError", *failed.ErrorType)
+	_, err = suite.scheduledStorage.Cancel(scheduled.Id)
+	assert.ErrorIs(suite.T(), err, &errors.ScheduledTransferNotPendingError{ScheduledTransferId: scheduled.Id, Status: model.FailedTransfer})
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldRecordAttemptAndPostponeScheduledTransfer() {
+	first := suite.schedule(suite.now.Add(-time.Minute))
+	second := suite.schedule(suite.now)
+
+	found, err := suite.scheduledStorage.ExecuteDue(suite.now, func(transfer *model.ScheduledTransfer) error {
+		transfer.Attempts++
+		transfer.ExecuteAt = suite.now.Add(time.Minute)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), found)
+
+	postponed, _ := suite.scheduledStorage.Get(first.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, postponed.Status)
+	assert.Equal(suite.T(), 1, postponed.Attempts)
+	assert.True(suite.T(), suite.now.Add(time.Minute).Equal(postponed.ExecuteAt))
+	_, err = suite.scheduledStorage.ExecuteDue(suite.now, func(transfer *model.ScheduledTransfer) error {
+		assert.Equal(suite.T(), second.Id, transfer.Id)
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldKeepTransferPendingWhenExecutionFails() {
+	scheduled := suite.schedule(suite.now)
+
+	found, err := suite.scheduledStorage.ExecuteDue(suite.now, func(transfer *model.ScheduledTransfer) error {
+		transfer.Status = model.ExecutedTransfer
+		return &errors.InternalServerError{Err: fmt.Errorf("connection refused")}
+	})
+	assert.Error(suite.T(), err)
+	assert.True(suite.T(), found)
+
+	pending, _ := suite.scheduledStorage.Get(scheduled.Id)
+	assert.Equal(suite.T(), model.PendingTransfer, pending.Status)
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldExecuteEveryTransferOnceWithConcurrentSchedulers() {
+	for i := 0; i < 5; i++ {
+		suite.schedule(suite.now)
+	}
+
+	var mutex sync.Mutex
+	executions := make(map[model.ScheduledTransferId]int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for found := true; found; {
+				var err error
+				found, err = suite.scheduledStorage.ExecuteDue(suite.now, func(transfer *model.ScheduledTransfer) error {
+					mutex.Lock()
+					executions[transfer.Id]++
+					mutex.Unlock()
+					transfer.Status = model.ExecutedTransfer
+					return nil
+				})
+				assert.NoError(suite.T(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), 5, len(executions))
+	for id, count := range executions {
+		assert.Equal(suite.T(), 1, count, "scheduled transfer %d", id)
+	}
+}
+
+func (suite *ScheduledTransferStorageSuite) TestShouldCancelPendingTransfersWhenClosingTheAccount() {
+	scheduled := suite.schedule(suite.now.Add(time.Hour))
+
+	_, err := suite.accountStorage.Close(suite.to, nil)
+
+	assert.NoError(suite.T(), err)
+	cancelled, err := suite.scheduledStorage.Get(scheduled.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CancelledTransfer, cancelled.Status)
+}
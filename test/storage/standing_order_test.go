@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang_bank_demo/src/errors"
+	"golang_bank_demo/src/model"
+	"golang_bank_demo/src/storage"
+	"golang_bank_demo/test/postgres"
+	"testing"
+	"time"
+)
+
+// StandingOrderStorageSuite is the contract that every storage backend has to fulfil
+type StandingOrderStorageSuite struct {
+	suite.Suite
+	accountStorage storage.AccountStorage
+	orderStorage   storage.StandingOrderStorage
+	from           model.AccountId
+	to             model.AccountId
+}
+
+type PostgresStandingOrderStorageSuite struct {
+	StandingOrderStorageSuite
+	postgres.PostgresTestSuite
+}
+
+func TestPostgresStandingOrderStorageSuite(t *testing.T) {
+	suite.Run(t, new(PostgresStandingOrderStorageSuite))
+}
+
+func (suite *PostgresStandingOrderStorageSuite) SetupSuite() {
+	suite.PostgresTestSuite.SetupSuite(suite.T())
+	suite.accountStorage = storage.NewPostgresAccountStorage(suite.Db)
+	suite.orderStorage = storage.NewPostgresStandingOrderStorage(suite.Db)
+}
+
+func (suite *PostgresStandingOrderStorageSuite) SetupTest() {
+	suite.PostgresTestSuite.SetupTest(suite.T())
+	suite.createAccounts()
+}
+
+func (suite *PostgresStandingOrderStorageSuite) TearDownTest() {
+	suite.PostgresTestSuite.TearDownTest(suite.T())
+}
+
+type InMemoryStandingOrderStorageSuite struct {
+	StandingOrderStorageSuite
+}
+
+func TestInMemoryStandingOrderStorageSuite(t *testing.T) {
+	suite.Run(t, new(InMemoryStandingOrderStorageSuite))
+}
+
+func (suite *InMemoryStandingOrderStorageSuite) SetupTest() {
+	accounts := storage.NewInMemoryAccountStorage(storage.NewInMemoryLedgerStorage())
+	orders := storage.NewInMemoryStandingOrderStorage()
+	accounts.SetSchedules(storage.NewInMemoryScheduledTransferStorage(), orders)
+	suite.accountStorage = accounts
+	suite.orderStorage = orders
+	suite.createAccounts()
+}
+
+func (suite *StandingOrderStorageSuite) createAccounts() {
+	from, _ := suite.accountStorage.Create(&model.Account{Owner: 1, Type: model.CheckingAccount, Currency: "EUR"})
+	to, _ := suite.accountStorage.Create(&model.Account{Owner: 2, Type: model.CheckingAccount, Currency: "EUR"})
+	suite.from = from.Id
+	suite.to = to.Id
+}
+
+func (suite *StandingOrderStorageSuite) create(nextRunOn time.Time) *model.StandingOrder {
+	order, err := suite.orderStorage.Create(&model.StandingOrder{
+		Recurrence: model.Recurrence{Frequency: model.MonthlyFrequency, Day: 1},
+		Owner:      1,
+		From:       suite.from,
+		To:         suite.to,
+		Amount:     decimal.NewFromInt(100),
+		NextRunOn:  nextRunOn,
+		DueAt:      nextRunOn,
+		Status:     model.ActiveStandingOrder,
+	})
+	assert.NoError(suite.T(), err)
+	return order
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldCreateAndListStandingOrders() {
+	first := suite.create(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	second := suite.create(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(suite.T(), model.Recurrence{Frequency: model.MonthlyFrequency, Day: 1}, first.Recurrence)
+	assert.Equal(suite.T(), "100", first.Amount.String())
+	assert.True(suite.T(), first.NextRunOn.Equal(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)))
+
+	orders, err := suite.orderStorage.ListByOwner(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(orders))
+	assert.Equal(suite.T(), first.Id, orders[0].Id)
+	assert.Equal(suite.T(), second.Id, orders[1].Id)
+
+	orders, err = suite.orderStorage.ListByOwner(2)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), orders)
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldNotGetStandingOrderThatDoesNotExist() {
+	_, err := suite.orderStorage.Get(123)
+
+	assert.ErrorIs(suite.T(), err, &errors.StandingOrderDoesNotExistError{StandingOrderId: 123})
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldUpdateAndReactivateSuspendedOrder() {
+	order := suite.create(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	_, _ = suite.orderStorage.ExecuteDue(order.DueAt, func(order *model.StandingOrder) error {
+		errorType := "BalanceTooLowError"
+		order.Failures = 4
+		order.ErrorType = &errorType
+		order.Status = model.SuspendedStandingOrder
+		return nil
+	})
+
+	order.Amount = decimal.NewFromInt(50)
+	order.Recurrence = model.Recurrence{Frequency: model.WeeklyFrequency, Day: 1}
+	updated, err := suite.orderStorage.Update(order)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "50", updated.Amount.String())
+	assert.Equal(suite.T(), model.WeeklyFrequency, updated.Frequency)
+	assert.Equal(suite.T(), model.ActiveStandingOrder, updated.Status)
+	assert.Equal(suite.T(), 0, updated.Failures)
+	assert.Nil(suite.T(), updated.ErrorType)
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldNotChangeCancelledOrder() {
+	order := suite.create(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	cancelled, err := suite.orderStorage.Cancel(order.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CancelledStandingOrder, cancelled.Status)
+
+	_, err = suite.orderStorage.Cancel(order.Id)
+	assert.ErrorIs(suite.T(), err, &errors.StandingOrderEndedError{StandingOrderId: order.Id, Status: model.CancelledStandingOrder})
+	_, err = suite.orderStorage.Update(order)
+	assert.ErrorIs(suite.T(), err, &errors.StandingOrderEndedError{StandingOrderId: order.Id, Status: model.CancelledStandingOrder})
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldStoreTheOutcomeOfDueRun() {
+	due := suite.create(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	notDue := suite.create(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC))
+	_, _ = suite.accountStorage.TopUp(suite.from, decimal.NewFromInt(100), decimal.Zero)
+	transfer, _ := suite.accountStorage.Transfer(model.NewTransfer(suite.from, suite.to, decimal.NewFromInt(100)))
+	executed := make([]model.StandingOrderId, 0)
+
+	now := time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC)
+	for found := true; found; {
+		var err error
+		found, err = suite.orderStorage.ExecuteDue(now, func(order *model.StandingOrder) error {
+			executed = append(executed, order.Id)
+			order.LastTransferId = &transfer.Id
+			order.Reschedule(order.NextRunOn.AddDate(0, 0, 1))
+			return nil
+		})
+		assert.NoError(suite.T(), err)
+	}
+
+	assert.Equal(suite.T(), []model.StandingOrderId{due.Id}, executed)
+	order, _ := suite.orderStorage.Get(due.Id)
+	assert.True(suite.T(), order.NextRunOn.Equal(time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(suite.T(), transfer.Id, *order.LastTransferId)
+	order, _ = suite.orderStorage.Get(notDue.Id)
+	assert.Nil(suite.T(), order.LastTransferId)
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldKeepOrderAsItWasWhenRunFails() {
+	order := suite.create(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	found, err := suite.orderStorage.ExecuteDue(order.DueAt, func(order *model.StandingOrder) error {
+		order.Failures = 1
+		order.DueAt = order.DueAt.Add(time.Hour)
+		return &errors.InternalServerError{Err: fmt.Errorf("connection refused")}
+	})
+	assert.True(suite.T(), found)
+	assert.Error(suite.T(), err)
+
+	unchanged, _ := suite.orderStorage.Get(order.Id)
+	assert.Equal(suite.T(), 0, unchanged.Failures)
+	assert.True(suite.T(), unchanged.DueAt.Equal(order.DueAt))
+}
+
+func (suite *StandingOrderStorageSuite) TestShouldCancelOrdersWhenClosingTheAccount() {
+	order := suite.create(time.Now().Add(24 * time.Hour))
+
+	_, err := suite.accountStorage.Close(suite.from, nil)
+
+	assert.NoError(suite.T(), err)
+	cancelled, err := suite.orderStorage.Get(order.Id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.CancelledStandingOrder, cancelled.Status)
+}